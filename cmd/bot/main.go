@@ -1,21 +1,69 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"money-telegram-bot/internal/bot"
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/logging"
+	"money-telegram-bot/internal/ratelimit"
 )
 
 func main() {
+	logging.Setup()
+	if err := i18n.Validate(); err != nil {
+		logging.Fatal("Invalid message catalogues", "error", err)
+	}
+
 	token := os.Getenv("TELEGRAM_BOT_TOKEN")
 	if token == "" {
-		log.Fatal("[FATAL] TELEGRAM_BOT_TOKEN environment variable not configured. Please set this environment variable with your bot's token.")
+		logging.Fatal("TELEGRAM_BOT_TOKEN environment variable not configured. Please set this environment variable with your bot's token")
+	}
+
+	slog.Info("Starting Money Savior Telegram Bot")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := database.InitDB(ctx); err != nil {
+		logging.Fatal("Failed to initialize DynamoDB", "error", err)
+	}
+
+	limits, err := ratelimit.LimitsFromEnv()
+	if err != nil {
+		logging.Fatal("Invalid RATE_LIMITS", "error", err)
 	}
 
-	log.Println("[INFO] Starting Money Savior Telegram Bot...")
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// BOT_MODE selects how updates arrive: "polling" (default) or "webhook".
+	switch mode := os.Getenv("BOT_MODE"); mode {
+	case "", "polling":
+		bot.SetRateLimiter(ratelimit.New(limits, ratelimit.NewMemoryStore()))
+		if err := bot.Start(signalCtx, token); err != nil {
+			logging.Fatal("Bot failed", "error", err)
+		}
+	case "webhook":
+		cfg, err := bot.WebhookConfigFromEnv()
+		if err != nil {
+			logging.Fatal("Invalid webhook configuration", "error", err)
+		}
+
+		// Retries of a webhook may outlive this process; remember them in DynamoDB.
+		bot.SetUpdateStore(bot.DynamoUpdateStore{})
+		bot.SetRateLimiter(ratelimit.New(limits, ratelimit.DynamoStore{}))
 
-	if err := bot.Start(token); err != nil {
-		log.Fatal("[FATAL] Bot initialization failed:", err)
+		if err := bot.StartWebhook(signalCtx, token, cfg); err != nil {
+			logging.Fatal("Webhook server failed", "error", err)
+		}
+	default:
+		logging.Fatal("Unknown BOT_MODE, use \"polling\" or \"webhook\"", "mode", mode)
 	}
 }
@@ -2,55 +2,147 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"log"
+	"encoding/base64"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
 	"os"
+	"strings"
 	"time"
-	
+
 	"money-telegram-bot/internal/bot"
 	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/logging"
+	"money-telegram-bot/internal/metrics"
+	"money-telegram-bot/internal/ratelimit"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
 )
 
 var telegramBot *tgbotapi.BotAPI
+var webhookSecret = os.Getenv("WEBHOOK_SECRET")
+
+// replyMargin is kept from the Lambda deadline to answer the user.
+const replyMargin = 2 * time.Second
+
+// routeUpdate hands a verified update to the bot; tests replace it.
+var routeUpdate = bot.RouteUpdate
+
+// setup runs once per Lambda instance, before the first request.
+func setup() {
+	logging.Setup()
+	// There is no server to scrape in Lambda; metrics go to CloudWatch as EMF logs.
+	metrics.EnableEMF()
+	if err := i18n.Validate(); err != nil {
+		logging.Fatal("Invalid message catalogues", "error", err)
+	}
 
-func init() {
 	token := os.Getenv("TELEGRAM_BOT_TOKEN")
 	if token == "" {
-		log.Fatal("[FATAL] TELEGRAM_BOT_TOKEN environment variable not configured.")
+		logging.Fatal("TELEGRAM_BOT_TOKEN environment variable not configured")
+	}
+	if webhookSecret == "" {
+		logging.Fatal("WEBHOOK_SECRET environment variable not configured")
 	}
 
 	var err error
-	telegramBot, err = tgbotapi.NewBotAPI(token)
+	telegramBot, err = bot.NewAPI(token)
 	if err != nil {
-		log.Fatal("[FATAL] Failed to initialize Telegram bot:", err)
+		logging.Fatal("Failed to initialize Telegram bot", "error", err)
 	}
 
-	log.Printf("[INFO] Lambda bot authenticated as @%s", telegramBot.Self.UserName)
+	slog.Info("Lambda bot authenticated", "bot", telegramBot.Self.UserName)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	
+
 	if err := database.InitDB(ctx); err != nil {
-		log.Fatal("[FATAL] Failed to initialize DynamoDB:", err)
+		logging.Fatal("Failed to initialize DynamoDB", "error", err)
+	}
+
+	// Each Lambda instance has its own memory; retries may land on another one.
+	bot.SetUpdateStore(bot.DynamoUpdateStore{})
+
+	limits, err := ratelimit.LimitsFromEnv()
+	if err != nil {
+		logging.Fatal("Invalid RATE_LIMITS", "error", err)
 	}
+	bot.SetRateLimiter(ratelimit.New(limits, ratelimit.DynamoStore{}))
 }
 
-func Handler(ctx context.Context, req events.APIGatewayProxyRequest) error {
-	var update tgbotapi.Update
-	if err := json.Unmarshal([]byte(req.Body), &update); err != nil {
-		log.Printf("[ERROR] Failed to parse Telegram update: %v", err)
-		return err
+// Handler answers every request with a status code instead of an error:
+// Telegram retries any non-2xx webhook response, and a Lambda error would
+// make it retry a malformed update forever. Only the method and secret token
+// checks fail the request; an authenticated body that is not an update is
+// logged and acknowledged with 200, so Telegram stops sending it.
+func Handler(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	defer metrics.FlushEMF(os.Stdout)
+
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		ctx = logging.With(ctx, slog.String("request_id", lc.AwsRequestID))
+	}
+
+	if req.HTTPMethod != "" && req.HTTPMethod != http.MethodPost {
+		return respond(http.StatusMethodNotAllowed), nil
+	}
+
+	if !bot.ValidSecretToken(webhookSecret, header(req.Headers, bot.SecretTokenHeader)) {
+		slog.WarnContext(ctx, "Webhook request with invalid secret token", "source_ip", req.RequestContext.Identity.SourceIP)
+		return respond(http.StatusUnauthorized), nil
 	}
 
-	bot.RouteUpdate(telegramBot, update)
-	return nil
+	var body io.Reader = strings.NewReader(req.Body)
+	if req.IsBase64Encoded {
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+
+	update, err := bot.DecodeUpdate(body)
+	if err != nil {
+		slog.ErrorContext(ctx, "Dropping unreadable Telegram update", "too_large", errors.Is(err, bot.ErrBodyTooLarge), "error", err)
+		return respond(http.StatusOK), nil
+	}
+
+	// Stop the handlers a little before Lambda does, so they can still tell
+	// the user the operation timed out.
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-replyMargin))
+		defer cancel()
+	}
+
+	routeUpdate(ctx, telegramBot, update)
+	return respond(http.StatusOK), nil
+}
+
+// header looks up an HTTP header ignoring case; API Gateway keeps the casing
+// the client sent.
+func header(headers map[string]string, name string) string {
+	if value, ok := headers[name]; ok {
+		return value
+	}
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+func respond(status int) events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+		Body:       http.StatusText(status),
+	}
 }
 
 func main() {
+	setup()
 	lambda.Start(Handler)
-}
\ No newline at end of file
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"money-telegram-bot/internal/bot"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	const secret = "0123456789abcdef_secret"
+	const update = `{"update_id":42,"message":{"message_id":1,"text":"/ajuda","chat":{"id":7}}}`
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		status int
+		routed bool
+	}{
+		{
+			name:   "valid update",
+			req:    events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: map[string]string{bot.SecretTokenHeader: secret}, Body: update},
+			status: http.StatusOK,
+			routed: true,
+		},
+		{
+			name:   "header casing from API Gateway",
+			req:    events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: map[string]string{"x-telegram-bot-api-secret-token": secret}, Body: update},
+			status: http.StatusOK,
+			routed: true,
+		},
+		{
+			name: "base64 body",
+			req: events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: map[string]string{bot.SecretTokenHeader: secret},
+				Body: base64.StdEncoding.EncodeToString([]byte(update)), IsBase64Encoded: true},
+			status: http.StatusOK,
+			routed: true,
+		},
+		{
+			name:   "wrong secret",
+			req:    events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: map[string]string{bot.SecretTokenHeader: secret + "x"}, Body: update},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "missing secret",
+			req:    events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Body: update},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "wrong method",
+			req:    events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Headers: map[string]string{bot.SecretTokenHeader: secret}},
+			status: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "oversized body",
+			req:    events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: map[string]string{bot.SecretTokenHeader: secret}, Body: `{"update_id":1,"x":"` + strings.Repeat("a", bot.MaxUpdateBytes) + `"}`},
+			status: http.StatusOK,
+		},
+		{
+			name:   "malformed body",
+			req:    events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: map[string]string{bot.SecretTokenHeader: secret}, Body: `{"update_id":`},
+			status: http.StatusOK,
+		},
+		{
+			name: "malformed base64",
+			req: events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: map[string]string{bot.SecretTokenHeader: secret},
+				Body: "not base64!", IsBase64Encoded: true},
+			status: http.StatusOK,
+		},
+	}
+
+	webhookSecret = secret
+	defer func() { webhookSecret, routeUpdate = "", bot.RouteUpdate }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var routed []tgbotapi.Update
+			routeUpdate = func(_ context.Context, _ *tgbotapi.BotAPI, update tgbotapi.Update) {
+				routed = append(routed, update)
+			}
+
+			resp, err := Handler(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Handler returned an error, which Telegram would retry: %v", err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := len(routed) == 1 && routed[0].UpdateID == 42; got != tt.routed {
+				t.Errorf("routed = %v, want %v", routed, tt.routed)
+			}
+		})
+	}
+}
+
+func TestHeader(t *testing.T) {
+	headers := map[string]string{"Content-Type": "application/json", "x-telegram-bot-api-secret-token": "s"}
+	if got := header(headers, bot.SecretTokenHeader); got != "s" {
+		t.Errorf("header = %q, want s", got)
+	}
+	if got := header(headers, "X-Missing"); got != "" {
+		t.Errorf("header = %q, want none", got)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"money-telegram-bot/internal/bot"
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/handlers"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// This Lambda is triggered once a day by an EventBridge schedule and sends the
+// credit card invoice reminders. The polling bot runs the same job in-process.
+// The webhook Lambda has no startup hook, so this one also keeps the command
+// menu up to date; republishing it daily is harmless.
+
+var telegramBot *tgbotapi.BotAPI
+
+func init() {
+	logging.Setup()
+	if err := i18n.Validate(); err != nil {
+		logging.Fatal("Invalid message catalogues", "error", err)
+	}
+
+	token := os.Getenv("TELEGRAM_BOT_TOKEN")
+	if token == "" {
+		logging.Fatal("TELEGRAM_BOT_TOKEN environment variable not configured")
+	}
+
+	var err error
+	telegramBot, err = bot.NewAPI(token)
+	if err != nil {
+		logging.Fatal("Failed to initialize Telegram bot", "error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := database.InitDB(ctx); err != nil {
+		logging.Fatal("Failed to initialize DynamoDB", "error", err)
+	}
+}
+
+func Handler(ctx context.Context) error {
+	if err := handlers.RegisterCommands(ctx, telegramBot); err != nil {
+		slog.WarnContext(ctx, "Failed to register command menu", "error", err)
+	}
+	return handlers.SendInvoiceReminders(ctx, telegramBot, time.Now())
+}
+
+func main() {
+	lambda.Start(Handler)
+}
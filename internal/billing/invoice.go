@@ -0,0 +1,129 @@
+// Package billing groups credit card expenses into invoices (faturas) based on
+// the closing and due days registered for each card.
+package billing
+
+import (
+	"sort"
+	"time"
+
+	"money-telegram-bot/internal/models"
+)
+
+// Location is the time zone used to decide on which day a purchase happened.
+// Brazil has not observed daylight saving time since 2019.
+var Location = time.FixedZone("BRT", -3*60*60)
+
+// Invoice is the set of card expenses closed on the same date.
+type Invoice struct {
+	Card     string
+	Closing  time.Time
+	Due      time.Time
+	Total    float64
+	Expenses []models.Expense
+}
+
+// Period returns the closing and due dates of the invoice a purchase made at t
+// falls into. Purchases made on the closing day already go to the next invoice.
+func Period(card *models.PaymentMethod, t time.Time) (closing, due time.Time) {
+	local := t.In(Location)
+	year, month, day := local.Date()
+	closing = dateOn(year, month, card.ClosingDay)
+	if day >= closing.Day() {
+		closing = dateOn(year, month+1, card.ClosingDay)
+	}
+	return closing, dueFor(card, closing)
+}
+
+// InvoiceDueOn returns the closing date of the card invoice due on the given day, if any.
+func InvoiceDueOn(card *models.PaymentMethod, day time.Time) (time.Time, bool) {
+	local := day.In(Location)
+	year, month, _ := local.Date()
+	for offset := -2; offset <= 1; offset++ {
+		closing := dateOn(year, month+time.Month(offset), card.ClosingDay)
+		if sameDay(dueFor(card, closing), local) {
+			return closing, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Group splits the expenses paid with the card into invoices, oldest first.
+func Group(card *models.PaymentMethod, expenses []models.Expense) []Invoice {
+	byClosing := make(map[time.Time]*Invoice)
+	for _, expense := range expenses {
+		if !card.Matches(expense.Method) {
+			continue
+		}
+		closing, due := Period(card, expense.CreatedAt)
+		invoice, ok := byClosing[closing]
+		if !ok {
+			invoice = &Invoice{Card: card.Name, Closing: closing, Due: due}
+			byClosing[closing] = invoice
+		}
+		invoice.Total += expense.Amount
+		invoice.Expenses = append(invoice.Expenses, expense)
+	}
+
+	invoices := make([]Invoice, 0, len(byClosing))
+	for _, invoice := range byClosing {
+		invoices = append(invoices, *invoice)
+	}
+	sort.Slice(invoices, func(i, j int) bool {
+		return invoices[i].Closing.Before(invoices[j].Closing)
+	})
+	return invoices
+}
+
+// Find returns the invoice closing on the given date, or an empty one when no
+// expense fell into it.
+func Find(card *models.PaymentMethod, expenses []models.Expense, closing time.Time) Invoice {
+	for _, invoice := range Group(card, expenses) {
+		if invoice.Closing.Equal(closing) {
+			return invoice
+		}
+	}
+	return Invoice{Card: card.Name, Closing: closing, Due: dueFor(card, closing)}
+}
+
+// Open returns the invoice that is still receiving purchases at now.
+func Open(card *models.PaymentMethod, expenses []models.Expense, now time.Time) Invoice {
+	closing, _ := Period(card, now)
+	return Find(card, expenses, closing)
+}
+
+// dueFor returns the due date of the invoice closed on closing. When the due
+// day is not after the closing day, payment is due in the following month.
+func dueFor(card *models.PaymentMethod, closing time.Time) time.Time {
+	year, month, _ := closing.Date()
+	if card.DueDay <= card.ClosingDay {
+		month++
+	}
+	return dateOn(year, month, card.DueDay)
+}
+
+// AddMonths moves t n months ahead on the calendar of Location, keeping the
+// time of day and clamping the day to the last day of short months, so a
+// purchase on Jan 31 is billed again on Feb 28 and Mar 31.
+func AddMonths(t time.Time, n int) time.Time {
+	local := t.In(Location)
+	year, month, day := local.Date()
+	date := dateOn(year, month+time.Month(n), day)
+	return time.Date(date.Year(), date.Month(), date.Day(),
+		local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), Location).In(t.Location())
+}
+
+// dateOn builds a date, clamping the day to the last day of short months.
+func dateOn(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, Location)
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, Location)
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,130 @@
+package billing
+
+import (
+	"testing"
+	"time"
+
+	"money-telegram-bot/internal/models"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, Location)
+}
+
+func TestPeriod(t *testing.T) {
+	tests := []struct {
+		name         string
+		closing, due int
+		purchase     time.Time
+		wantClosing  time.Time
+		wantDue      time.Time
+	}{
+		{"before closing", 10, 17, date(2025, time.March, 9), date(2025, time.March, 10), date(2025, time.March, 17)},
+		{"on closing day", 10, 17, date(2025, time.March, 10), date(2025, time.April, 10), date(2025, time.April, 17)},
+		{"due next month", 25, 5, date(2025, time.March, 1), date(2025, time.March, 25), date(2025, time.April, 5)},
+		{"due on closing day", 10, 10, date(2025, time.March, 1), date(2025, time.March, 10), date(2025, time.April, 10)},
+		{"year end", 20, 5, date(2024, time.December, 25), date(2025, time.January, 20), date(2025, time.February, 5)},
+		// 01:00 UTC on the 10th is still the 9th in Brazil.
+		{"brazilian calendar", 10, 17, time.Date(2025, time.March, 10, 1, 0, 0, 0, time.UTC), date(2025, time.March, 10), date(2025, time.March, 17)},
+		{"day 31 in February", 31, 10, date(2025, time.February, 15), date(2025, time.February, 28), date(2025, time.March, 10)},
+		{"day 31 on February's last day", 31, 10, date(2025, time.February, 28), date(2025, time.March, 31), date(2025, time.April, 10)},
+		{"day 31 in April", 31, 10, date(2025, time.April, 29), date(2025, time.April, 30), date(2025, time.May, 10)},
+		{"day 31 on April 30", 31, 10, date(2025, time.April, 30), date(2025, time.May, 31), date(2025, time.June, 10)},
+		{"day 30 in February", 30, 7, date(2025, time.February, 27), date(2025, time.February, 28), date(2025, time.March, 7)},
+		{"day 29 in a leap February", 29, 8, date(2024, time.February, 28), date(2024, time.February, 29), date(2024, time.March, 8)},
+		{"day 29 in February", 29, 8, date(2025, time.February, 28), date(2025, time.March, 29), date(2025, time.April, 8)},
+		{"due day 31 in February", 5, 31, date(2025, time.February, 1), date(2025, time.February, 5), date(2025, time.February, 28)},
+		{"due day 30 next February", 28, 30, date(2025, time.January, 28), date(2025, time.February, 28), date(2025, time.February, 28)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := &models.PaymentMethod{Name: "nubank", Type: models.MethodCredit, ClosingDay: tt.closing, DueDay: tt.due}
+			closing, due := Period(card, tt.purchase)
+			if !closing.Equal(tt.wantClosing) || !due.Equal(tt.wantDue) {
+				t.Errorf("Period = %s, %s; want %s, %s", closing.Format(time.DateOnly), due.Format(time.DateOnly),
+					tt.wantClosing.Format(time.DateOnly), tt.wantDue.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func TestInvoiceDueOn(t *testing.T) {
+	tests := []struct {
+		name         string
+		closing, due int
+		day          time.Time
+		want         time.Time // zero when no invoice is due
+	}{
+		{"due same month", 10, 17, date(2025, time.March, 17), date(2025, time.March, 10)},
+		{"due next month", 25, 5, date(2025, time.April, 5), date(2025, time.March, 25)},
+		{"not a due day", 10, 17, date(2025, time.March, 16), time.Time{}},
+		{"across the year", 25, 5, date(2025, time.January, 5), date(2024, time.December, 25)},
+		{"closing clamped", 31, 10, date(2025, time.March, 10), date(2025, time.February, 28)},
+		{"due clamped", 5, 31, date(2025, time.February, 28), date(2025, time.February, 5)},
+		{"due clamped in April", 5, 31, date(2025, time.April, 30), date(2025, time.April, 5)},
+		{"due 30 in a leap year", 10, 30, date(2024, time.February, 29), date(2024, time.February, 10)},
+		{"late in the day", 10, 17, time.Date(2025, time.March, 18, 2, 0, 0, 0, time.UTC), date(2025, time.March, 10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := &models.PaymentMethod{Name: "nubank", Type: models.MethodCredit, ClosingDay: tt.closing, DueDay: tt.due}
+			closing, ok := InvoiceDueOn(card, tt.day)
+			if ok != !tt.want.IsZero() || !closing.Equal(tt.want) {
+				t.Errorf("InvoiceDueOn = %s, %v; want %s", closing.Format(time.DateOnly), ok, tt.want.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func TestAddMonths(t *testing.T) {
+	purchase := time.Date(2025, time.January, 31, 15, 4, 5, 0, Location)
+	tests := []struct {
+		start time.Time
+		n     int
+		want  time.Time
+	}{
+		{purchase, 0, purchase},
+		{purchase, 1, time.Date(2025, time.February, 28, 15, 4, 5, 0, Location)},
+		{purchase, 2, time.Date(2025, time.March, 31, 15, 4, 5, 0, Location)},
+		{purchase, 3, time.Date(2025, time.April, 30, 15, 4, 5, 0, Location)},
+		{purchase, 12, time.Date(2026, time.January, 31, 15, 4, 5, 0, Location)},
+		{time.Date(2024, time.January, 30, 9, 0, 0, 0, Location), 1, time.Date(2024, time.February, 29, 9, 0, 0, 0, Location)},
+		{time.Date(2025, time.November, 29, 9, 0, 0, 0, Location), 3, time.Date(2026, time.February, 28, 9, 0, 0, 0, Location)},
+		// 01:00 UTC on Feb 1st is Jan 31 in Brazil.
+		{time.Date(2025, time.February, 1, 1, 0, 0, 0, time.UTC), 1, time.Date(2025, time.February, 28, 22, 0, 0, 0, Location)},
+	}
+
+	for _, tt := range tests {
+		if got := AddMonths(tt.start, tt.n); !got.Equal(tt.want) {
+			t.Errorf("AddMonths(%s, %d) = %s, want %s", tt.start, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	card := &models.PaymentMethod{Name: "nubank", Type: models.MethodCredit, ClosingDay: 31, DueDay: 10}
+	expenses := []models.Expense{
+		{Method: "Nubank", Amount: 10, CreatedAt: time.Date(2025, time.February, 27, 12, 0, 0, 0, time.UTC)},
+		{Method: "nubank", Amount: 20, CreatedAt: time.Date(2025, time.February, 28, 12, 0, 0, 0, time.UTC)},
+		{Method: "nubank", Amount: 5, CreatedAt: time.Date(2025, time.March, 30, 12, 0, 0, 0, time.UTC)},
+		{Method: "pix", Amount: 99, CreatedAt: time.Date(2025, time.February, 27, 12, 0, 0, 0, time.UTC)},
+	}
+
+	invoices := Group(card, expenses)
+	if len(invoices) != 2 {
+		t.Fatalf("got %d invoices, want 2", len(invoices))
+	}
+	if !invoices[0].Closing.Equal(date(2025, time.February, 28)) || invoices[0].Total != 10 {
+		t.Errorf("first invoice = %s, %v", invoices[0].Closing.Format(time.DateOnly), invoices[0].Total)
+	}
+	if !invoices[1].Closing.Equal(date(2025, time.March, 31)) || invoices[1].Total != 25 {
+		t.Errorf("second invoice = %s, %v", invoices[1].Closing.Format(time.DateOnly), invoices[1].Total)
+	}
+
+	empty := Find(card, expenses, date(2025, time.April, 30))
+	if empty.Total != 0 || !empty.Due.Equal(date(2025, time.May, 10)) {
+		t.Errorf("empty invoice = %+v", empty)
+	}
+}
@@ -0,0 +1,30 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"money-telegram-bot/internal/handlers"
+	"money-telegram-bot/internal/metrics"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// registerCommands publishes the command menu. A failure only costs the menu,
+// so it is logged and the bot starts anyway.
+func registerCommands(ctx context.Context, bot *tgbotapi.BotAPI) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := handlers.RegisterCommands(ctx, bot); err != nil {
+		slog.WarnContext(ctx, "Failed to register command menu", "error", err)
+	}
+}
+
+// NewAPI authenticates with Telegram using an HTTP client that counts failed
+// API calls in the metrics.
+func NewAPI(token string) (*tgbotapi.BotAPI, error) {
+	client := &http.Client{Transport: metrics.Transport(http.DefaultTransport)}
+	return tgbotapi.NewBotAPIWithClient(token, tgbotapi.APIEndpoint, client)
+}
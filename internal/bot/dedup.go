@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"money-telegram-bot/internal/database"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// UpdateStore remembers which updates were already handled, so a webhook
+// retry or a redelivered update does not save the same expense twice.
+type UpdateStore interface {
+	// MarkProcessed records the update and reports whether it is new.
+	MarkProcessed(ctx context.Context, update tgbotapi.Update) (bool, error)
+}
+
+// updateStore is checked by RouteUpdate before any handler runs.
+var updateStore UpdateStore = NewMemoryUpdateStore(DefaultMemoryUpdates)
+
+// SetUpdateStore replaces the store used to deduplicate updates.
+func SetUpdateStore(store UpdateStore) {
+	updateStore = store
+}
+
+// DefaultMemoryUpdates is how many update_ids the in-memory store keeps.
+const DefaultMemoryUpdates = 10000
+
+// MemoryUpdateStore keeps the most recent update_ids in memory, evicting the
+// least recently seen. It suits polling mode, where a single process sees
+// every update.
+type MemoryUpdateStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	seen     map[int]*list.Element
+}
+
+func NewMemoryUpdateStore(capacity int) *MemoryUpdateStore {
+	return &MemoryUpdateStore{
+		capacity: capacity,
+		order:    list.New(),
+		seen:     make(map[int]*list.Element, capacity),
+	}
+}
+
+func (s *MemoryUpdateStore) MarkProcessed(_ context.Context, update tgbotapi.Update) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.seen[update.UpdateID]; ok {
+		s.order.MoveToFront(element)
+		return false, nil
+	}
+
+	s.seen[update.UpdateID] = s.order.PushFront(update.UpdateID)
+	if s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.seen, oldest.Value.(int))
+	}
+	return true, nil
+}
+
+// DynamoUpdateStore keeps processed update_ids in DynamoDB with a TTL, so
+// retries are caught across Lambda instances and restarts.
+type DynamoUpdateStore struct{}
+
+func (DynamoUpdateStore) MarkProcessed(ctx context.Context, update tgbotapi.Update) (bool, error) {
+	var userID int64
+	if user := update.SentFrom(); user != nil {
+		userID = user.ID
+	}
+	return database.MarkUpdateProcessed(ctx, userID, update.UpdateID)
+}
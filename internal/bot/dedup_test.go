@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"money-telegram-bot/internal/metrics"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestMemoryUpdateStoreReplay(t *testing.T) {
+	store := NewMemoryUpdateStore(DefaultMemoryUpdates)
+	update := tgbotapi.Update{UpdateID: 1001}
+
+	for i, want := range []bool{true, false, false} {
+		isNew, err := store.MarkProcessed(context.Background(), update)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isNew != want {
+			t.Errorf("delivery %d: new = %v, want %v", i+1, isNew, want)
+		}
+	}
+}
+
+func TestMemoryUpdateStoreConcurrentReplay(t *testing.T) {
+	store := NewMemoryUpdateStore(DefaultMemoryUpdates)
+	update := tgbotapi.Update{UpdateID: 2002}
+
+	var handled atomic.Int32
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isNew, _ := store.MarkProcessed(context.Background(), update); isNew {
+				handled.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := handled.Load(); got != 1 {
+		t.Errorf("update handled %d times, want once", got)
+	}
+}
+
+func TestMemoryUpdateStoreEvictsLeastRecent(t *testing.T) {
+	store := NewMemoryUpdateStore(2)
+	mark := func(id int) bool {
+		isNew, _ := store.MarkProcessed(context.Background(), tgbotapi.Update{UpdateID: id})
+		return isNew
+	}
+
+	mark(1)
+	mark(2)
+	mark(1) // seen again: 2 is now the least recent
+	mark(3) // evicts 2
+
+	if mark(1) {
+		t.Error("update 1 was forgotten")
+	}
+	if !mark(2) {
+		t.Error("update 2 was kept beyond the capacity")
+	}
+}
+
+type failingUpdateStore struct{}
+
+func (failingUpdateStore) MarkProcessed(context.Context, tgbotapi.Update) (bool, error) {
+	return false, errors.New("table unavailable")
+}
+
+func TestRouteUpdateSkipsReplays(t *testing.T) {
+	defer SetUpdateStore(updateStore)
+	SetUpdateStore(NewMemoryUpdateStore(DefaultMemoryUpdates))
+
+	// An update with neither sender nor message reaches no handler nor the
+	// database, so only deduplication decides the outcome.
+	update := tgbotapi.Update{UpdateID: 3003}
+	if got := routeUpdate(context.Background(), nil, update); got != metrics.OutcomeIgnored {
+		t.Errorf("first delivery outcome = %q, want %q", got, metrics.OutcomeIgnored)
+	}
+	if got := routeUpdate(context.Background(), nil, update); got != metrics.OutcomeDuplicate {
+		t.Errorf("replay outcome = %q, want %q", got, metrics.OutcomeDuplicate)
+	}
+
+	// When the store fails the update is handled rather than dropped.
+	SetUpdateStore(failingUpdateStore{})
+	if got := routeUpdate(context.Background(), nil, update); got != metrics.OutcomeIgnored {
+		t.Errorf("outcome with a failing store = %q, want %q", got, metrics.OutcomeIgnored)
+	}
+}
@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// DefaultWorkers is how many updates are handled at the same time.
+	DefaultWorkers = 8
+	// DefaultQueueSize is how many updates each worker holds before the
+	// dispatcher stops accepting new ones.
+	DefaultQueueSize = 64
+)
+
+// Dispatcher handles updates concurrently while keeping the updates of the
+// same chat (or user) in order: each chat is always sent to the same worker.
+type Dispatcher struct {
+	handle func(tgbotapi.Update)
+	queues []chan tgbotapi.Update
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher starts workers goroutines, each with a queue of queueSize
+// updates, that call handle for every dispatched update.
+func NewDispatcher(workers, queueSize int, handle func(tgbotapi.Update)) *Dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	d := &Dispatcher{
+		handle: handle,
+		queues: make([]chan tgbotapi.Update, workers),
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan tgbotapi.Update, queueSize)
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+	return d
+}
+
+// Dispatch queues the update on the worker of its chat. It blocks while that
+// queue is full and returns ctx's error if ctx is cancelled first.
+func (d *Dispatcher) Dispatch(ctx context.Context, update tgbotapi.Update) error {
+	queue := d.queues[orderingKey(update)%uint64(len(d.queues))]
+	select {
+	case queue <- update:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting updates and waits until the queued ones are handled
+// or ctx is cancelled. Dispatch must not be called after Close.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) work(queue <-chan tgbotapi.Update) {
+	defer d.wg.Done()
+	for update := range queue {
+		d.safeHandle(update)
+	}
+}
+
+// safeHandle keeps a panic in one handler from taking the worker (and the
+// updates queued behind it) down.
+func (d *Dispatcher) safeHandle(update tgbotapi.Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Panic while handling update", "update_id", update.UpdateID, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+		}
+	}()
+	d.handle(update)
+}
+
+// orderingKey picks the chat of the update, falling back to the sender, so
+// that updates that must not be reordered share a worker.
+func orderingKey(update tgbotapi.Update) uint64 {
+	switch {
+	case update.Message != nil:
+		return uint64(update.Message.Chat.ID)
+	case update.EditedMessage != nil:
+		return uint64(update.EditedMessage.Chat.ID)
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return uint64(update.CallbackQuery.Message.Chat.ID)
+	}
+	if user := update.SentFrom(); user != nil {
+		return uint64(user.ID)
+	}
+	return uint64(update.UpdateID)
+}
+
+// workersFromEnv reads BOT_WORKERS, falling back to DefaultWorkers.
+func workersFromEnv() int {
+	workers, err := strconv.Atoi(os.Getenv("BOT_WORKERS"))
+	if err != nil || workers < 1 {
+		return DefaultWorkers
+	}
+	return workers
+}
@@ -0,0 +1,168 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func chatUpdate(id int, chatID int64) tgbotapi.Update {
+	return tgbotapi.Update{UpdateID: id, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID}}}
+}
+
+// recorder is a stub handler that records the updates it handled per chat.
+type recorder struct {
+	mu      sync.Mutex
+	handled map[int64][]int
+}
+
+func (r *recorder) handle(update tgbotapi.Update) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.handled == nil {
+		r.handled = make(map[int64][]int)
+	}
+	chatID := update.Message.Chat.ID
+	r.handled[chatID] = append(r.handled[chatID], update.UpdateID)
+}
+
+func TestDispatcherKeepsChatOrder(t *testing.T) {
+	var rec recorder
+	d := NewDispatcher(4, 8, rec.handle)
+
+	const chats, perChat = 10, 50
+	for i := 0; i < perChat; i++ {
+		for chat := int64(1); chat <= chats; chat++ {
+			if err := d.Dispatch(context.Background(), chatUpdate(i, chat)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for chat := int64(1); chat <= chats; chat++ {
+		got := rec.handled[chat]
+		if len(got) != perChat {
+			t.Fatalf("chat %d: handled %d updates, want %d", chat, len(got), perChat)
+		}
+		for i, id := range got {
+			if id != i {
+				t.Fatalf("chat %d: update %d handled at position %d", chat, id, i)
+			}
+		}
+	}
+}
+
+func TestDispatcherRecoversFromPanics(t *testing.T) {
+	var rec recorder
+	d := NewDispatcher(1, 4, func(update tgbotapi.Update) {
+		if update.UpdateID == 1 {
+			panic("handler bug")
+		}
+		rec.handle(update)
+	})
+
+	for id := 1; id <= 3; id++ {
+		if err := d.Dispatch(context.Background(), chatUpdate(id, 7)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rec.handled[7]; len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("handled %v after the panic, want [2 3]", got)
+	}
+}
+
+func TestDispatcherFullQueue(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	d := NewDispatcher(1, 1, func(tgbotapi.Update) {
+		started <- struct{}{}
+		<-release
+	})
+	defer func() {
+		close(release)
+		d.Close(context.Background())
+	}()
+
+	// The worker holds the first update and the queue holds the second.
+	if err := d.Dispatch(context.Background(), chatUpdate(1, 7)); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := d.Dispatch(context.Background(), chatUpdate(2, 7)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := d.Dispatch(ctx, chatUpdate(3, 7)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Dispatch on a full queue = %v, want the context's error", err)
+	}
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := d.Dispatch(cancelled, chatUpdate(4, 7)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Dispatch with a cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestDispatcherCloseDrainsQueue(t *testing.T) {
+	var rec recorder
+	release := make(chan struct{})
+	d := NewDispatcher(2, 16, func(update tgbotapi.Update) {
+		<-release
+		rec.handle(update)
+	})
+
+	for id := 0; id < 20; id++ {
+		if err := d.Dispatch(context.Background(), chatUpdate(id, int64(id%2))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Close waits for the queued updates, and gives up when ctx does.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := d.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close with stuck handlers = %v, want context.DeadlineExceeded", err)
+	}
+
+	close(release)
+	d.wg.Wait()
+	if got := len(rec.handled[0]) + len(rec.handled[1]); got != 20 {
+		t.Errorf("handled %d updates after Close, want all 20", got)
+	}
+}
+
+func TestOrderingKey(t *testing.T) {
+	user := &tgbotapi.User{ID: 99}
+	tests := []struct {
+		name   string
+		update tgbotapi.Update
+		want   uint64
+	}{
+		{"message", chatUpdate(1, 7), 7},
+		{"edited message", tgbotapi.Update{EditedMessage: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 8}}}, 8},
+		{"callback", tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{From: user, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 9}}}}, 9},
+		{"inline query", tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{From: user}}, 99},
+		{"nothing else", tgbotapi.Update{UpdateID: 5}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderingKey(tt.update); got != tt.want {
+				t.Errorf("orderingKey = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
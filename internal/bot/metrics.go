@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"money-telegram-bot/internal/metrics"
+)
+
+// DefaultMetricsAddr is where /metrics is served when METRICS_ADDR is not
+// set. It is a listener of its own in both polling and webhook modes, so the
+// metrics are never reachable through the public webhook port.
+const DefaultMetricsAddr = ":9090"
+
+// metricsAddrFromEnv reads METRICS_ADDR; "off" disables the endpoint.
+func metricsAddrFromEnv() string {
+	switch addr := os.Getenv("METRICS_ADDR"); addr {
+	case "":
+		return DefaultMetricsAddr
+	case "off":
+		return ""
+	default:
+		return addr
+	}
+}
+
+// serveMetrics serves /metrics on addr until ctx is cancelled. A failure to
+// listen is logged and does not stop the bot.
+func serveMetrics(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Default.Handler())
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	slog.InfoContext(ctx, "Metrics server listening", "addr", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.ErrorContext(ctx, "Metrics server failed", "error", err)
+	}
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"money-telegram-bot/internal/handlers"
+	"money-telegram-bot/internal/ratelimit"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// rateLimiter is checked by RouteUpdate after deduplication.
+var rateLimiter = ratelimit.New(ratelimit.DefaultLimits, ratelimit.NewMemoryStore())
+
+// SetRateLimiter replaces the limiter applied to incoming updates.
+func SetRateLimiter(limiter *ratelimit.Limiter) {
+	rateLimiter = limiter
+}
+
+// allowRate spends a token of the update's bucket and, when it is refused,
+// tells the user to wait. It reports whether the update may be handled.
+func allowRate(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) bool {
+	key, ok := rateKey(update)
+	if !ok {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result := rateLimiter.Allow(ctx, key)
+	if result.Allowed {
+		return true
+	}
+
+	if update.CallbackQuery != nil {
+		handlers.HandleRateLimitedCallback(ctx, bot, update.CallbackQuery, result.RetryAfter)
+	} else if msg := updateMessage(update); msg != nil && result.Notify {
+		handlers.HandleRateLimited(ctx, bot, msg, result.RetryAfter)
+	}
+	return false
+}
+
+// rateKey names the bucket of an update: the command for commands, and the
+// kind of update ("callback", "inline", "photo", "voice", "message") otherwise.
+// Choosing an inline result logs an expense, so it counts as /gastei. Unknown
+// commands share a single "unknown" bucket, so made-up command names can't
+// each get a fresh one.
+func rateKey(update tgbotapi.Update) (ratelimit.Key, bool) {
+	if callback := update.CallbackQuery; callback != nil {
+		if callback.Message == nil {
+			return ratelimit.Key{}, false
+		}
+		return ratelimit.Key{UserID: callback.From.ID, ChatID: callback.Message.Chat.ID, Name: "callback"}, true
+	}
+	// Inline updates have no chat; they share the bucket of the user's private chat.
+	if query := update.InlineQuery; query != nil {
+		return ratelimit.Key{UserID: query.From.ID, ChatID: query.From.ID, Name: "inline"}, true
+	}
+	if chosen := update.ChosenInlineResult; chosen != nil {
+		return ratelimit.Key{UserID: chosen.From.ID, ChatID: chosen.From.ID, Name: "gastei"}, true
+	}
+
+	msg := updateMessage(update)
+	if msg == nil || msg.From == nil {
+		return ratelimit.Key{}, false
+	}
+
+	key := ratelimit.Key{UserID: msg.From.ID, ChatID: msg.Chat.ID, Name: "message"}
+	switch {
+	case len(msg.Photo) > 0:
+		key.Name = "photo"
+	case msg.Voice != nil:
+		key.Name = "voice"
+	case msg.IsCommand() && isRegistered(msg.Command()):
+		key.Name = msg.Command()
+	case msg.IsCommand():
+		key.Name = "unknown"
+	}
+	return key, true
+}
+
+func updateMessage(update tgbotapi.Update) *tgbotapi.Message {
+	if update.Message != nil {
+		return update.Message
+	}
+	return update.EditedMessage
+}
@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func commandUpdate(text string) tgbotapi.Update {
+	length := len(text)
+	if i := strings.IndexByte(text, ' '); i >= 0 {
+		length = i
+	}
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		Text:     text,
+		From:     &tgbotapi.User{ID: 7},
+		Chat:     &tgbotapi.Chat{ID: -100},
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: length}},
+	}}
+}
+
+func TestRateKeyCommands(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"/gastei 10 uber", "gastei"},
+		{"/gastei@MoneySaviorBot 10 uber", "gastei"},
+		{"/naoexiste", "unknown"},
+		{"/a1b2c3", "unknown"},
+		{"/GASTEI 10", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			key, ok := rateKey(commandUpdate(tt.text))
+			if !ok {
+				t.Fatal("no bucket")
+			}
+			if key.Name != tt.want || key.UserID != 7 || key.ChatID != -100 {
+				t.Errorf("rateKey = %+v, want bucket %q", key, tt.want)
+			}
+			if label := commandLabel(commandUpdate(tt.text)); label != tt.want {
+				t.Errorf("commandLabel = %q, rate bucket %q", label, tt.want)
+			}
+		})
+	}
+}
@@ -1,67 +1,325 @@
 package bot
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
+	"money-telegram-bot/internal/billing"
+	"money-telegram-bot/internal/database"
 	"money-telegram-bot/internal/handlers"
+	"money-telegram-bot/internal/logging"
+	"money-telegram-bot/internal/metrics"
+	"money-telegram-bot/internal/nfce"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func RouteUpdate(bot *tgbotapi.BotAPI, update tgbotapi.Update) {
-	msg := update.Message
-	if msg == nil {
-		msg = update.EditedMessage
+// RouteUpdate handles an update. ctx bounds everything the update triggers:
+// the Lambda deadline, the webhook request or the polling shutdown.
+// Every update is counted in the metrics with its outcome and duration.
+func RouteUpdate(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+	ctx = updateLogContext(ctx, update)
+	ctx, failed := metrics.Track(ctx)
+	start := time.Now()
+
+	outcome := metrics.OutcomePanic
+	defer func() {
+		switch {
+		case outcome != metrics.OutcomeOK:
+		case ctx.Err() != nil:
+			outcome = metrics.OutcomeTimeout
+		case failed():
+			outcome = metrics.OutcomeError
+		}
+		metrics.ObserveUpdate(commandLabel(update), outcome, time.Since(start))
+	}()
+
+	outcome = routeUpdate(ctx, bot, update)
+}
+
+func routeUpdate(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) string {
+	if !firstDelivery(ctx, update) {
+		slog.InfoContext(ctx, "Duplicate update skipped")
+		return metrics.OutcomeDuplicate
+	}
+
+	// Every reply from here on, rate limit notices included, is in the user's language and currency.
+	ctx = handlers.WithUserSettings(ctx, update.SentFrom())
+
+	if !allowRate(ctx, bot, update) {
+		return metrics.OutcomeRateLimited
 	}
 
+	if update.CallbackQuery != nil {
+		routeCallback(ctx, bot, update.CallbackQuery)
+		return metrics.OutcomeOK
+	}
+
+	if update.InlineQuery != nil {
+		handlers.HandleInlineQuery(ctx, bot, update.InlineQuery)
+		return metrics.OutcomeOK
+	}
+
+	if update.ChosenInlineResult != nil {
+		handlers.HandleChosenInlineResult(ctx, bot, update.ChosenInlineResult)
+		return metrics.OutcomeOK
+	}
+
+	msg := updateMessage(update)
 	if msg == nil {
-		log.Println("[DEBUG] Update received with no message. Skipping...")
-		return
+		slog.DebugContext(ctx, "Update received with no message. Skipping")
+		return metrics.OutcomeIgnored
+	}
+
+	slog.InfoContext(ctx, "Message received", "text", msg.Text)
+
+	if len(msg.Photo) > 0 {
+		handlers.HandlePhoto(ctx, bot, msg)
+		return metrics.OutcomeOK
+	}
+
+	if msg.Voice != nil {
+		handlers.HandleVoice(ctx, bot, msg)
+		return metrics.OutcomeOK
+	}
+
+	if _, ok := nfce.FindURL(msg.Text); ok && !msg.IsCommand() {
+		handlers.HandleNFCeLink(ctx, bot, msg)
+		return metrics.OutcomeOK
+	}
+
+	if !msg.IsCommand() {
+		return metrics.OutcomeIgnored
+	}
+
+	if !handlers.AddressedToBot(bot, msg) {
+		slog.DebugContext(ctx, "Command for another bot. Skipping")
+		return metrics.OutcomeIgnored
+	}
+
+	slog.InfoContext(ctx, "Command received")
+
+	command, ok := handlers.LookupCommand(msg.Command())
+	if !ok {
+		slog.WarnContext(ctx, "Unknown command received")
+		handlers.HandleInvalidCommand(ctx, bot, msg)
+		return metrics.OutcomeOK
+	}
+	command.Handle(ctx, bot, msg)
+	return metrics.OutcomeOK
+}
+
+// commandLabel names the update in the metrics: its command, or the kind of
+// update for everything else.
+func commandLabel(update tgbotapi.Update) string {
+	switch {
+	case update.CallbackQuery != nil:
+		return "callback"
+	case update.InlineQuery != nil:
+		return "inline"
+	case update.ChosenInlineResult != nil:
+		return "inline_chosen"
+	}
+	msg := updateMessage(update)
+	switch {
+	case msg == nil:
+		return "other"
+	case len(msg.Photo) > 0:
+		return "photo"
+	case msg.Voice != nil:
+		return "voice"
+	case msg.IsCommand() && isRegistered(msg.Command()):
+		return msg.Command()
+	case msg.IsCommand():
+		return "unknown"
+	}
+	if _, ok := nfce.FindURL(msg.Text); ok {
+		return "nfce"
 	}
+	return "message"
+}
+
+// isRegistered reports whether name is a registered command. Other commands
+// are labelled "unknown" in the metrics, so users cannot create label values.
+func isRegistered(name string) bool {
+	_, ok := handlers.LookupCommand(name)
+	return ok
+}
 
-	log.Printf("[INFO] Message received: %q", msg.Text)
-
-	if msg.IsCommand() {
-		command := msg.Command()
-		log.Printf("[INFO] Command received: /%s", command)
-
-		switch command {
-		case "start":
-			handlers.HandleStart(bot, msg)
-		case "help":
-			handlers.HandleHelp(bot, msg)
-		case "gastei":
-			handlers.HandleExpense(bot, msg)
-		case "consulta":
-			handlers.HandleQuery(bot, msg)
-		case "deletar":
-			handlers.HandleDelete(bot, msg)
-		case "deletartudo":
-			handlers.HandleDeleteAll(bot, msg)
-		default:
-			log.Printf("[WARN] Unknown command received: /%s", command)
-			handlers.HandleInvalidCommand(bot, msg)
+// updateLogContext tags every log line of the update with its update_id,
+// user_id, chat_id and command.
+func updateLogContext(ctx context.Context, update tgbotapi.Update) context.Context {
+	attrs := []slog.Attr{slog.Int("update_id", update.UpdateID)}
+	if user := update.SentFrom(); user != nil {
+		attrs = append(attrs, slog.Int64("user_id", user.ID))
+	}
+	if msg := updateMessage(update); msg != nil {
+		attrs = append(attrs, slog.Int64("chat_id", msg.Chat.ID))
+		if msg.IsCommand() {
+			attrs = append(attrs, slog.String("command", msg.Command()))
 		}
+	} else if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		attrs = append(attrs, slog.Int64("chat_id", update.CallbackQuery.Message.Chat.ID))
+	}
+	return logging.With(ctx, attrs...)
+}
+
+// firstDelivery reports whether the update has not been handled before. When
+// the store fails the update is processed anyway: a rare duplicate is better
+// than dropping the user's message.
+func firstDelivery(ctx context.Context, update tgbotapi.Update) bool {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	isNew, err := updateStore.MarkProcessed(ctx, update)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to check update for duplicates", "error", err)
+		return true
 	}
+	return isNew
 }
 
+// routeCallback dispatches inline keyboard presses by their data prefix.
+func routeCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+	if callback.Message == nil {
+		slog.DebugContext(ctx, "Callback received without message. Skipping")
+		return
+	}
+
+	data := callback.Data
+	slog.InfoContext(ctx, "Callback received", "data", data)
 
-func Start(token string) error {
-	bot, err := tgbotapi.NewBotAPI(token)
+	switch {
+	case strings.HasPrefix(data, "qnav"):
+		handlers.HandleQueryCallback(ctx, bot, callback)
+	case strings.HasPrefix(data, "cancel_delete_all:") || strings.HasPrefix(data, "confirm_delete_all:"):
+		handlers.HandleDeleteAllCallback(ctx, bot, callback)
+	case strings.HasPrefix(data, "cancel_delete:") || strings.HasPrefix(data, "confirm_delete:"):
+		handlers.HandleConfirmDeleteCallback(ctx, bot, callback)
+	case strings.HasPrefix(data, "sug"):
+		handlers.HandleSuggestionCallback(ctx, bot, callback)
+	case strings.HasPrefix(data, "receipt:"):
+		handlers.HandleReceiptCallback(ctx, bot, callback)
+	case strings.HasPrefix(data, "draft"):
+		handlers.HandleDraftCallback(ctx, bot, callback)
+	case strings.HasPrefix(data, "lang:"):
+		handlers.HandleLanguageCallback(ctx, bot, callback)
+	case strings.HasPrefix(data, "trash:"):
+		handlers.HandleTrashCallback(ctx, bot, callback)
+	case strings.HasPrefix(data, "undo:"):
+		handlers.HandleUndoCallback(ctx, bot, callback)
+	case strings.HasPrefix(data, "run:"):
+		handlers.HandleRunCommandCallback(ctx, bot, callback)
+	default:
+		handlers.HandleUnknownCallback(ctx, bot, callback)
+	}
+}
+
+// Start polls Telegram for updates and handles them on a Dispatcher until ctx
+// is cancelled. It then stops polling and waits for the queued updates.
+func Start(ctx context.Context, token string) error {
+	bot, err := NewAPI(token)
 	if err != nil {
 		return err
 	}
 
-	log.Printf("[INFO] Bot authenticated successfully as @%s", bot.Self.UserName)
+	slog.InfoContext(ctx, "Bot authenticated successfully", "bot", bot.Self.UserName)
+
+	// getUpdates is refused while a webhook is registered (webhook mode).
+	if _, err := bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		slog.WarnContext(ctx, "Failed to remove webhook before polling", "error", err)
+	}
 
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
 	updates := bot.GetUpdatesChan(u)
 
-	for update := range updates {
-		RouteUpdate(bot, update)
+	registerCommands(ctx, bot)
+	go runReminders(ctx, bot)
+	if addr := metricsAddrFromEnv(); addr != "" {
+		go serveMetrics(ctx, addr)
+	}
+
+	// Updates already queued must finish after ctx is cancelled, so handlers
+	// get their own context, cancelled only if draining takes too long.
+	handlerCtx, cancelHandlers := context.WithCancel(context.Background())
+	defer cancelHandlers()
+
+	workers := workersFromEnv()
+	dispatcher := NewDispatcher(workers, DefaultQueueSize, func(update tgbotapi.Update) {
+		RouteUpdate(handlerCtx, bot, update)
+	})
+	slog.InfoContext(ctx, "Polling for updates", "workers", workers)
+
+poll:
+	for {
+		select {
+		case <-ctx.Done():
+			break poll
+		case update, ok := <-updates:
+			if !ok {
+				break poll
+			}
+			if err := dispatcher.Dispatch(ctx, update); err != nil {
+				break poll
+			}
 		}
+	}
+
+	slog.InfoContext(ctx, "Stopping polling, draining queued updates")
+	bot.StopReceivingUpdates()
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := dispatcher.Close(drainCtx); err != nil {
+		cancelHandlers()
+		return fmt.Errorf("draining updates: %w", err)
+	}
+	slog.InfoContext(ctx, "Bot stopped")
 	return nil
 }
 
+// reminderHour is the local hour after which the daily invoice reminders go out.
+const reminderHour = 9
+
+// runReminders sends the invoice reminders once a day while the bot is running
+// (polling or webhook server), until ctx is cancelled. In Lambda the same job runs from cmd/reminders on a schedule.
+// It also empties the trash of expired expenses, for tables without TTL such
+// as DynamoDB Local; in Lambda TTL does it.
+func runReminders(ctx context.Context, bot *tgbotapi.BotAPI) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	lastRun := ""
+	for {
+		var now time.Time
+		select {
+		case <-ctx.Done():
+			return
+		case now = <-ticker.C:
+		}
+
+		local := now.In(billing.Location)
+		today := local.Format("2006-01-02")
+		if local.Hour() < reminderHour || today == lastRun {
+			continue
+		}
+
+		jobCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		if err := handlers.SendInvoiceReminders(jobCtx, bot, now); err != nil {
+			slog.ErrorContext(ctx, "Failed to send invoice reminders", "error", err)
+		} else {
+			lastRun = today
+		}
+		if purged, err := database.PurgeDeletedExpenses(jobCtx, now); err != nil {
+			slog.ErrorContext(ctx, "Failed to purge deleted expenses", "error", err)
+		} else if purged > 0 {
+			slog.InfoContext(ctx, "Deleted expenses purged", "count", purged)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,47 @@
+package bot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"money-telegram-bot/internal/metrics"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// updatesCounted returns how many updates were counted with command and outcome.
+func updatesCounted(t *testing.T, command, outcome string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := metrics.Default.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	prefix := `moneybot_updates_total{command="` + command + `",outcome="` + outcome + `"} `
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), prefix); ok {
+			return value
+		}
+	}
+	return "0"
+}
+
+func TestRouteUpdateReportsCancelledUpdatesAsTimeouts(t *testing.T) {
+	defer SetUpdateStore(updateStore)
+	SetUpdateStore(NewMemoryUpdateStore(DefaultMemoryUpdates))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A callback whose message is gone is answered without any storage call.
+	update := tgbotapi.Update{UpdateID: 4004, CallbackQuery: &tgbotapi.CallbackQuery{From: &tgbotapi.User{ID: 7}}}
+	before := updatesCounted(t, "callback", metrics.OutcomeTimeout)
+	RouteUpdate(ctx, nil, update)
+
+	if after := updatesCounted(t, "callback", metrics.OutcomeTimeout); after == before {
+		t.Errorf("cancelled update not counted as a timeout (still %s)", after)
+	}
+}
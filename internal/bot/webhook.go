@@ -0,0 +1,229 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SecretTokenHeader carries the secret_token registered with setWebhook on
+// every request Telegram sends to the webhook.
+const SecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// MaxUpdateBytes caps the body of a webhook request. Real updates are a few
+// kilobytes; anything bigger is not from Telegram.
+const MaxUpdateBytes = 1 << 20
+
+var (
+	ErrBodyTooLarge    = errors.New("update body too large")
+	ErrMalformedUpdate = errors.New("malformed update")
+)
+
+// ValidSecretToken reports whether the header value matches the configured
+// secret, in constant time.
+func ValidSecretToken(expected, received string) bool {
+	if expected == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(received)) == 1
+}
+
+// DecodeUpdate reads a Telegram update from a webhook body, rejecting bodies
+// over MaxUpdateBytes and anything that is not an update.
+func DecodeUpdate(body io.Reader) (tgbotapi.Update, error) {
+	data, err := io.ReadAll(io.LimitReader(body, MaxUpdateBytes+1))
+	if err != nil {
+		return tgbotapi.Update{}, err
+	}
+	if len(data) > MaxUpdateBytes {
+		return tgbotapi.Update{}, ErrBodyTooLarge
+	}
+
+	var update tgbotapi.Update
+	if err := json.Unmarshal(data, &update); err != nil {
+		return tgbotapi.Update{}, fmt.Errorf("%w: %v", ErrMalformedUpdate, err)
+	}
+	if update.UpdateID <= 0 {
+		return tgbotapi.Update{}, fmt.Errorf("%w: missing update_id", ErrMalformedUpdate)
+	}
+	return update, nil
+}
+
+// WebhookConfig configures the self-hosted webhook server.
+type WebhookConfig struct {
+	URL        string // public HTTPS URL registered with Telegram, including the path
+	Secret     string // secret_token Telegram echoes in SecretTokenHeader
+	ListenAddr string // address the HTTP server binds to
+}
+
+// WebhookConfigFromEnv reads WEBHOOK_URL, WEBHOOK_SECRET and PORT (or LISTEN_ADDR).
+func WebhookConfigFromEnv() (WebhookConfig, error) {
+	cfg := WebhookConfig{
+		URL:        os.Getenv("WEBHOOK_URL"),
+		Secret:     os.Getenv("WEBHOOK_SECRET"),
+		ListenAddr: os.Getenv("LISTEN_ADDR"),
+	}
+	if cfg.ListenAddr == "" {
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "8080"
+		}
+		cfg.ListenAddr = ":" + port
+	}
+
+	if cfg.URL == "" {
+		return cfg, errors.New("WEBHOOK_URL environment variable not configured")
+	}
+	if !strings.HasPrefix(cfg.URL, "https://") {
+		return cfg, errors.New("WEBHOOK_URL must be an https:// URL")
+	}
+	// Telegram accepts 1-256 characters: A-Z, a-z, 0-9, _ and -.
+	if len(cfg.Secret) < 16 || len(cfg.Secret) > 256 || strings.Trim(cfg.Secret, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789_-") != "" {
+		return cfg, errors.New("WEBHOOK_SECRET must have 16-256 characters among A-Z, a-z, 0-9, _ and -")
+	}
+	return cfg, nil
+}
+
+// StartWebhook registers the webhook with Telegram and serves updates over
+// HTTP until ctx is cancelled, then shuts the server down gracefully.
+// Besides the webhook path it serves /healthz (liveness) and /readyz
+// (ready once the webhook is registered, until shutdown starts). /metrics is
+// served on its own internal listener, never on the public port.
+func StartWebhook(ctx context.Context, token string, cfg WebhookConfig) error {
+	bot, err := NewAPI(token)
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Bot authenticated successfully", "bot", bot.Self.UserName)
+
+	webhookURL, err := url.Parse(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("invalid WEBHOOK_URL: %w", err)
+	}
+	path := webhookURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	var ready atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !ready.Load() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "ready")
+	})
+	mux.Handle(path, webhookHandler(bot, cfg.Secret))
+
+	server := &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.InfoContext(ctx, "Webhook server listening", "addr", cfg.ListenAddr, "path", path)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	if err := registerWebhook(bot, cfg); err != nil {
+		server.Close()
+		return err
+	}
+	ready.Store(true)
+	slog.InfoContext(ctx, "Webhook registered", "url", webhookURL.Redacted())
+
+	registerCommands(ctx, bot)
+	go runReminders(ctx, bot)
+	if addr := metricsAddrFromEnv(); addr != "" {
+		go serveMetrics(ctx, addr)
+	}
+
+	select {
+	case err, ok := <-serverErr:
+		if ok {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	slog.InfoContext(ctx, "Shutting down webhook server")
+	ready.Store(false)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("webhook server shutdown: %w", err)
+	}
+	slog.InfoContext(ctx, "Webhook server stopped")
+	return nil
+}
+
+// registerWebhook calls setWebhook with the secret token. The library's
+// WebhookConfig has no secret_token field, so the request is built by hand.
+func registerWebhook(bot *tgbotapi.BotAPI, cfg WebhookConfig) error {
+	params := tgbotapi.Params{}
+	params["url"] = cfg.URL
+	params["secret_token"] = cfg.Secret
+	params.AddBool("drop_pending_updates", false)
+
+	if _, err := bot.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("setWebhook failed: %w", err)
+	}
+	return nil
+}
+
+func webhookHandler(bot *tgbotapi.BotAPI, secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !ValidSecretToken(secret, r.Header.Get(SecretTokenHeader)) {
+			slog.Warn("Webhook request with invalid secret token", "remote", r.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		update, err := DecodeUpdate(r.Body)
+		switch {
+		case errors.Is(err, ErrBodyTooLarge):
+			http.Error(w, "request entity too large", http.StatusRequestEntityTooLarge)
+			return
+		case err != nil:
+			slog.Error("Failed to parse Telegram update", "error", err)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		RouteUpdate(r.Context(), bot, update)
+		w.WriteHeader(http.StatusOK)
+	})
+}
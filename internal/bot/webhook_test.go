@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidSecretToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		received string
+		want     bool
+	}{
+		{"match", "0123456789abcdef", "0123456789abcdef", true},
+		{"wrong", "0123456789abcdef", "0123456789abcdeg", false},
+		{"prefix", "0123456789abcdef", "0123456789abcde", false},
+		{"missing", "0123456789abcdef", "", false},
+		{"none configured", "", "", false},
+		{"case", "0123456789abcdef", "0123456789ABCDEF", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidSecretToken(tt.expected, tt.received); got != tt.want {
+				t.Errorf("ValidSecretToken(%q, %q) = %v, want %v", tt.expected, tt.received, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantID  int
+		wantErr error
+	}{
+		{"message", `{"update_id":7,"message":{"message_id":1,"text":"oi","chat":{"id":1}}}`, 7, nil},
+		{"largest body", `{"update_id":8}` + strings.Repeat(" ", MaxUpdateBytes-len(`{"update_id":8}`)), 8, nil},
+		{"too large", `{"update_id":9}` + strings.Repeat(" ", MaxUpdateBytes), 0, ErrBodyTooLarge},
+		{"not json", `update`, 0, ErrMalformedUpdate},
+		{"truncated", `{"update_id":`, 0, ErrMalformedUpdate},
+		{"no update_id", `{"message":{"message_id":1}}`, 0, ErrMalformedUpdate},
+		{"empty", ``, 0, ErrMalformedUpdate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update, err := DecodeUpdate(strings.NewReader(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if update.UpdateID != tt.wantID {
+				t.Errorf("update_id = %d, want %d", update.UpdateID, tt.wantID)
+			}
+		})
+	}
+}
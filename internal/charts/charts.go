@@ -0,0 +1,165 @@
+// Package charts renders simple PNG charts in pure Go. The images carry no
+// text: callers describe them in the photo caption, using the Emoji of each
+// palette color as the legend.
+package charts
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+// Color is a palette entry with the emoji that represents it in a legend.
+type Color struct {
+	RGBA  color.RGBA
+	Emoji string
+}
+
+// Palette holds the slice colors, in order. The last entry is meant for "others".
+var Palette = []Color{
+	{color.RGBA{R: 0xdd, G: 0x2e, B: 0x44, A: 0xff}, "🟥"},
+	{color.RGBA{R: 0x55, G: 0xac, B: 0xee, A: 0xff}, "🟦"},
+	{color.RGBA{R: 0x78, G: 0xb1, B: 0x59, A: 0xff}, "🟩"},
+	{color.RGBA{R: 0xf4, G: 0x90, B: 0x0c, A: 0xff}, "🟧"},
+	{color.RGBA{R: 0xaa, G: 0x8e, B: 0xd6, A: 0xff}, "🟪"},
+	{color.RGBA{R: 0xfd, G: 0xcb, B: 0x58, A: 0xff}, "🟨"},
+	{color.RGBA{R: 0xc1, G: 0x69, B: 0x4f, A: 0xff}, "🟫"},
+	{color.RGBA{R: 0x31, G: 0x37, B: 0x3d, A: 0xff}, "⬛"},
+}
+
+var (
+	background = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	axisColor  = color.RGBA{R: 0x66, G: 0x66, B: 0x66, A: 0xff}
+	gridColor  = color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+	barColor   = Palette[1].RGBA
+)
+
+// ErrNoData is returned when there is nothing to draw.
+var ErrNoData = errors.New("charts: no data to draw")
+
+// Pie renders a pie chart of size x size pixels. Slice i is drawn with
+// Palette[i]; values past the palette length are not supported.
+func Pie(values []float64, size int) ([]byte, error) {
+	if len(values) > len(Palette) {
+		return nil, errors.New("charts: too many pie slices")
+	}
+
+	var total float64
+	for _, v := range values {
+		if v < 0 {
+			return nil, errors.New("charts: negative pie value")
+		}
+		total += v
+	}
+	if total == 0 {
+		return nil, ErrNoData
+	}
+
+	// Cumulative end angle of each slice, clockwise from 12 o'clock.
+	ends := make([]float64, len(values))
+	var acc float64
+	for i, v := range values {
+		acc += v / total
+		ends[i] = acc * 2 * math.Pi
+	}
+
+	img := newCanvas(size, size)
+	center := float64(size) / 2
+	radius := center * 0.92
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx := float64(x) + 0.5 - center
+			dy := float64(y) + 0.5 - center
+			if dx*dx+dy*dy > radius*radius {
+				continue
+			}
+			angle := math.Atan2(dx, -dy)
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			for i, end := range ends {
+				if angle <= end {
+					img.SetRGBA(x, y, Palette[i].RGBA)
+					break
+				}
+			}
+		}
+	}
+
+	return encode(img)
+}
+
+// Bars renders a bar chart of width x height pixels, one bar per value, with
+// horizontal grid lines at each quarter of the highest value.
+func Bars(values []float64, width, height int) ([]byte, error) {
+	if len(values) == 0 {
+		return nil, ErrNoData
+	}
+
+	var highest float64
+	for _, v := range values {
+		if v > highest {
+			highest = v
+		}
+	}
+	if highest <= 0 {
+		return nil, ErrNoData
+	}
+
+	img := newCanvas(width, height)
+	margin := width / 20
+	plotLeft, plotRight := margin, width-margin
+	plotTop, plotBottom := margin, height-margin
+	plotHeight := plotBottom - plotTop
+
+	for q := 1; q <= 4; q++ {
+		y := plotBottom - plotHeight*q/4
+		fill(img, plotLeft, y, plotRight, y+1, gridColor)
+	}
+
+	slot := float64(plotRight-plotLeft) / float64(len(values))
+	gap := slot * 0.2
+	for i, v := range values {
+		if v <= 0 {
+			continue
+		}
+		x0 := plotLeft + int(float64(i)*slot+gap/2)
+		x1 := plotLeft + int(float64(i+1)*slot-gap/2)
+		if x1 <= x0 {
+			x1 = x0 + 1
+		}
+		barHeight := int(math.Round(v / highest * float64(plotHeight)))
+		fill(img, x0, plotBottom-barHeight, x1, plotBottom, barColor)
+	}
+
+	fill(img, plotLeft, plotBottom, plotRight, plotBottom+2, axisColor)
+	fill(img, plotLeft-2, plotTop, plotLeft, plotBottom+2, axisColor)
+
+	return encode(img)
+}
+
+func newCanvas(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fill(img, 0, 0, width, height, background)
+	return img
+}
+
+func fill(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	r := image.Rect(x0, y0, x1, y1).Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
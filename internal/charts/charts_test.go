@@ -0,0 +1,120 @@
+package charts
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func decode(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("not a PNG: %v", err)
+	}
+	return img
+}
+
+func assertColor(t *testing.T, img image.Image, x, y int, want color.RGBA) {
+	t.Helper()
+	if got := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA); got != want {
+		t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+	}
+}
+
+func TestPie(t *testing.T) {
+	data, err := Pie([]float64{1, 1}, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := decode(t, data)
+	if size := img.Bounds().Size(); size != image.Pt(100, 100) {
+		t.Fatalf("size = %v, want 100x100", size)
+	}
+
+	// Slices go clockwise from 12 o'clock: the first half is on the right.
+	assertColor(t, img, 75, 25, Palette[0].RGBA)
+	assertColor(t, img, 75, 75, Palette[0].RGBA)
+	assertColor(t, img, 25, 75, Palette[1].RGBA)
+	assertColor(t, img, 25, 25, Palette[1].RGBA)
+	assertColor(t, img, 0, 0, background)
+}
+
+func TestPieSingleSlice(t *testing.T) {
+	data, err := Pie([]float64{0, 42}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := decode(t, data)
+	for _, p := range []image.Point{{45, 15}, {45, 45}, {15, 45}, {15, 15}} {
+		assertColor(t, img, p.X, p.Y, Palette[1].RGBA)
+	}
+}
+
+func TestPieErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		noData bool
+	}{
+		{"empty", nil, true},
+		{"all zero", []float64{0, 0}, true},
+		{"negative", []float64{3, -1}, false},
+		{"too many slices", make([]float64, len(Palette)+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Pie(tt.values, 100)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if errors.Is(err, ErrNoData) != tt.noData {
+				t.Errorf("err = %v, ErrNoData expected: %v", err, tt.noData)
+			}
+		})
+	}
+}
+
+func TestBars(t *testing.T) {
+	// 200x100 leaves a 10px margin: the plot spans x 10..190 and y 10..90.
+	data, err := Bars([]float64{1, 2}, 200, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := decode(t, data)
+	if size := img.Bounds().Size(); size != image.Pt(200, 100) {
+		t.Fatalf("size = %v, want 200x100", size)
+	}
+
+	// The first bar reaches half of the plot, the highest one all of it.
+	assertColor(t, img, 55, 60, barColor)
+	assertColor(t, img, 55, 40, background)
+	assertColor(t, img, 145, 20, barColor)
+	// Gap between the bars, grid line and axes.
+	assertColor(t, img, 100, 60, background)
+	assertColor(t, img, 100, 50, gridColor)
+	assertColor(t, img, 100, 91, axisColor)
+	assertColor(t, img, 9, 60, axisColor)
+}
+
+func TestBarsSkipsEmptyValues(t *testing.T) {
+	data, err := Bars([]float64{0, 5, -1}, 300, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := decode(t, data)
+	assertColor(t, img, 60, 80, background)
+	assertColor(t, img, 150, 80, barColor)
+	assertColor(t, img, 240, 80, background)
+}
+
+func TestBarsNoData(t *testing.T) {
+	for _, values := range [][]float64{nil, {0, 0}, {-3}} {
+		if _, err := Bars(values, 200, 100); !errors.Is(err, ErrNoData) {
+			t.Errorf("Bars(%v) error = %v, want ErrNoData", values, err)
+		}
+	}
+}
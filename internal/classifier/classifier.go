@@ -0,0 +1,162 @@
+// Package classifier guesses the category and payment method of a new expense
+// from the user's own history. It is a small multinomial naive Bayes model that
+// is trained on demand, so corrections saved by the user are picked up on the
+// next expense without any extra persistence.
+package classifier
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"money-telegram-bot/internal/models"
+)
+
+// UnknownMethod is the method stored when the user does not inform one.
+// It is never learned nor suggested.
+const UnknownMethod = "desconhecido"
+
+// Suggestion is a candidate value with its estimated probability (0..1).
+type Suggestion struct {
+	Value       string
+	Probability float64
+}
+
+// Model holds one naive Bayes classifier for categories and another for methods.
+type Model struct {
+	categories *naiveBayes
+	methods    *naiveBayes
+}
+
+// Train builds a model from the user's existing expenses.
+func Train(expenses []models.Expense) *Model {
+	m := &Model{
+		categories: newNaiveBayes(),
+		methods:    newNaiveBayes(),
+	}
+
+	for _, expense := range expenses {
+		tokens := Tokenize(expense.Label())
+		if len(tokens) == 0 {
+			continue
+		}
+		if expense.Category != "" {
+			m.categories.add(expense.Category, tokens)
+		}
+		if expense.Method != "" && expense.Method != UnknownMethod {
+			m.methods.add(expense.Method, tokens)
+		}
+	}
+
+	return m
+}
+
+// SuggestCategories returns up to n categories for the description, best first.
+// Nothing is returned when none of the description words was seen before, since
+// the answer would just be the user's most frequent category.
+func (m *Model) SuggestCategories(description string, n int) []Suggestion {
+	tokens := Tokenize(description)
+	if !m.categories.knows(tokens) {
+		return nil
+	}
+	return m.categories.predict(tokens, n)
+}
+
+// SuggestMethods returns up to n payment methods for the description, best first.
+// Unlike categories, the prior alone is a useful guess: most people pay almost
+// everything the same way.
+func (m *Model) SuggestMethods(description string, n int) []Suggestion {
+	return m.methods.predict(Tokenize(description), n)
+}
+
+// Tokenize lowercases the text and splits it into letter/digit words.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+type naiveBayes struct {
+	docs        int
+	classDocs   map[string]int
+	tokenCounts map[string]map[string]int
+	classTokens map[string]int
+	vocabulary  map[string]struct{}
+}
+
+func newNaiveBayes() *naiveBayes {
+	return &naiveBayes{
+		classDocs:   make(map[string]int),
+		tokenCounts: make(map[string]map[string]int),
+		classTokens: make(map[string]int),
+		vocabulary:  make(map[string]struct{}),
+	}
+}
+
+func (nb *naiveBayes) add(class string, tokens []string) {
+	nb.docs++
+	nb.classDocs[class]++
+	if nb.tokenCounts[class] == nil {
+		nb.tokenCounts[class] = make(map[string]int)
+	}
+	for _, token := range tokens {
+		nb.tokenCounts[class][token]++
+		nb.classTokens[class]++
+		nb.vocabulary[token] = struct{}{}
+	}
+}
+
+func (nb *naiveBayes) knows(tokens []string) bool {
+	for _, token := range tokens {
+		if _, ok := nb.vocabulary[token]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (nb *naiveBayes) predict(tokens []string, n int) []Suggestion {
+	if nb.docs == 0 || n <= 0 {
+		return nil
+	}
+
+	vocabSize := float64(len(nb.vocabulary))
+	scores := make(map[string]float64, len(nb.classDocs))
+	best := math.Inf(-1)
+	for class, docs := range nb.classDocs {
+		score := math.Log(float64(docs) / float64(nb.docs))
+		for _, token := range tokens {
+			// Laplace smoothing keeps unseen words from zeroing a class out.
+			count := float64(nb.tokenCounts[class][token])
+			score += math.Log((count + 1) / (float64(nb.classTokens[class]) + vocabSize))
+		}
+		scores[class] = score
+		if score > best {
+			best = score
+		}
+	}
+
+	// Normalise the log scores into probabilities (softmax).
+	var sum float64
+	for class, score := range scores {
+		scores[class] = math.Exp(score - best)
+		sum += scores[class]
+	}
+
+	suggestions := make([]Suggestion, 0, len(scores))
+	for class, score := range scores {
+		suggestions = append(suggestions, Suggestion{Value: class, Probability: score / sum})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Probability != suggestions[j].Probability {
+			return suggestions[i].Probability > suggestions[j].Probability
+		}
+		return suggestions[i].Value < suggestions[j].Value
+	})
+
+	if len(suggestions) > n {
+		suggestions = suggestions[:n]
+	}
+	return suggestions
+}
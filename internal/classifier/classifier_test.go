@@ -0,0 +1,151 @@
+package classifier
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"money-telegram-bot/internal/models"
+)
+
+func history() []models.Expense {
+	return []models.Expense{
+		{Description: "ifood pizza", Category: "delivery", Method: "nubank"},
+		{Description: "ifood lanche", Category: "delivery", Method: "nubank"},
+		{Description: "ifood japonês", Category: "delivery", Method: "itau"},
+		{Description: "uber trabalho", Category: "transporte", Method: "nubank"},
+		{Description: "uber aeroporto", Category: "transporte", Method: "nubank"},
+		{Description: "mercado", Category: "mercado", Method: "pix"},
+		{Description: "padaria", Category: "padaria", Method: UnknownMethod},
+	}
+}
+
+func values(suggestions []Suggestion) []string {
+	var values []string
+	for _, s := range suggestions {
+		values = append(values, s.Value)
+	}
+	return values
+}
+
+func TestSuggestCategories(t *testing.T) {
+	model := Train(history())
+	tests := []struct {
+		description string
+		n           int
+		want        []string
+	}{
+		{"ifood", 1, []string{"delivery"}},
+		{"Uber para casa", 1, []string{"transporte"}},
+		{"ifood", 3, []string{"delivery", "transporte", "mercado"}},
+		{"cinema", 3, nil}, // no known word: the prior alone is no guess
+		{"", 3, nil},
+		{"ifood", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			got := model.SuggestCategories(tt.description, tt.n)
+			if !reflect.DeepEqual(values(got), tt.want) {
+				t.Errorf("SuggestCategories(%q, %d) = %v, want %v", tt.description, tt.n, values(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestMethods(t *testing.T) {
+	model := Train(history())
+	tests := []struct {
+		description string
+		want        []string
+	}{
+		{"uber", []string{"nubank", "pix", "itau"}},
+		// One "mercado" paid with pix does not beat four nubank payments...
+		{"mercado", []string{"nubank", "pix", "itau"}},
+		// ...and unknown words fall back to how the user usually pays.
+		{"cinema", []string{"nubank", "pix", "itau"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			got := model.SuggestMethods(tt.description, 3)
+			if !reflect.DeepEqual(values(got), tt.want) {
+				t.Errorf("SuggestMethods(%q) = %v, want %v", tt.description, values(got), tt.want)
+			}
+			for _, s := range got {
+				if s.Value == UnknownMethod {
+					t.Errorf("suggested the unknown method placeholder")
+				}
+			}
+		})
+	}
+}
+
+func TestSuggestionsAreProbabilities(t *testing.T) {
+	model := Train(history())
+	got := model.SuggestCategories("ifood", 10)
+	var sum float64
+	for i, s := range got {
+		if s.Probability <= 0 || s.Probability > 1 {
+			t.Errorf("%s has probability %v", s.Value, s.Probability)
+		}
+		if i > 0 && s.Probability > got[i-1].Probability {
+			t.Errorf("%s ranked below a less likely category", s.Value)
+		}
+		sum += s.Probability
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("probabilities add up to %v", sum)
+	}
+}
+
+func TestEmptyHistory(t *testing.T) {
+	for _, expenses := range [][]models.Expense{nil, {{Description: "!!!", Category: "outros", Method: "pix"}}} {
+		model := Train(expenses)
+		if got := model.SuggestCategories("ifood", 3); got != nil {
+			t.Errorf("categories = %v, want none", got)
+		}
+		if got := model.SuggestMethods("ifood", 3); got != nil {
+			t.Errorf("methods = %v, want none", got)
+		}
+	}
+}
+
+func TestWordsOutweighThePrior(t *testing.T) {
+	expenses := append(history(),
+		models.Expense{Description: "mercado", Category: "mercado", Method: "pix"},
+		models.Expense{Description: "mercado extra", Category: "mercado", Method: "pix"},
+	)
+	if got := Train(expenses).SuggestMethods("mercado", 1); len(got) != 1 || got[0].Value != "pix" {
+		t.Errorf("SuggestMethods = %v, want pix", values(got))
+	}
+}
+
+func TestCorrectionsAreLearned(t *testing.T) {
+	expenses := history()
+	// The user moved the first ifood expense, and two new ones, to "comida".
+	expenses[0].Category = "comida"
+	expenses = append(expenses,
+		models.Expense{Description: "ifood", Category: "comida", Method: "nubank"},
+		models.Expense{Description: "ifood", Category: "comida", Method: "nubank"},
+	)
+	if got := Train(expenses).SuggestCategories("ifood", 1); len(got) != 1 || got[0].Value != "comida" {
+		t.Errorf("SuggestCategories = %v, want comida", values(got))
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"iFood Pizza", []string{"ifood", "pizza"}},
+		{"pão-de-açúcar, 2x", []string{"pão", "de", "açúcar", "2x"}},
+		{"  ", []string{}},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package currency converts amounts between currencies. Rate providers are
+// pluggable: the bot reads a cached-rates file, users can pin their own rates
+// with /cotacao, and tests can use Fake.
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrNoRate is returned when a provider has no rate for a pair of currencies.
+var ErrNoRate = errors.New("currency: no exchange rate")
+
+// Default is the base currency of users who never chose one.
+const Default = "BRL"
+
+// symbols are the currencies the bot accepts, by ISO 4217 code.
+var symbols = map[string]string{
+	"BRL": "R$",
+	"USD": "US$",
+	"EUR": "€",
+	"GBP": "£",
+	"ARS": "AR$",
+	"CLP": "CLP$",
+	"UYU": "$U",
+	"PYG": "₲",
+	"MXN": "MX$",
+	"CAD": "C$",
+	"AUD": "A$",
+	"JPY": "¥",
+	"CHF": "CHF",
+	"CNY": "CN¥",
+}
+
+// Parse reads a currency code ("usd", "EUR"), reporting whether it is supported.
+func Parse(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	_, ok := symbols[code]
+	return code, ok
+}
+
+// Symbol returns the symbol of a currency ("US$"), or its code when it has none.
+func Symbol(code string) string {
+	if symbol, ok := symbols[code]; ok {
+		return symbol
+	}
+	return code
+}
+
+// ExchangeRateProvider tells how many units of one currency buy one unit of another.
+type ExchangeRateProvider interface {
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// Convert returns amount, in from, converted to to, and the rate used.
+func Convert(ctx context.Context, provider ExchangeRateProvider, amount float64, from, to string) (float64, float64, error) {
+	if from == to {
+		return amount, 1, nil
+	}
+	rate, err := provider.Rate(ctx, from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return 0, 0, fmt.Errorf("currency: invalid rate %v for %s/%s", rate, from, to)
+	}
+	return math.Round(amount*rate*100) / 100, rate, nil
+}
+
+// Overrides are rates pinned by a user, in units of Base per unit of each
+// currency. Pairs without a pinned rate are asked to Next.
+type Overrides struct {
+	Base  string
+	Rates map[string]float64
+	Next  ExchangeRateProvider
+}
+
+// Rate returns the pinned rate of the pair, inverting it when converting from
+// the base currency.
+func (o Overrides) Rate(ctx context.Context, from, to string) (float64, error) {
+	if rate, ok := o.Rates[from]; ok && to == o.Base {
+		return rate, nil
+	}
+	if rate, ok := o.Rates[to]; ok && from == o.Base {
+		return 1 / rate, nil
+	}
+	if o.Next == nil {
+		return 0, ErrNoRate
+	}
+	return o.Next.Rate(ctx, from, to)
+}
+
+// Disabled is a provider without rates, for deployments without a rates file.
+type Disabled struct{}
+
+// Rate always returns ErrNoRate.
+func (Disabled) Rate(context.Context, string, string) (float64, error) {
+	return 0, ErrNoRate
+}
+
+// Fake returns rates from a table keyed by "FROM/TO", for tests.
+type Fake struct {
+	Rates map[string]float64
+	Err   error
+}
+
+// Rate returns the configured rate and error.
+func (f Fake) Rate(_ context.Context, from, to string) (float64, error) {
+	if f.Err != nil {
+		return 0, f.Err
+	}
+	if rate, ok := f.Rates[from+"/"+to]; ok {
+		return rate, nil
+	}
+	return 0, ErrNoRate
+}
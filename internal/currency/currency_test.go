@@ -0,0 +1,113 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConvert(t *testing.T) {
+	rates := Fake{Rates: map[string]float64{"USD/BRL": 5.42, "EUR/BRL": -1}}
+	tests := []struct {
+		name      string
+		amount    float64
+		from, to  string
+		want      float64
+		wantRate  float64
+		wantError bool
+	}{
+		{"same currency", 10, "BRL", "BRL", 10, 1, false},
+		{"rounded to cents", 20.01, "USD", "BRL", 108.45, 5.42, false},
+		{"no rate", 10, "JPY", "BRL", 0, 0, true},
+		{"invalid rate", 10, "EUR", "BRL", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rate, err := Convert(context.Background(), rates, tt.amount, tt.from, tt.to)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantError)
+			}
+			if got != tt.want || rate != tt.wantRate {
+				t.Errorf("Convert = %v at %v, want %v at %v", got, rate, tt.want, tt.wantRate)
+			}
+		})
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	next := Fake{Rates: map[string]float64{"USD/BRL": 5.42, "EUR/BRL": 5.90}}
+	pinned := Overrides{Base: "BRL", Rates: map[string]float64{"USD": 5}, Next: next}
+
+	tests := []struct {
+		from, to string
+		want     float64
+	}{
+		{"USD", "BRL", 5},    // pinned
+		{"BRL", "USD", 0.2},  // pinned, inverted
+		{"EUR", "BRL", 5.90}, // from the next provider
+	}
+	for _, tt := range tests {
+		got, err := pinned.Rate(context.Background(), tt.from, tt.to)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Rate(%s, %s) = %v, %v; want %v", tt.from, tt.to, got, err, tt.want)
+		}
+	}
+
+	if _, err := (Overrides{Base: "BRL"}).Rate(context.Background(), "EUR", "BRL"); !errors.Is(err, ErrNoRate) {
+		t.Errorf("Rate without a pinned rate nor next provider: err = %v, want ErrNoRate", err)
+	}
+
+	failure := errors.New("rates service down")
+	if _, err := (Overrides{Base: "BRL", Next: Fake{Err: failure}}).Rate(context.Background(), "EUR", "BRL"); !errors.Is(err, failure) {
+		t.Errorf("err = %v, want the next provider's error", err)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	file := NewFile(path)
+
+	if _, err := file.Rate(context.Background(), "USD", "BRL"); !errors.Is(err, ErrNoRate) {
+		t.Errorf("missing file: err = %v, want ErrNoRate", err)
+	}
+
+	write := func(content string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"base": "usd", "date": "2026-10-18", "rates": {"BRL": 5.40, "EUR": 0.90, "XYZ": 2}}`, time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		from, to string
+		want     float64
+	}{
+		{"USD", "BRL", 5.40},
+		{"EUR", "BRL", 6}, // crossed through USD
+		{"BRL", "USD", 1 / 5.40},
+	}
+	for _, tt := range tests {
+		got, err := file.Rate(context.Background(), tt.from, tt.to)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Rate(%s, %s) = %v, %v; want %v", tt.from, tt.to, got, err, tt.want)
+		}
+	}
+	if _, err := file.Rate(context.Background(), "XYZ", "BRL"); !errors.Is(err, ErrNoRate) {
+		t.Errorf("unsupported currency: err = %v, want ErrNoRate", err)
+	}
+
+	// A refreshed file is read again.
+	write(`{"base": "USD", "rates": {"BRL": 5.50}}`, time.Now())
+	if got, err := file.Rate(context.Background(), "USD", "BRL"); err != nil || got != 5.50 {
+		t.Errorf("after refresh Rate = %v, %v; want 5.5", got, err)
+	}
+}
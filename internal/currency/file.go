@@ -0,0 +1,101 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File reads rates from a JSON file in the format of most exchange-rate APIs,
+// so a cron job can refresh it with curl and the bot keeps working offline:
+//
+//	{"base": "USD", "date": "2026-10-18", "rates": {"BRL": 5.42, "EUR": 0.92}}
+//
+// Rates are units of each currency per unit of base; pairs that don't involve
+// base are crossed through it. The file is read again when it changes.
+type File struct {
+	Path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	rates   map[string]float64
+}
+
+// NewFile returns a provider reading the rates file at path.
+func NewFile(path string) *File {
+	return &File{Path: path}
+}
+
+type ratesFile struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Rate returns how many units of to buy one unit of from. It returns ErrNoRate
+// when the file is missing or lacks one of the currencies.
+func (f *File) Rate(_ context.Context, from, to string) (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return 0, err
+	}
+	fromRate, okFrom := f.rates[from]
+	toRate, okTo := f.rates[to]
+	if !okFrom || !okTo || fromRate <= 0 {
+		return 0, ErrNoRate
+	}
+	return toRate / fromRate, nil
+}
+
+// load reads the file when it changed since the last read.
+func (f *File) load() error {
+	info, err := os.Stat(f.Path)
+	if os.IsNotExist(err) {
+		return ErrNoRate
+	}
+	if err != nil {
+		return err
+	}
+	if f.rates != nil && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return err
+	}
+	var parsed ratesFile
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return fmt.Errorf("currency: invalid rates file %s: %w", f.Path, err)
+	}
+	base := strings.ToUpper(strings.TrimSpace(parsed.Base))
+	if base == "" {
+		return fmt.Errorf("currency: rates file %s has no base", f.Path)
+	}
+
+	rates := make(map[string]float64, len(parsed.Rates)+1)
+	for code, rate := range parsed.Rates {
+		if code, ok := Parse(code); ok {
+			rates[code] = rate
+		}
+	}
+	rates[base] = 1
+
+	f.rates, f.modTime = rates, info.ModTime()
+	return nil
+}
+
+// FromEnv returns the provider configured by RATES_FILE, or Disabled when it
+// is not set.
+func FromEnv() ExchangeRateProvider {
+	if path := os.Getenv("RATES_FILE"); path != "" {
+		return NewFile(path)
+	}
+	return Disabled{}
+}
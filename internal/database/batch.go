@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"money-telegram-bot/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// batchSize is the most items a BatchWriteItem call takes.
+	batchSize = 25
+	// batchAttempts is how many times items DynamoDB leaves unprocessed
+	// (throttling) are sent again before giving up.
+	batchAttempts = 5
+)
+
+// batchBackoff is the wait before the first retry of unprocessed items; it
+// doubles on every attempt.
+var batchBackoff = 50 * time.Millisecond
+
+// putExpenseItems writes items in batches, in order. It returns the items
+// that were written, even when it fails halfway, so the caller knows what
+// changed.
+func putExpenseItems(ctx context.Context, items []models.Expense) ([]models.Expense, error) {
+	if dynamoClient == nil {
+		return nil, fmt.Errorf("DynamoDB client is not initialized")
+	}
+
+	written := make([]models.Expense, 0, len(items))
+	for start := 0; start < len(items); start += batchSize {
+		batch := items[start:min(start+batchSize, len(items))]
+		done, err := writeBatch(ctx, batch)
+		written = append(written, done...)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to write expenses", "written", len(written), "total", len(items), "error", err)
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// writeBatch writes up to batchSize items, retrying the unprocessed ones with
+// backoff, and returns the items that were written.
+func writeBatch(ctx context.Context, batch []models.Expense) ([]models.Expense, error) {
+	requests := make([]types.WriteRequest, len(batch))
+	for i := range batch {
+		av, err := attributevalue.MarshalMap(&batch[i])
+		if err != nil {
+			return nil, err
+		}
+		requests[i] = types.WriteRequest{PutRequest: &types.PutRequest{Item: av}}
+	}
+
+	wait := batchBackoff
+	for attempt := 1; ; attempt++ {
+		result, err := dynamoClient.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{tableName: requests},
+		})
+		if err != nil {
+			return writtenItems(batch, requests), err
+		}
+
+		requests = result.UnprocessedItems[tableName]
+		if len(requests) == 0 {
+			return batch, nil
+		}
+		if attempt == batchAttempts {
+			return writtenItems(batch, requests), fmt.Errorf("%d items left unprocessed after %d attempts", len(requests), attempt)
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return writtenItems(batch, requests), ctx.Err()
+		}
+		wait *= 2
+	}
+}
+
+// writtenItems returns the items of batch that are not among the pending
+// requests.
+func writtenItems(batch []models.Expense, pending []types.WriteRequest) []models.Expense {
+	left := make(map[string]bool, len(pending))
+	for _, request := range pending {
+		if key, ok := request.PutRequest.Item["expense_id"].(*types.AttributeValueMemberS); ok {
+			left[key.Value] = true
+		}
+	}
+
+	written := make([]models.Expense, 0, len(batch)-len(pending))
+	for _, item := range batch {
+		if !left[item.ExpenseID] {
+			written = append(written, item)
+		}
+	}
+	return written
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"money-telegram-bot/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// throttledBatch answers the first BatchWriteItem call leaving one item
+// unprocessed and fails every call after it.
+type throttledBatch struct {
+	unprocessed string
+	calls       atomic.Int32
+}
+
+func (b *throttledBatch) Do(*http.Request) (*http.Response, error) {
+	status, body := http.StatusOK,
+		fmt.Sprintf(`{"UnprocessedItems":{"%s":[{"PutRequest":{"Item":{"user_id":{"N":"1"},"expense_id":{"S":"%s"}}}}]}}`, tableName, b.unprocessed)
+	if b.calls.Add(1) > 1 {
+		status, body = http.StatusBadRequest, `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"test"}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func TestTrashExpenseItemsReportsPartialWrites(t *testing.T) {
+	fake := &throttledBatch{unprocessed: "1#03"}
+	client, backoff := dynamoClient, batchBackoff
+	dynamoClient = dynamodb.New(dynamodb.Options{
+		Region:           "sa-east-1",
+		Credentials:      aws.AnonymousCredentials{},
+		HTTPClient:       fake,
+		RetryMaxAttempts: 1,
+	})
+	batchBackoff = 0
+	defer func() { dynamoClient, batchBackoff = client, backoff }()
+
+	items := make([]models.Expense, 30)
+	for i := range items {
+		items[i] = models.Expense{UserID: 1, ExpenseID: fmt.Sprintf("1#%02d", i)}
+	}
+
+	now := time.Now()
+	trashed, err := trashExpenseItems(context.Background(), items, now)
+	if err == nil {
+		t.Fatal("expected the retry to fail")
+	}
+	if got := fake.calls.Load(); got != 2 {
+		t.Errorf("made %d calls, want the first batch and one retry", got)
+	}
+	if len(trashed) != 24 {
+		t.Fatalf("trashed %d items, want the 24 the first batch wrote", len(trashed))
+	}
+	for _, item := range trashed {
+		if item.ExpenseID == fake.unprocessed {
+			t.Errorf("unprocessed item %s reported as trashed", item.ExpenseID)
+		}
+		if item.DeletedAt == nil || item.ExpiresAt != now.Add(TrashRetention).Unix() {
+			t.Errorf("item %s not marked as deleted", item.ExpenseID)
+		}
+	}
+}
+
+func TestInstallmentsFirst(t *testing.T) {
+	items := []models.Expense{
+		{ExpenseID: "1#a"},
+		{ExpenseID: "1#a#01", ParentID: "1#a", Installment: 1},
+		{ExpenseID: "1#b"},
+		{ExpenseID: "1#a#02", ParentID: "1#a", Installment: 2},
+	}
+
+	got := installmentsFirst(items)
+	want := []string{"1#a#01", "1#a#02", "1#a", "1#b"}
+	for i, item := range got {
+		if item.ExpenseID != want[i] {
+			t.Errorf("item %d = %s, want %s", i, item.ExpenseID, want[i])
+		}
+	}
+}
@@ -2,10 +2,12 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"money-telegram-bot/internal/models"
 	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -17,32 +19,24 @@ import (
 var dynamoClient *dynamodb.Client
 var tableName = os.Getenv("TABLE_NAME")
 
+// ErrExpenseExists is returned by SaveExpense when another expense already
+// has the key of the new one.
+var ErrExpenseExists = errors.New("expense already exists")
+
 func InitDB(ctx context.Context) error {
 	cfg, err := config.LoadDefaultConfig(ctx)
 	if err != nil {
-		log.Printf("[ERROR] Failed to load AWS config: %v", err)
+		slog.ErrorContext(ctx, "Failed to load AWS config", "error", err)
 		return err
 	}
 
-	dynamoClient = dynamodb.NewFromConfig(cfg)
-	log.Println("[INFO] DynamoDB client initialized successfully")
+	dynamoClient = dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, recordLatency)
+	})
+	slog.InfoContext(ctx, "DynamoDB client initialized successfully")
 	return nil
 }
 
-func getNextSeqID(ctx context.Context, userID int64) (int, error) {
-	expenses, err := GetUserExpenses(ctx, userID)
-	if err != nil {
-		return 0, err
-	}
-	maxID := 0
-	for _, e := range expenses {
-		if e.SeqID > maxID {
-			maxID = e.SeqID
-		}
-	}
-	return maxID + 1, nil
-}
-
 func SaveExpense(ctx context.Context, expense *models.Expense) error {
 	if dynamoClient == nil {
 		return fmt.Errorf("DynamoDB client is not initialized")
@@ -50,63 +44,164 @@ func SaveExpense(ctx context.Context, expense *models.Expense) error {
 
 	nextSeq, err := getNextSeqID(ctx, expense.UserID)
 	if err != nil {
-		log.Printf("[ERROR] Failed to get next seq_id: %v", err)
+		slog.ErrorContext(ctx, "Failed to get next seq_id", "error", err)
 		return err
 	}
 	expense.SeqID = nextSeq
-	expense.ExpenseID = fmt.Sprintf("%d#%s", expense.UserID, expense.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	expense.ExpenseID = expenseKey(expense)
 
 	av, err := attributevalue.MarshalMap(expense)
 	if err != nil {
-		log.Printf("[ERROR] Failed to marshal expense: %v", err)
+		slog.ErrorContext(ctx, "Failed to marshal expense", "error", err)
 		return err
 	}
 
+	// Never overwrite another expense that got the same key.
 	_, err = dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(tableName),
-		Item:      av,
+		TableName:           aws.String(tableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(expense_id)"),
 	})
 
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		slog.ErrorContext(ctx, "Expense key already taken", "expense_id", expense.ExpenseID)
+		return fmt.Errorf("%w: %s", ErrExpenseExists, expense.ExpenseID)
+	}
 	if err != nil {
-		log.Printf("[ERROR] Failed to save expense to DynamoDB: %v", err)
+		slog.ErrorContext(ctx, "Failed to save expense to DynamoDB", "error", err)
 		return err
 	}
 
-	log.Printf(
-		"[INFO] Expense saved successfully | userID=%d | seqID=%d | amount=R$%.2f | category=%s",
-		expense.UserID,
-		expense.SeqID,
-		expense.Amount,
-		expense.Category,
+	slog.InfoContext(ctx, "Expense saved successfully",
+		"user_id", expense.UserID,
+		"seq_id", expense.SeqID,
+		"amount", expense.Amount,
+		"category", expense.Category,
 	)
 
 	return nil
 }
 
+// expenseKey builds the sort key of a new expense: the user and the creation
+// time to the nanosecond, so expenses dated on the same day (or second) never
+// share a key.
+func expenseKey(expense *models.Expense) string {
+	return fmt.Sprintf("%d#%s", expense.UserID, expense.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000000Z07:00"))
+}
+
+// UpdateExpense overwrites an existing expense, keeping its keys and SeqID.
+// Changes to an installment purchase are copied to its installments.
+func UpdateExpense(ctx context.Context, expense *models.Expense) error {
+	if dynamoClient == nil {
+		return fmt.Errorf("DynamoDB client is not initialized")
+	}
+	if expense.ExpenseID == "" {
+		return fmt.Errorf("expense has no expense_id")
+	}
+
+	var err error
+	if expense.IsInstallmentPurchase() {
+		// A purchase and its installments change together or not at all.
+		var items []models.Expense
+		if items, err = cascadeToInstallments(ctx, expense); err == nil {
+			err = transactExpenseItems(ctx, items, false)
+		}
+	} else {
+		err = putExpenseItem(ctx, expense)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to update expense", "seq_id", expense.SeqID, "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "Expense updated", "user_id", expense.UserID, "seq_id", expense.SeqID)
+	return nil
+}
+
+// GetUserExpenses returns the expenses the user registered, in creation order.
+// Installments of a purchase are not included, only the purchase itself.
 func GetUserExpenses(ctx context.Context, userID int64) ([]models.Expense, error) {
+	items, err := queryExpenseItems(ctx, userID, fmt.Sprintf("%d#", userID), activeItems)
+	if err != nil {
+		return nil, err
+	}
+
+	expenses := make([]models.Expense, 0, len(items))
+	for _, item := range items {
+		if !item.IsInstallment() {
+			expenses = append(expenses, item)
+		}
+	}
+	return expenses, nil
+}
+
+// GetBillableExpenses returns what is actually charged to the user: regular
+// expenses and each installment in the month it falls in, without the
+// installment purchases themselves.
+func GetBillableExpenses(ctx context.Context, userID int64) ([]models.Expense, error) {
+	items, err := queryExpenseItems(ctx, userID, fmt.Sprintf("%d#", userID), activeItems)
+	if err != nil {
+		return nil, err
+	}
+
+	expenses := make([]models.Expense, 0, len(items))
+	for _, item := range items {
+		if !item.IsInstallmentPurchase() {
+			expenses = append(expenses, item)
+		}
+	}
+	return expenses, nil
+}
+
+// GetInstallments returns the installments of a purchase, in order.
+func GetInstallments(ctx context.Context, parent *models.Expense) ([]models.Expense, error) {
+	return queryExpenseItems(ctx, parent.UserID, parent.ExpenseID+"#", activeItems)
+}
+
+// itemState selects expense items by whether they are in the trash.
+type itemState int
+
+const (
+	activeItems  itemState = iota // not deleted
+	deletedItems                  // in the trash
+	allItems
+)
+
+// queryExpenseItems queries the user's items whose sort key starts with prefix.
+// Other user items (payment methods...) share the partition, so only sort keys
+// in the "<user_id>#<timestamp>" format are expenses.
+func queryExpenseItems(ctx context.Context, userID int64, prefix string, state itemState) ([]models.Expense, error) {
 	if dynamoClient == nil {
 		return nil, fmt.Errorf("DynamoDB client not initialized")
 	}
 
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(tableName),
-		KeyConditionExpression: aws.String("user_id = :uid"),
+		KeyConditionExpression: aws.String("user_id = :uid AND begins_with(expense_id, :prefix)"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":uid": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", userID)},
+			":uid":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", userID)},
+			":prefix": &types.AttributeValueMemberS{Value: prefix},
 		},
 		ScanIndexForward: aws.Bool(true),
 	}
+	switch state {
+	case activeItems:
+		input.FilterExpression = aws.String("attribute_not_exists(deleted_at)")
+	case deletedItems:
+		input.FilterExpression = aws.String("attribute_exists(deleted_at)")
+	}
 
 	result, err := dynamoClient.Query(ctx, input)
 	if err != nil {
-		log.Printf("[ERROR] Failed to query expenses: %v", err)
+		slog.ErrorContext(ctx, "Failed to query expenses", "error", err)
 		return nil, err
 	}
 
 	var expenses []models.Expense
 	err = attributevalue.UnmarshalListOfMaps(result.Items, &expenses)
 	if err != nil {
-		log.Printf("[ERROR] Failed to unmarshal expenses: %v", err)
+		slog.ErrorContext(ctx, "Failed to unmarshal expenses", "error", err)
 		return nil, err
 	}
 
@@ -134,50 +229,73 @@ func GetTotalExpenses(ctx context.Context, userID int64) (int, error) {
 	return len(expenses), nil
 }
 
-func DeleteExpenseBySeqID(ctx context.Context, userID int64, seqID int) error {
-	expenses, err := GetUserExpenses(ctx, userID)
+// HasExpenses reports whether the user has any expense, counting the ones in
+// the trash, which can still be restored.
+func HasExpenses(ctx context.Context, userID int64) (bool, error) {
+	items, err := queryExpenseItems(ctx, userID, fmt.Sprintf("%d#", userID), allItems)
 	if err != nil {
-		return err
+		return false, err
 	}
-	for _, expense := range expenses {
-		if expense.SeqID == seqID {
-			_, err := dynamoClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-				TableName: aws.String(tableName),
-				Key: map[string]types.AttributeValue{
-					"user_id":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", expense.UserID)},
-					"expense_id": &types.AttributeValueMemberS{Value: expense.ExpenseID},
-				},
-			})
-			if err != nil {
-				log.Printf("[ERROR] Failed to delete expense seqID=%d: %v", seqID, err)
-				return err
-			}
-			log.Printf("[INFO] Expense deleted | userID=%d | seqID=%d", userID, seqID)
-			return nil
+	now := time.Now().Unix()
+	for _, item := range items {
+		if !item.IsDeleted() || item.ExpiresAt > now {
+			return true, nil
 		}
 	}
-	return fmt.Errorf("nenhum gasto encontrado com ID %d", seqID)
+	return false, nil
 }
 
-func DeleteAllExpenses(ctx context.Context, userID int64) error {
-	expenses, err := GetUserExpenses(ctx, userID)
+// DeleteExpenseBySeqID moves an expense to the trash. Deleting an installment
+// purchase deletes all of its installments too. It returns the deleted items,
+// also on failure, when they are the ones it got to.
+func DeleteExpenseBySeqID(ctx context.Context, userID int64, seqID int) ([]models.Expense, error) {
+	expense, err := GetExpenseBySeqID(ctx, userID, seqID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, expense := range expenses {
-		_, err := dynamoClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-			TableName: aws.String(tableName),
-			Key: map[string]types.AttributeValue{
-				"user_id":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", expense.UserID)},
-				"expense_id": &types.AttributeValueMemberS{Value: expense.ExpenseID},
-			},
-		})
+
+	items := []models.Expense{*expense}
+	if expense.IsInstallmentPurchase() {
+		installments, err := GetInstallments(ctx, expense)
 		if err != nil {
-			log.Printf("[ERROR] Failed to delete expense: %v", err)
-			return err
+			return nil, err
 		}
+		items = append(items, installments...)
 	}
-	log.Printf("[INFO] All expenses deleted | userID=%d | count=%d", userID, len(expenses))
-	return nil
+
+	deleted, err := trashExpenseItems(ctx, installmentsFirst(items), time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to delete expense", "seq_id", seqID, "deleted", len(deleted), "error", err)
+		return deleted, err
+	}
+	slog.InfoContext(ctx, "Expense deleted", "user_id", userID, "seq_id", seqID)
+	return deleted, nil
+}
+
+// DeleteAllExpenses moves every expense of the user to the trash, installments
+// included, and returns the deleted items. On failure it returns the items it
+// did delete along with the error.
+func DeleteAllExpenses(ctx context.Context, userID int64) ([]models.Expense, error) {
+	items, err := queryExpenseItems(ctx, userID, fmt.Sprintf("%d#", userID), activeItems)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := trashExpenseItems(ctx, installmentsFirst(items), time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to delete expenses", "user_id", userID, "deleted", len(deleted), "total", len(items), "error", err)
+		return deleted, err
+	}
+	slog.InfoContext(ctx, "All expenses deleted", "user_id", userID, "count", len(deleted))
+	return deleted, nil
 }
 
+func deleteExpenseItem(ctx context.Context, expense *models.Expense) error {
+	_, err := dynamoClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]types.AttributeValue{
+			"user_id":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", expense.UserID)},
+			"expense_id": &types.AttributeValueMemberS{Value: expense.ExpenseID},
+		},
+	})
+	return err
+}
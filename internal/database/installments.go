@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"money-telegram-bot/internal/billing"
+	"money-telegram-bot/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// SaveInstallmentPurchase saves a purchase split in count installments: the
+// purchase itself, with the full amount and a SeqID, and one installment per
+// month starting at the purchase date. Rounding cents go to the last
+// installment. Everything is written in one transaction, so a failure leaves
+// nothing behind for a retry to duplicate.
+func SaveInstallmentPurchase(ctx context.Context, purchase *models.Expense, count int) error {
+	if dynamoClient == nil {
+		return fmt.Errorf("DynamoDB client is not initialized")
+	}
+	if count < 2 || count >= maxTransactItems {
+		return fmt.Errorf("an installment purchase needs 2 to %d installments, got %d", maxTransactItems-1, count)
+	}
+
+	nextSeq, err := getNextSeqID(ctx, purchase.UserID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get next seq_id", "error", err)
+		return err
+	}
+	purchase.SeqID = nextSeq
+	purchase.ExpenseID = expenseKey(purchase)
+	purchase.InstallmentCount = count
+
+	items := append([]models.Expense{*purchase}, splitInstallments(purchase)...)
+	if err := transactExpenseItems(ctx, items, true); err != nil {
+		slog.ErrorContext(ctx, "Failed to save installment purchase", "count", count, "seq_id", purchase.SeqID, "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "Installments saved", "user_id", purchase.UserID, "seq_id", purchase.SeqID, "count", count)
+	return nil
+}
+
+// splitInstallments builds the installments of a saved purchase, one per
+// month from the purchase date on. Purchases late in the month are billed on
+// the last day of shorter months, so every month gets exactly one.
+func splitInstallments(purchase *models.Expense) []models.Expense {
+	count := purchase.InstallmentCount
+	installmentAmount := math.Round(purchase.Amount/float64(count)*100) / 100
+	installmentOriginal := math.Round(purchase.OriginalAmount/float64(count)*100) / 100
+
+	installments := make([]models.Expense, 0, count)
+	for i := 1; i <= count; i++ {
+		installment := *purchase
+		installment.SeqID = 0
+		installment.ParentID = purchase.ExpenseID
+		installment.Installment = i
+		installment.ExpenseID = fmt.Sprintf("%s#%02d", purchase.ExpenseID, i)
+		installment.CreatedAt = billing.AddMonths(purchase.CreatedAt, i-1)
+		installment.Amount = installmentAmount
+		installment.OriginalAmount = installmentOriginal
+		if i == count {
+			installment.Amount = math.Round((purchase.Amount-installmentAmount*float64(count-1))*100) / 100
+			installment.OriginalAmount = math.Round((purchase.OriginalAmount-installmentOriginal*float64(count-1))*100) / 100
+		}
+		installments = append(installments, installment)
+	}
+	return installments
+}
+
+// cascadeToInstallments copies the descriptive fields of a purchase to its
+// installments, returning the purchase followed by the updated installments.
+func cascadeToInstallments(ctx context.Context, purchase *models.Expense) ([]models.Expense, error) {
+	installments, err := GetInstallments(ctx, purchase)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []models.Expense{*purchase}
+	for _, installment := range installments {
+		installment.Category = purchase.Category
+		installment.Method = purchase.Method
+		installment.Description = purchase.Description
+		items = append(items, installment)
+	}
+	return items, nil
+}
+
+// maxTransactItems is the most items a TransactWriteItems call takes.
+const maxTransactItems = 100
+
+// transactExpenseItems writes items all at once or not at all. With create
+// set, the first item must not exist yet, and ErrExpenseExists is returned
+// when it does.
+func transactExpenseItems(ctx context.Context, items []models.Expense, create bool) error {
+	if len(items) > maxTransactItems {
+		return fmt.Errorf("%d items do not fit in a transaction", len(items))
+	}
+
+	writes := make([]types.TransactWriteItem, len(items))
+	for i := range items {
+		av, err := attributevalue.MarshalMap(&items[i])
+		if err != nil {
+			return err
+		}
+		writes[i] = types.TransactWriteItem{Put: &types.Put{TableName: aws.String(tableName), Item: av}}
+	}
+	if create {
+		// Never overwrite another expense that got the same key.
+		writes[0].Put.ConditionExpression = aws.String("attribute_not_exists(expense_id)")
+	}
+
+	_, err := dynamoClient.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: writes})
+	var canceled *types.TransactionCanceledException
+	if create && errors.As(err, &canceled) && len(canceled.CancellationReasons) > 0 &&
+		aws.ToString(canceled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+		return fmt.Errorf("%w: %s", ErrExpenseExists, items[0].ExpenseID)
+	}
+	return err
+}
+
+func putExpenseItem(ctx context.Context, expense *models.Expense) error {
+	av, err := attributevalue.MarshalMap(expense)
+	if err != nil {
+		return err
+	}
+
+	_, err = dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item:      av,
+	})
+	return err
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"money-telegram-bot/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// fakeDynamo answers DynamoDB calls by operation name, recording each call
+// and its request body.
+type fakeDynamo struct {
+	mu        sync.Mutex
+	calls     []string
+	bodies    map[string][]byte
+	responses map[string]string // operation -> JSON body; "!" prefix for an error
+}
+
+func (f *fakeDynamo) Do(req *http.Request) (*http.Response, error) {
+	operation := strings.TrimPrefix(req.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+	body, _ := io.ReadAll(req.Body)
+
+	f.mu.Lock()
+	f.calls = append(f.calls, operation)
+	if f.bodies == nil {
+		f.bodies = make(map[string][]byte)
+	}
+	f.bodies[operation] = body
+	response, ok := f.responses[operation]
+	f.mu.Unlock()
+
+	status := http.StatusOK
+	if !ok {
+		response = `{}`
+	}
+	if errorBody, failed := strings.CutPrefix(response, "!"); failed {
+		status, response = http.StatusBadRequest, errorBody
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(strings.NewReader(response)),
+	}, nil
+}
+
+func useFakeDynamo(t *testing.T, fake *fakeDynamo) {
+	t.Helper()
+	client := dynamoClient
+	dynamoClient = dynamodb.New(dynamodb.Options{
+		Region:           "sa-east-1",
+		Credentials:      aws.AnonymousCredentials{},
+		HTTPClient:       fake,
+		RetryMaxAttempts: 1,
+	})
+	t.Cleanup(func() { dynamoClient = client })
+}
+
+func TestSaveInstallmentPurchaseWritesAllOrNothing(t *testing.T) {
+	const canceled = `!{"__type":"com.amazonaws.dynamodb.v20120810#TransactionCanceledException","message":"canceled",` +
+		`"CancellationReasons":[{"Code":"%s"},{"Code":"None"},{"Code":"None"},{"Code":"None"}]}`
+	tests := []struct {
+		name      string
+		transact  string
+		wantErr   bool
+		wantExist bool
+	}{
+		{"saved", `{}`, false, false},
+		{"throttled halfway", strings.Replace(canceled, "%s", "ThrottlingError", 1), true, false},
+		{"key taken", strings.Replace(canceled, "%s", "ConditionalCheckFailed", 1), true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeDynamo{responses: map[string]string{
+				"UpdateItem":         `{"Attributes":{"seq_id":{"N":"7"}}}`,
+				"TransactWriteItems": tt.transact,
+			}}
+			useFakeDynamo(t, fake)
+
+			purchase := &models.Expense{UserID: 1, Amount: 100, CreatedAt: time.Date(2025, time.January, 31, 12, 0, 0, 0, time.UTC)}
+			err := SaveInstallmentPurchase(context.Background(), purchase, 3)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrExpenseExists) != tt.wantExist {
+				t.Errorf("err = %v, want ErrExpenseExists %v", err, tt.wantExist)
+			}
+
+			// The seq counter and one transaction: no item is written on its
+			// own, so a failure leaves nothing behind.
+			if want := []string{"UpdateItem", "TransactWriteItems"}; strings.Join(fake.calls, ",") != strings.Join(want, ",") {
+				t.Errorf("calls = %v, want %v", fake.calls, want)
+			}
+			var input struct {
+				TransactItems []struct {
+					Put struct {
+						Item                map[string]map[string]any
+						ConditionExpression string
+					}
+				}
+			}
+			if err := json.Unmarshal(fake.bodies["TransactWriteItems"], &input); err != nil {
+				t.Fatal(err)
+			}
+			if len(input.TransactItems) != 4 {
+				t.Fatalf("transaction has %d items, want the purchase and 3 installments", len(input.TransactItems))
+			}
+			if input.TransactItems[0].Put.ConditionExpression == "" {
+				t.Error("the purchase may overwrite another expense")
+			}
+			if got := input.TransactItems[3].Put.Item["expense_id"]["S"]; got != purchase.ExpenseID+"#03" {
+				t.Errorf("last item = %v, want the third installment", got)
+			}
+		})
+	}
+}
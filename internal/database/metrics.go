@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"money-telegram-bot/internal/metrics"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// recordLatency adds a middleware timing every DynamoDB call, retries
+// included, for the storage latency metrics. A failed condition is how
+// conditional writes (deduplication, unique keys, rate limits) say no, so it
+// is not counted as a storage error, not even inside a transaction.
+func recordLatency(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("RecordLatency",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			start := time.Now()
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			failure := err
+			if conditionFailed(err) {
+				failure = nil
+			}
+			metrics.ObserveStorage(ctx, awsmiddleware.GetOperationName(ctx), time.Since(start), failure)
+			return out, metadata, err
+		},
+	), middleware.After)
+}
+
+// conditionFailed reports whether err is a write turned down by its
+// condition, alone or in a transaction.
+func conditionFailed(err error) bool {
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return true
+	}
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) || len(canceled.CancellationReasons) == 0 {
+		return false
+	}
+	for _, reason := range canceled.CancellationReasons {
+		if code := aws.ToString(reason.Code); code != "None" && code != "ConditionalCheckFailed" {
+			return false
+		}
+	}
+	return true
+}
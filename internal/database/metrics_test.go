@@ -0,0 +1,88 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"money-telegram-bot/internal/metrics"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// dynamoError answers every DynamoDB call with the given error type.
+type dynamoError string
+
+func (e dynamoError) Do(*http.Request) (*http.Response, error) {
+	body := `{"__type":"com.amazonaws.dynamodb.v20120810#` + string(e) + `","message":"test"}`
+	return &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func storageErrorCount(t *testing.T, operation string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := metrics.Default.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	prefix := `moneybot_dynamodb_errors_total{operation="` + operation + `"} `
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, prefix); ok {
+			return value
+		}
+	}
+	return "0"
+}
+
+func TestRecordLatencyIgnoresFailedConditions(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		err       dynamoError
+		counted   bool
+	}{
+		{"failed condition", "PutItem", "ConditionalCheckFailedException", false},
+		{"missing table", "GetItem", "ResourceNotFoundException", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := dynamodb.New(dynamodb.Options{
+				Region:           "sa-east-1",
+				Credentials:      aws.AnonymousCredentials{},
+				HTTPClient:       tt.err,
+				RetryMaxAttempts: 1,
+				APIOptions:       []func(*middleware.Stack) error{recordLatency},
+			})
+			key := map[string]types.AttributeValue{"user_id": &types.AttributeValueMemberN{Value: "1"}}
+
+			ctx, failed := metrics.Track(context.Background())
+			before := storageErrorCount(t, tt.operation)
+			var err error
+			if tt.operation == "PutItem" {
+				_, err = client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("t"), Item: key})
+			} else {
+				_, err = client.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String("t"), Key: key})
+			}
+			if err == nil {
+				t.Fatal("expected the call to fail")
+			}
+
+			if counted := storageErrorCount(t, tt.operation) != before; counted != tt.counted {
+				t.Errorf("error counted = %v, want %v", counted, tt.counted)
+			}
+			if failed() != tt.counted {
+				t.Errorf("update failed = %v, want %v", failed(), tt.counted)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"money-telegram-bot/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const paymentMethodPrefix = "method#"
+
+func paymentMethodKey(name string) string {
+	return paymentMethodPrefix + strings.ToLower(name)
+}
+
+// SavePaymentMethod creates or replaces a payment method of the user.
+func SavePaymentMethod(ctx context.Context, method *models.PaymentMethod) error {
+	if dynamoClient == nil {
+		return fmt.Errorf("DynamoDB client is not initialized")
+	}
+
+	method.ItemID = paymentMethodKey(method.Name)
+
+	av, err := attributevalue.MarshalMap(method)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal payment method", "error", err)
+		return err
+	}
+
+	_, err = dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item:      av,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save payment method to DynamoDB", "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "Payment method saved", "user_id", method.UserID, "name", method.Name, "type", method.Type)
+	return nil
+}
+
+// GetPaymentMethods returns every payment method registered by the user.
+func GetPaymentMethods(ctx context.Context, userID int64) ([]models.PaymentMethod, error) {
+	if dynamoClient == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	result, err := dynamoClient.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		KeyConditionExpression: aws.String("user_id = :uid AND begins_with(expense_id, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":uid":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", userID)},
+			":prefix": &types.AttributeValueMemberS{Value: paymentMethodPrefix},
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to query payment methods", "error", err)
+		return nil, err
+	}
+
+	var methods []models.PaymentMethod
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &methods); err != nil {
+		slog.ErrorContext(ctx, "Failed to unmarshal payment methods", "error", err)
+		return nil, err
+	}
+	return methods, nil
+}
+
+// GetPaymentMethod returns a payment method by name (case-insensitive).
+func GetPaymentMethod(ctx context.Context, userID int64, name string) (*models.PaymentMethod, error) {
+	methods, err := GetPaymentMethods(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range methods {
+		if methods[i].Matches(name) {
+			return &methods[i], nil
+		}
+	}
+	return nil, fmt.Errorf("nenhum método encontrado com o nome %s", name)
+}
+
+// DeletePaymentMethod removes a payment method. Expenses paid with it are kept.
+func DeletePaymentMethod(ctx context.Context, userID int64, name string) error {
+	if dynamoClient == nil {
+		return fmt.Errorf("DynamoDB client is not initialized")
+	}
+
+	_, err := dynamoClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]types.AttributeValue{
+			"user_id":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", userID)},
+			"expense_id": &types.AttributeValueMemberS{Value: paymentMethodKey(name)},
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to delete payment method", "name", name, "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "Payment method deleted", "user_id", userID, "name", name)
+	return nil
+}
+
+// GetAllCreditCards scans the table for the credit cards of every user. It is
+// meant for the daily invoice reminder job, not for request handling.
+func GetAllCreditCards(ctx context.Context) ([]models.PaymentMethod, error) {
+	if dynamoClient == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(tableName),
+		FilterExpression: aws.String("begins_with(expense_id, :prefix) AND #type = :credit"),
+		ExpressionAttributeNames: map[string]string{
+			"#type": "type",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":prefix": &types.AttributeValueMemberS{Value: paymentMethodPrefix},
+			":credit": &types.AttributeValueMemberS{Value: models.MethodCredit},
+		},
+	}
+
+	var cards []models.PaymentMethod
+	paginator := dynamodb.NewScanPaginator(dynamoClient, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to scan credit cards", "error", err)
+			return nil, err
+		}
+		var batch []models.PaymentMethod
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			slog.ErrorContext(ctx, "Failed to unmarshal credit cards", "error", err)
+			return nil, err
+		}
+		cards = append(cards, batch...)
+	}
+	return cards, nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const rateLimitPrefix = "ratelimit#"
+
+// ErrRateBucketChanged is returned by SaveRateBucket when another request
+// updated the bucket since it was read.
+var ErrRateBucketChanged = errors.New("rate limit bucket changed concurrently")
+
+// RateBucket is the stored state of a rate limit token bucket. UpdatedAt is
+// in Unix milliseconds and doubles as the version for optimistic locking.
+type RateBucket struct {
+	UserID    int64   `dynamodbav:"user_id"`
+	ItemID    string  `dynamodbav:"expense_id"`
+	Tokens    float64 `dynamodbav:"tokens"`
+	UpdatedAt int64   `dynamodbav:"updated_at"`
+	Notified  bool    `dynamodbav:"notified"`
+	ExpiresAt int64   `dynamodbav:"expires_at"`
+}
+
+func rateBucketKey(userID int64, name string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"user_id":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", userID)},
+		"expense_id": &types.AttributeValueMemberS{Value: rateLimitPrefix + name},
+	}
+}
+
+// GetRateBucket returns the user's bucket called name, or nil if there is none.
+func GetRateBucket(ctx context.Context, userID int64, name string) (*RateBucket, error) {
+	if dynamoClient == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	result, err := dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(tableName),
+		Key:            rateBucketKey(userID, name),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get rate limit bucket", "bucket", name, "error", err)
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var bucket RateBucket
+	if err := attributevalue.UnmarshalMap(result.Item, &bucket); err != nil {
+		slog.ErrorContext(ctx, "Failed to unmarshal rate limit bucket", "error", err)
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+// SaveRateBucket stores the bucket called name if it still has the
+// previousUpdatedAt it was read with (0 when it did not exist). Otherwise it
+// returns ErrRateBucketChanged.
+func SaveRateBucket(ctx context.Context, bucket *RateBucket, name string, previousUpdatedAt int64) error {
+	if dynamoClient == nil {
+		return fmt.Errorf("DynamoDB client is not initialized")
+	}
+
+	bucket.ItemID = rateLimitPrefix + name
+	av, err := attributevalue.MarshalMap(bucket)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal rate limit bucket", "error", err)
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(tableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(expense_id)"),
+	}
+	if previousUpdatedAt != 0 {
+		input.ConditionExpression = aws.String("updated_at = :previous")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":previous": &types.AttributeValueMemberN{Value: strconv.FormatInt(previousUpdatedAt, 10)},
+		}
+	}
+
+	_, err = dynamoClient.PutItem(ctx, input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrRateBucketChanged
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save rate limit bucket", "bucket", name, "error", err)
+		return err
+	}
+	return nil
+}
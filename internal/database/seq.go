@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// seqCounterID is the sort key of the item holding the last SeqID given to
+// the user's expenses.
+const seqCounterID = "seq"
+
+// getNextSeqID allocates the SeqID of the next expense. The counter is
+// incremented atomically, so two expenses saved at the same time (say from a
+// group and a private chat) never get the same SeqID.
+func getNextSeqID(ctx context.Context, userID int64) (int, error) {
+	seq, err := incrementSeqCounter(ctx, userID)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if !errors.As(err, &conditionFailed) {
+		return seq, err
+	}
+
+	// No counter yet: start it from the expenses saved before it existed.
+	if err := createSeqCounter(ctx, userID); err != nil {
+		return 0, err
+	}
+	return incrementSeqCounter(ctx, userID)
+}
+
+func incrementSeqCounter(ctx context.Context, userID int64) (int, error) {
+	if dynamoClient == nil {
+		return 0, fmt.Errorf("DynamoDB client is not initialized")
+	}
+
+	result, err := dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]types.AttributeValue{
+			"user_id":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", userID)},
+			"expense_id": &types.AttributeValueMemberS{Value: seqCounterID},
+		},
+		UpdateExpression:    aws.String("ADD seq_id :one"),
+		ConditionExpression: aws.String("attribute_exists(expense_id)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	value, ok := result.Attributes["seq_id"].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, fmt.Errorf("seq counter of user %d has no seq_id", userID)
+	}
+	return strconv.Atoi(value.Value)
+}
+
+// createSeqCounter starts the counter at the highest SeqID in use. Expenses
+// in the trash keep theirs, so they can be restored with it. Losing the race
+// to create the counter is fine: the winner started it from the same items.
+func createSeqCounter(ctx context.Context, userID int64) error {
+	expenses, err := queryExpenseItems(ctx, userID, fmt.Sprintf("%d#", userID), allItems)
+	if err != nil {
+		return err
+	}
+	last := 0
+	for _, e := range expenses {
+		last = max(last, e.SeqID)
+	}
+
+	_, err = dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item: map[string]types.AttributeValue{
+			"user_id":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", userID)},
+			"expense_id": &types.AttributeValueMemberS{Value: seqCounterID},
+			"seq_id":     &types.AttributeValueMemberN{Value: strconv.Itoa(last)},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(expense_id)"),
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionFailed) {
+		slog.ErrorContext(ctx, "Failed to create seq counter", "user_id", userID, "error", err)
+		return err
+	}
+	return nil
+}
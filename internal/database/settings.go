@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"money-telegram-bot/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GetUserSettings returns the settings of the user. Users who never changed a
+// setting get empty settings, not an error.
+func GetUserSettings(ctx context.Context, userID int64) (*models.UserSettings, error) {
+	if dynamoClient == nil {
+		return nil, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	result, err := dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]types.AttributeValue{
+			"user_id":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", userID)},
+			"expense_id": &types.AttributeValueMemberS{Value: models.SettingsItemID},
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get user settings", "error", err)
+		return nil, err
+	}
+
+	settings := &models.UserSettings{UserID: userID, ItemID: models.SettingsItemID}
+	if result.Item == nil {
+		return settings, nil
+	}
+	if err := attributevalue.UnmarshalMap(result.Item, settings); err != nil {
+		slog.ErrorContext(ctx, "Failed to unmarshal user settings", "error", err)
+		return nil, err
+	}
+	return settings, nil
+}
+
+// SaveUserSettings creates or replaces the settings of the user.
+func SaveUserSettings(ctx context.Context, settings *models.UserSettings) error {
+	if dynamoClient == nil {
+		return fmt.Errorf("DynamoDB client is not initialized")
+	}
+
+	settings.ItemID = models.SettingsItemID
+
+	av, err := attributevalue.MarshalMap(settings)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal user settings", "error", err)
+		return err
+	}
+
+	_, err = dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item:      av,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save user settings to DynamoDB", "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "User settings saved", "user_id", settings.UserID)
+	return nil
+}
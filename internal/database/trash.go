@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"money-telegram-bot/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TrashRetention is how long a deleted expense can be restored before the
+// table's TTL removes it.
+const TrashRetention = 30 * 24 * time.Hour
+
+// ErrNotInTrash is returned by RestoreExpenseBySeqID when the expense is not
+// in the trash, either because it was restored or because it expired.
+var ErrNotInTrash = errors.New("expense not in trash")
+
+// trashExpenseItems marks items as deleted at now and writes them in batches,
+// in order. They stay in the table, hidden from queries, until expires_at. It
+// returns the items that went to the trash, even when it fails halfway.
+func trashExpenseItems(ctx context.Context, items []models.Expense, now time.Time) ([]models.Expense, error) {
+	deletedAt := now.UTC()
+	for i := range items {
+		items[i].DeletedAt = &deletedAt
+		items[i].ExpiresAt = now.Add(TrashRetention).Unix()
+	}
+	return putExpenseItems(ctx, items)
+}
+
+// installmentsFirst orders items so that installments come before the
+// purchases, for trashing: a failure halfway never leaves a purchase hidden
+// with its installments still billed.
+func installmentsFirst(items []models.Expense) []models.Expense {
+	ordered := make([]models.Expense, 0, len(items))
+	for _, item := range items {
+		if item.IsInstallment() {
+			ordered = append(ordered, item)
+		}
+	}
+	for _, item := range items {
+		if !item.IsInstallment() {
+			ordered = append(ordered, item)
+		}
+	}
+	return ordered
+}
+
+// GetDeletedExpenses returns the expenses of the user in the trash, most
+// recently deleted first. Installments are not included, only their purchase.
+func GetDeletedExpenses(ctx context.Context, userID int64) ([]models.Expense, error) {
+	items, err := queryExpenseItems(ctx, userID, fmt.Sprintf("%d#", userID), deletedItems)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	expenses := make([]models.Expense, 0, len(items))
+	for _, item := range items {
+		// Expired items may linger until TTL gets to them.
+		if !item.IsInstallment() && item.ExpiresAt > now {
+			expenses = append(expenses, item)
+		}
+	}
+	sort.SliceStable(expenses, func(i, j int) bool {
+		return expenses[i].DeletedAt.After(*expenses[j].DeletedAt)
+	})
+	return expenses, nil
+}
+
+// RestoreExpenseBySeqID takes an expense out of the trash, with its
+// installments, keeping its keys and SeqID.
+func RestoreExpenseBySeqID(ctx context.Context, userID int64, seqID int) (*models.Expense, error) {
+	deleted, err := GetDeletedExpenses(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range deleted {
+		if deleted[i].SeqID != seqID {
+			continue
+		}
+		restored, err := RestoreExpenses(ctx, userID, []string{deleted[i].ExpenseID})
+		if err != nil {
+			return nil, err
+		}
+		if len(restored) == 0 {
+			return nil, ErrNotInTrash
+		}
+		slog.InfoContext(ctx, "Expense restored", "user_id", userID, "seq_id", seqID)
+		return &restored[0], nil
+	}
+	return nil, ErrNotInTrash
+}
+
+// RestoreExpenses takes the expenses of expenseIDs out of the trash, with
+// their installments, keeping their keys and SeqIDs. Expenses no longer in the
+// trash are skipped. It returns the restored items, purchases first, also on
+// failure, when they are the ones it got to.
+func RestoreExpenses(ctx context.Context, userID int64, expenseIDs []string) ([]models.Expense, error) {
+	items, err := queryExpenseItems(ctx, userID, fmt.Sprintf("%d#", userID), deletedItems)
+	if err != nil {
+		return nil, err
+	}
+
+	// The purchases go first, so a failure never leaves installments billed
+	// without them.
+	restore := withInstallments(items, expenseIDs)
+	for i := range restore {
+		restore[i].DeletedAt, restore[i].ExpiresAt = nil, 0
+	}
+	restored, err := putExpenseItems(ctx, restore)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to restore expenses", "user_id", userID, "restored", len(restored), "error", err)
+		return restored, err
+	}
+	return restored, nil
+}
+
+// TrashExpenses puts the expenses of expenseIDs back in the trash, with their
+// installments, undoing a restore. Expenses no longer active are skipped. It
+// returns the trashed items, also on failure, when they are the ones it got to.
+func TrashExpenses(ctx context.Context, userID int64, expenseIDs []string) ([]models.Expense, error) {
+	items, err := queryExpenseItems(ctx, userID, fmt.Sprintf("%d#", userID), activeItems)
+	if err != nil {
+		return nil, err
+	}
+
+	trashed, err := trashExpenseItems(ctx, installmentsFirst(withInstallments(items, expenseIDs)), time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to delete expenses", "user_id", userID, "deleted", len(trashed), "error", err)
+		return trashed, err
+	}
+	return trashed, nil
+}
+
+// withInstallments picks from items the expenses of expenseIDs and the
+// installments of those that are purchases, purchases first.
+func withInstallments(items []models.Expense, expenseIDs []string) []models.Expense {
+	wanted := make(map[string]bool, len(expenseIDs))
+	for _, id := range expenseIDs {
+		wanted[id] = true
+	}
+
+	var expenses, installments []models.Expense
+	for _, item := range items {
+		switch {
+		case wanted[item.ExpenseID]:
+			expenses = append(expenses, item)
+		case item.IsInstallment() && wanted[item.ParentID]:
+			installments = append(installments, item)
+		}
+	}
+	return append(expenses, installments...)
+}
+
+// PurgeDeletedExpenses scans the table for expenses whose time in the trash
+// is over and deletes them, returning how many. DynamoDB's TTL does this on
+// its own; the job is for tables without TTL, such as DynamoDB Local.
+func PurgeDeletedExpenses(ctx context.Context, now time.Time) (int, error) {
+	if dynamoClient == nil {
+		return 0, fmt.Errorf("DynamoDB client not initialized")
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(tableName),
+		FilterExpression: aws.String("attribute_exists(deleted_at) AND expires_at < :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+		},
+	}
+
+	purged := 0
+	paginator := dynamodb.NewScanPaginator(dynamoClient, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to scan deleted expenses", "error", err)
+			return purged, err
+		}
+		var batch []models.Expense
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			slog.ErrorContext(ctx, "Failed to unmarshal deleted expenses", "error", err)
+			return purged, err
+		}
+		for _, expense := range batch {
+			if err := deleteExpenseItem(ctx, &expense); err != nil {
+				slog.ErrorContext(ctx, "Failed to purge expense", "expense_id", expense.ExpenseID, "error", err)
+				return purged, err
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"testing"
+
+	"money-telegram-bot/internal/models"
+)
+
+func TestWithInstallments(t *testing.T) {
+	items := []models.Expense{
+		{ExpenseID: "1#a"},
+		{ExpenseID: "1#a#01", ParentID: "1#a", Installment: 1},
+		{ExpenseID: "1#a#02", ParentID: "1#a", Installment: 2},
+		{ExpenseID: "1#b"},
+		{ExpenseID: "1#c#01", ParentID: "1#c", Installment: 1},
+		{ExpenseID: "1#d"},
+	}
+
+	got := withInstallments(items, []string{"1#a", "1#d", "1#gone"})
+	want := []string{"1#a", "1#d", "1#a#01", "1#a#02"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d items, want %v", len(got), want)
+	}
+	for i, item := range got {
+		if item.ExpenseID != want[i] {
+			t.Errorf("item %d = %s, want %s", i, item.ExpenseID, want[i])
+		}
+	}
+}
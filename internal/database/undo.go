@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"money-telegram-bot/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrNothingToUndo is returned by TakeUndoEntry when the user has no action to
+// undo, or not the one asked for.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// SaveUndoEntry records the last action of the user, replacing the previous
+// one. The item expires through the table's TTL on expires_at.
+func SaveUndoEntry(ctx context.Context, entry *models.UndoEntry) error {
+	if dynamoClient == nil {
+		return fmt.Errorf("DynamoDB client is not initialized")
+	}
+
+	entry.ItemID = models.UndoItemID
+	av, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal undo entry", "error", err)
+		return err
+	}
+
+	_, err = dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item:      av,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save undo entry", "error", err)
+		return err
+	}
+	return nil
+}
+
+// TakeUndoEntry removes and returns the undo entry of the user, so an action
+// is undone once even when /desfazer and the button race. With an actionID,
+// only the entry of that action is taken. Expired entries TTL has not removed
+// yet are not taken.
+func TakeUndoEntry(ctx context.Context, userID int64, actionID string) (*models.UndoEntry, error) {
+	if dynamoClient == nil {
+		return nil, fmt.Errorf("DynamoDB client is not initialized")
+	}
+
+	condition := "attribute_exists(expense_id) AND expires_at > :now"
+	values := map[string]types.AttributeValue{
+		":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+	}
+	if actionID != "" {
+		condition += " AND action_id = :action"
+		values[":action"] = &types.AttributeValueMemberS{Value: actionID}
+	}
+
+	result, err := dynamoClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]types.AttributeValue{
+			"user_id":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", userID)},
+			"expense_id": &types.AttributeValueMemberS{Value: models.UndoItemID},
+		},
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllOld,
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, ErrNothingToUndo
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to take undo entry", "error", err)
+		return nil, err
+	}
+
+	var entry models.UndoEntry
+	if err := attributevalue.UnmarshalMap(result.Attributes, &entry); err != nil {
+		slog.ErrorContext(ctx, "Failed to unmarshal undo entry", "error", err)
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// RevertUndoEntry undoes the action of entry. The expenses it created are
+// deleted for good with their installments, the ones it put in the trash are
+// restored and the ones it restored go back to the trash, and the ones it
+// changed are put back as they were, keys and SeqID included.
+func RevertUndoEntry(ctx context.Context, entry *models.UndoEntry) error {
+	for _, expenseID := range entry.Created {
+		created := models.Expense{UserID: entry.UserID, ExpenseID: expenseID}
+		installments, err := queryExpenseItems(ctx, entry.UserID, expenseID+"#", allItems)
+		if err != nil {
+			return err
+		}
+		for _, installment := range installments {
+			if err := deleteExpenseItem(ctx, &installment); err != nil {
+				slog.ErrorContext(ctx, "Failed to delete installment", "expense_id", installment.ExpenseID, "error", err)
+				return err
+			}
+		}
+		if err := deleteExpenseItem(ctx, &created); err != nil {
+			slog.ErrorContext(ctx, "Failed to delete expense", "expense_id", expenseID, "error", err)
+			return err
+		}
+	}
+
+	if len(entry.Trashed) > 0 {
+		if _, err := RestoreExpenses(ctx, entry.UserID, entry.Trashed); err != nil {
+			return err
+		}
+	}
+	if len(entry.Restored) > 0 {
+		if _, err := TrashExpenses(ctx, entry.UserID, entry.Restored); err != nil {
+			return err
+		}
+	}
+
+	for i := range entry.Previous {
+		if err := putExpenseItem(ctx, &entry.Previous[i]); err != nil {
+			slog.ErrorContext(ctx, "Failed to restore expense", "expense_id", entry.Previous[i].ExpenseID, "error", err)
+			return err
+		}
+	}
+
+	slog.InfoContext(ctx, "Action undone", "user_id", entry.UserID, "action", entry.Action,
+		"deleted", len(entry.Created), "restored", len(entry.Trashed), "trashed", len(entry.Restored), "reverted", len(entry.Previous))
+	return nil
+}
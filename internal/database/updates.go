@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const updatePrefix = "update#"
+
+// UpdateRetention is how long a processed update_id is remembered. Telegram
+// gives up redelivering an update after 24 hours.
+const UpdateRetention = 48 * time.Hour
+
+// MarkUpdateProcessed records that an update was handled, keyed by the user
+// who sent it. It returns false when the update had already been recorded.
+// The item expires through the table's TTL on expires_at.
+func MarkUpdateProcessed(ctx context.Context, userID int64, updateID int) (bool, error) {
+	if dynamoClient == nil {
+		return false, fmt.Errorf("DynamoDB client is not initialized")
+	}
+
+	now := time.Now()
+	_, err := dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item: map[string]types.AttributeValue{
+			"user_id":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", userID)},
+			"expense_id": &types.AttributeValueMemberS{Value: updatePrefix + strconv.Itoa(updateID)},
+			"expires_at": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(UpdateRetention).Unix(), 10)},
+		},
+		// An item left behind after expiring but before TTL removed it does not count.
+		ConditionExpression: aws.String("attribute_not_exists(expense_id) OR expires_at < :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+		},
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record update", "update_id", updateID, "error", err)
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"money-telegram-bot/internal/billing"
+	"money-telegram-bot/internal/charts"
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	pieChartSize   = 480
+	barChartWidth  = 720
+	barChartHeight = 360
+)
+
+// HandleChart handles /grafico [mês|ano] — sends a pie chart by category and
+// a bar chart of daily (month) or monthly (year) totals.
+func HandleChart(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /grafico command", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+
+	p := i18n.FromContext(ctx)
+	period := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	yearly := false
+	switch period {
+	case "", "mes", "mês", "month":
+	case "ano", "year", "año":
+		yearly = true
+	default:
+		reply(ctx, bot, message, p.T("chart.usage"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	expenses, err := database.GetBillableExpenses(ctx, message.From.ID)
+	if err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("error.query_expenses")))
+		return
+	}
+
+	now := time.Now().In(billing.Location)
+	var title string
+	var buckets []float64
+	var inPeriod []models.Expense
+	if yearly {
+		title = fmt.Sprintf("%d", now.Year())
+		buckets = make([]float64, 12)
+		for _, expense := range expenses {
+			created := expense.CreatedAt.In(billing.Location)
+			if created.Year() == now.Year() {
+				inPeriod = append(inPeriod, expense)
+				buckets[created.Month()-1] += expense.Amount
+			}
+		}
+	} else {
+		title = p.T("chart.month_title", p.Month(now.Month()), now.Year())
+		daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, billing.Location).Day()
+		buckets = make([]float64, daysInMonth)
+		for _, expense := range expenses {
+			created := expense.CreatedAt.In(billing.Location)
+			if created.Year() == now.Year() && created.Month() == now.Month() {
+				inPeriod = append(inPeriod, expense)
+				buckets[created.Day()-1] += expense.Amount
+			}
+		}
+	}
+
+	if len(inPeriod) == 0 {
+		reply(ctx, bot, message, p.T("chart.empty", title))
+		return
+	}
+
+	labels, values := categoryTotals(p, inPeriod, len(charts.Palette))
+	pie, err := charts.Pie(values, pieChartSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to render pie chart", "user_id", message.From.ID, "error", err)
+		reply(ctx, bot, message, p.T("chart.failed"))
+		return
+	}
+	bars, err := charts.Bars(buckets, barChartWidth, barChartHeight)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to render bar chart", "user_id", message.From.ID, "error", err)
+		reply(ctx, bot, message, p.T("chart.failed"))
+		return
+	}
+
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+
+	var legend strings.Builder
+	legend.WriteString(p.T("chart.pie_caption", title, p.Money(total)))
+	for i, label := range labels {
+		legend.WriteString(p.T("chart.pie_item", charts.Palette[i].Emoji, label, p.Money(values[i]), p.Percent(values[i]/total)))
+	}
+
+	peak, peakValue := 0, 0.0
+	for i, v := range buckets {
+		if v > peakValue {
+			peak, peakValue = i, v
+		}
+	}
+	var barsCaption string
+	if yearly {
+		barsCaption = p.T("chart.bars_year", title, p.Month(time.Month(peak+1)), p.Money(peakValue))
+	} else {
+		barsCaption = p.T("chart.bars_month", title, len(buckets), peak+1, p.Money(peakValue))
+	}
+
+	sendChart(ctx, bot, message, "categorias.png", pie, legend.String())
+	sendChart(ctx, bot, message, "totais.png", bars, barsCaption)
+}
+
+// categoryTotals sums the expenses per category, largest first. Categories
+// beyond maxSlices-1 are merged into "others" so every slice has a color.
+func categoryTotals(p *i18n.Printer, expenses []models.Expense, maxSlices int) ([]string, []float64) {
+	sums := make(map[string]float64)
+	for _, expense := range expenses {
+		sums[expense.Category] += expense.Amount
+	}
+
+	labels := make([]string, 0, len(sums))
+	for category := range sums {
+		labels = append(labels, category)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if sums[labels[i]] != sums[labels[j]] {
+			return sums[labels[i]] > sums[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+
+	values := make([]float64, 0, len(labels))
+	for _, label := range labels {
+		values = append(values, sums[label])
+	}
+
+	if len(labels) > maxSlices {
+		var others float64
+		for _, v := range values[maxSlices-1:] {
+			others += v
+		}
+		labels = append(labels[:maxSlices-1], p.T("chart.others"))
+		values = append(values[:maxSlices-1], others)
+	}
+	return labels, values
+}
+
+func sendChart(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, name string, image []byte, caption string) {
+	photo := tgbotapi.NewPhoto(message.Chat.ID, tgbotapi.FileBytes{Name: name, Bytes: image})
+	photo.Caption = caption
+	send(ctx, bot, photo)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"money-telegram-bot/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Scope is a set of chat types whose command menu lists a command.
+type Scope uint8
+
+const (
+	ScopePrivate Scope = 1 << iota // private chats with the bot
+	ScopeGroup                     // groups and supergroups
+)
+
+// Command is a bot command. The registry below drives the dispatch in
+// RouteUpdate, the /start and /help texts and the Telegram command menu; its
+// texts live in the catalogues under "commands.<name>" (menu and /start) and
+// "help.<name>" (/help).
+type Command struct {
+	Name   string // as typed after the slash
+	Emoji  string
+	Scopes Scope // menus that list the command; none for commands Telegram already offers
+	Handle func(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message)
+}
+
+// commands is the registry, in the order the commands are listed. It is set in
+// init because /start and /help read it.
+var commands []Command
+
+func init() {
+	commands = []Command{
+		{Name: "start", Emoji: "▶️", Handle: HandleStart},
+		{Name: "gastei", Emoji: "💸", Scopes: ScopePrivate | ScopeGroup, Handle: HandleExpense},
+		{Name: "consulta", Emoji: "📋", Scopes: ScopePrivate | ScopeGroup, Handle: HandleQuery},
+		{Name: "grafico", Emoji: "📊", Scopes: ScopePrivate | ScopeGroup, Handle: HandleChart},
+		{Name: "deletar", Emoji: "🗑️", Scopes: ScopePrivate, Handle: HandleDelete},
+		{Name: "deletartudo", Emoji: "❌", Scopes: ScopePrivate, Handle: HandleDeleteAll},
+		{Name: "desfazer", Emoji: "↩️", Scopes: ScopePrivate | ScopeGroup, Handle: HandleUndo},
+		{Name: "lixeira", Emoji: "♻️", Scopes: ScopePrivate, Handle: HandleTrash},
+		{Name: "metodo", Emoji: "💳", Scopes: ScopePrivate, Handle: HandlePaymentMethod},
+		{Name: "fatura", Emoji: "🧾", Scopes: ScopePrivate | ScopeGroup, Handle: HandleInvoice},
+		{Name: "cotacao", Emoji: "💱", Scopes: ScopePrivate, Handle: HandleExchangeRate},
+		{Name: "idioma", Emoji: "🌐", Scopes: ScopePrivate, Handle: HandleLanguage},
+		{Name: "help", Emoji: "ℹ️", Scopes: ScopePrivate | ScopeGroup, Handle: HandleHelp},
+	}
+}
+
+// Commands returns the registered commands, in order.
+func Commands() []Command {
+	return commands
+}
+
+// LookupCommand returns the command called name.
+func LookupCommand(name string) (Command, bool) {
+	for _, command := range commands {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return Command{}, false
+}
+
+// AddressedToBot reports whether a command message is meant for this bot: in
+// groups commands may carry the name of the bot they are for, as in
+// /gastei@MoneySaviorBot, and commands for other bots must be left alone.
+func AddressedToBot(bot *tgbotapi.BotAPI, message *tgbotapi.Message) bool {
+	_, username, found := strings.Cut(message.CommandWithAt(), "@")
+	return !found || strings.EqualFold(username, bot.Self.UserName)
+}
+
+// menu returns the commands listed in the menu of scope, described in the
+// language of p.
+func menu(p *i18n.Printer, scope Scope) []tgbotapi.BotCommand {
+	var listed []tgbotapi.BotCommand
+	for _, command := range commands {
+		if command.Scopes&scope != 0 {
+			listed = append(listed, tgbotapi.BotCommand{
+				Command:     command.Name,
+				Description: p.T("commands." + command.Name),
+			})
+		}
+	}
+	return listed
+}
+
+// RegisterCommands publishes the command menu with setMyCommands, for private
+// chats and for groups, in every language. The menu without a language, shown
+// to users whose language is not supported, is in the default one.
+func RegisterCommands(ctx context.Context, bot *tgbotapi.BotAPI) error {
+	scopes := []struct {
+		scope    Scope
+		telegram tgbotapi.BotCommandScope
+	}{
+		{ScopePrivate, tgbotapi.NewBotCommandScopeAllPrivateChats()},
+		{ScopeGroup, tgbotapi.NewBotCommandScopeAllGroupChats()},
+	}
+
+	for _, s := range scopes {
+		config := tgbotapi.NewSetMyCommandsWithScope(s.telegram, menu(i18n.New(i18n.Default), s.scope)...)
+		if err := request(ctx, bot, config); err != nil {
+			return fmt.Errorf("setMyCommands for %s: %w", s.telegram.Type, err)
+		}
+		for _, lang := range i18n.Languages {
+			config := tgbotapi.NewSetMyCommandsWithScopeAndLanguage(s.telegram, lang.Code(), menu(i18n.New(lang), s.scope)...)
+			if err := request(ctx, bot, config); err != nil {
+				return fmt.Errorf("setMyCommands for %s in %s: %w", s.telegram.Type, lang, err)
+			}
+		}
+	}
+
+	slog.InfoContext(ctx, "Command menu registered", "commands", len(commands))
+	return nil
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"money-telegram-bot/internal/i18n"
+)
+
+// Deadlines of each kind of operation, derived from the context of the
+// update: a Lambda deadline or a cancelled request cuts them shorter.
+const (
+	storageTimeout    = 10 * time.Second // DynamoDB reads and writes
+	nfceTimeout       = 30 * time.Second // fetching an NFC-e page from SEFAZ
+	ocrTimeout        = 30 * time.Second // downloading and reading a receipt photo
+	transcribeTimeout = 60 * time.Second // downloading and transcribing a voice message
+)
+
+// timedOut reports whether err was caused by a context deadline or cancellation.
+func timedOut(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// failureText returns the timeout message when err is a timeout, and text otherwise.
+func failureText(ctx context.Context, err error, text string) string {
+	if timedOut(err) {
+		return i18n.FromContext(ctx).T("error.timeout")
+	}
+	return text
+}
+
+// callbackFailureText is failureText for callback answers.
+func callbackFailureText(ctx context.Context, err error, text string) string {
+	if timedOut(err) {
+		return i18n.FromContext(ctx).T("error.timeout_short")
+	}
+	return text
+}
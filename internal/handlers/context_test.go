@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"money-telegram-bot/internal/i18n"
+)
+
+func TestFailureText(t *testing.T) {
+	p := i18n.New(i18n.Default)
+	ctx := i18n.NewContext(context.Background(), p)
+
+	tests := []struct {
+		name    string
+		err     error
+		timeout bool
+	}{
+		{"deadline", context.DeadlineExceeded, true},
+		{"cancelled", context.Canceled, true},
+		{"wrapped by the SDK", fmt.Errorf("operation error DynamoDB: Query: %w", context.DeadlineExceeded), true},
+		{"other error", errors.New("ResourceNotFoundException"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, short := p.T("error.timeout"), p.T("error.timeout_short")
+			if !tt.timeout {
+				text, short = "falhou", "falhou"
+			}
+			if got := failureText(ctx, tt.err, "falhou"); got != text {
+				t.Errorf("failureText = %q, want %q", got, text)
+			}
+			if got := callbackFailureText(ctx, tt.err, "falhou"); got != short {
+				t.Errorf("callbackFailureText = %q, want %q", got, short)
+			}
+		})
+	}
+}
+
+// stalledEngine is an OCR and speech engine that only returns when its
+// context is done, like a hung tesseract or whisper process.
+type stalledEngine struct{}
+
+func (stalledEngine) Recognize(ctx context.Context, _ []byte) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (stalledEngine) Transcribe(ctx context.Context, _ []byte, _ string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestEnginesStopAtTheDeadline(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(ctx context.Context) error
+	}{
+		{"receipt", func(ctx context.Context) error {
+			_, _, err := readReceipt(ctx, stalledEngine{}, []byte("jpeg"), 42)
+			return err
+		}},
+		{"voice", func(ctx context.Context) error {
+			_, _, err := readVoice(ctx, stalledEngine{}, []byte("ogg"), "audio/ogg", 42, nil)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() { done <- tt.read(ctx) }()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("err = %v, want a deadline error", err)
+				}
+				if !timedOut(err) {
+					t.Error("the user would not be told it timed out")
+				}
+			case <-time.After(time.Second):
+				t.Fatal("the deadline did not reach the engine")
+			}
+		})
+	}
+}
+
+func TestCancelledUpdateStopsEngines(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, draft, err := readReceipt(ctx, stalledEngine{}, []byte("jpeg"), 42)
+	if !errors.Is(err, context.Canceled) || draft != (expenseDraft{}) {
+		t.Errorf("readReceipt = %+v, %v; want a cancelled read", draft, err)
+	}
+}
@@ -3,155 +3,199 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
 
 	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+	"money-telegram-bot/internal/render"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // HandleDelete handles /deletar <id> — shows inline confirmation before deleting.
-func HandleDelete(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	log.Printf("[INFO] Processing /deletar command | chatID=%d | userID=%d", message.Chat.ID, message.From.ID)
+func HandleDelete(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /deletar command", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+	p := i18n.FromContext(ctx)
 	args := strings.Fields(message.CommandArguments())
 	if len(args) != 1 {
-		reply(bot, message, "❌ Uso incorreto. Use: /deletar <ID do gasto>\nExemplo: /deletar 3\n\nUse /consulta para ver os IDs dos seus gastos.")
+		reply(ctx, bot, message, p.T("delete.usage"))
 		return
 	}
 
 	seqID, err := strconv.Atoi(args[0])
 	if err != nil || seqID < 1 {
-		reply(bot, message, "❌ ID inválido. Use um número inteiro maior que zero.\nExemplo: /deletar 3")
+		reply(ctx, bot, message, p.T("delete.invalid_id"))
 		return
 	}
 
-	expense, err := database.GetExpenseBySeqID(context.Background(), message.From.ID, seqID)
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	expense, err := database.GetExpenseBySeqID(ctx, message.From.ID, seqID)
 	if err != nil {
-		reply(bot, message, fmt.Sprintf("❌ Nenhum gasto encontrado com o ID %d.\nUse /consulta para ver os IDs disponíveis.", seqID))
+		reply(ctx, bot, message, failureText(ctx, err, p.T("delete.not_found", seqID)))
 		return
 	}
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"✅ Sim, deletar",
-				fmt.Sprintf("confirm_delete:%d", seqID),
+				p.T("delete.confirm_button"),
+				fmt.Sprintf("confirm_delete:%d:%d", message.From.ID, seqID),
 			),
-			tgbotapi.NewInlineKeyboardButtonData("❌ Cancelar", "cancel_delete"),
+			tgbotapi.NewInlineKeyboardButtonData(p.T("common.cancel"), fmt.Sprintf("cancel_delete:%d", message.From.ID)),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf(
-		"⚠️ Tem certeza que deseja deletar este gasto?\n\n"+
-			"🆔 ID: %d\n"+
-			"💰 Valor: R$ %.2f\n"+
-			"📝 Categoria: %s\n"+
-			"💳 Método: %s",
+	text := render.Sprintf(p.Format("delete.confirm"),
 		expense.SeqID,
-		expense.Amount,
+		amountLabel(p, expense),
 		expense.Category,
-		expense.Method,
-	))
+		methodLabel(p, expense.Method),
+	)
+	if expense.IsInstallmentPurchase() {
+		text += p.T("delete.confirm_installments", expense.InstallmentCount)
+	}
+	if expense.ReceiptFileID != "" {
+		text += p.T("delete.confirm_receipt")
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = render.ParseMode
 	msg.ReplyMarkup = keyboard
-	bot.Send(msg)
+	send(ctx, bot, msg)
 }
 
 // HandleConfirmDeleteCallback handles inline button confirmation for single delete.
-func HandleConfirmDeleteCallback(bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+func HandleConfirmDeleteCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
 	chatID := callback.Message.Chat.ID
-	userID := callback.From.ID
-
-	bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	p := i18n.FromContext(ctx)
+
+	// data format: "confirm_delete:<userID>:<seqID>" or "cancel_delete:<userID>"
+	action, args, _ := strings.Cut(callback.Data, ":")
+	var userID int64
+	var seqID int
+	fmt.Sscanf(args, "%d:%d", &userID, &seqID)
+	if userID != callback.From.ID {
+		answerCallback(ctx, bot, callback, p.T("delete.not_yours"))
+		return
+	}
+	answerCallback(ctx, bot, callback, "")
 
-	if callback.Data == "cancel_delete" {
-		edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, "❌ Operação cancelada.")
+	if action == "cancel_delete" {
+		edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, p.T("common.cancelled"))
 		edit.ReplyMarkup = nil
-		bot.Send(edit)
+		send(ctx, bot, edit)
 		return
 	}
 
-	// data format: "confirm_delete:<seqID>"
-	seqID := 0
-	fmt.Sscanf(callback.Data, "confirm_delete:%d", &seqID)
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
 
-	err := database.DeleteExpenseBySeqID(context.Background(), userID, seqID)
+	deleted, err := database.DeleteExpenseBySeqID(ctx, userID, seqID)
 	if err != nil {
-		log.Printf("[ERROR] Failed to delete expense for userID=%d with seqID=%d: %v", userID, seqID, err)
-		edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
-			fmt.Sprintf("❌ Nenhum gasto encontrado com o ID %d.", seqID))
-		bot.Send(edit)
+		slog.ErrorContext(ctx, "Failed to delete expense", "user_id", userID, "seq_id", seqID, "deleted", len(deleted), "error", err)
+		sendPartialDelete(ctx, bot, callback, p, failureText(ctx, err, p.T("common.expense_not_found", seqID)),
+			&models.UndoEntry{UserID: userID, Action: models.UndoDelete, SeqID: seqID, Trashed: topLevelIDs(deleted)})
 		return
 	}
 
+	actionID := recordUndo(ctx, &models.UndoEntry{UserID: userID, Action: models.UndoDelete, SeqID: seqID, Trashed: topLevelIDs(deleted)})
 	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
-		fmt.Sprintf("✅ Gasto #%d deletado com sucesso!", seqID))
-	edit.ReplyMarkup = nil
-	bot.Send(edit)
-	log.Printf("[INFO] Expense deleted | userID=%d | seqID=%d", userID, seqID)
+		p.T("delete.done", seqID)+p.T("delete.trash_note", trashDays()))
+	edit.ReplyMarkup = withUndoButton(p, nil, userID, actionID)
+	send(ctx, bot, edit)
+	slog.InfoContext(ctx, "Expense deleted", "user_id", userID, "seq_id", seqID)
 }
 
 // HandleDeleteAll handles /deletartudo — shows inline confirmation before deleting everything.
-func HandleDeleteAll(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	log.Printf("[INFO] Processing /deletartudo command | chatID=%d | userID=%d", message.Chat.ID, message.From.ID)
+func HandleDeleteAll(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /deletartudo command", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+	p := i18n.FromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
 
-	total, err := database.GetTotalExpenses(context.Background(), message.From.ID)
+	total, err := database.GetTotalExpenses(ctx, message.From.ID)
 	if err != nil {
-		reply(bot, message, "❌ Ocorreu um erro ao consultar seus gastos. Tente novamente mais tarde.")
+		reply(ctx, bot, message, failureText(ctx, err, p.T("error.query_expenses")))
 		return
 	}
 
 	if total == 0 {
-		reply(bot, message, "📝 Você não possui nenhum gasto registrado.")
+		reply(ctx, bot, message, p.T("delete_all.none"))
 		return
 	}
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("🗑️ Sim, deletar todos (%d)", total),
-				"confirm_delete_all",
+				p.T("delete_all.confirm_button", total),
+				fmt.Sprintf("confirm_delete_all:%d", message.From.ID),
 			),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("❌ Cancelar", "cancel_delete_all"),
+			tgbotapi.NewInlineKeyboardButtonData(p.T("common.cancel"), fmt.Sprintf("cancel_delete_all:%d", message.From.ID)),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(message.Chat.ID,
-		fmt.Sprintf("⚠️ *Atenção!* Você está prestes a deletar *todos os %d gastos* registrados.\n\nEssa ação é irreversível. Deseja continuar?", total),
-	)
-	msg.ParseMode = "Markdown"
+	msg := tgbotapi.NewMessage(message.Chat.ID, p.T("delete_all.confirm", total, int(undoWindow.Minutes())))
+	msg.ParseMode = render.ParseMode
 	msg.ReplyMarkup = keyboard
-	bot.Send(msg)
+	send(ctx, bot, msg)
 }
 
 // HandleDeleteAllCallback handles inline button confirmation for delete-all.
-func HandleDeleteAllCallback(bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+func HandleDeleteAllCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
 	chatID := callback.Message.Chat.ID
-	userID := callback.From.ID
-
-	bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+	p := i18n.FromContext(ctx)
+
+	// data format: "confirm_delete_all:<userID>" or "cancel_delete_all:<userID>"
+	action, owner, _ := strings.Cut(callback.Data, ":")
+	var userID int64
+	fmt.Sscanf(owner, "%d", &userID)
+	if userID != callback.From.ID {
+		answerCallback(ctx, bot, callback, p.T("delete.not_yours"))
+		return
+	}
+	answerCallback(ctx, bot, callback, "")
 
-	if callback.Data == "cancel_delete_all" {
-		edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, "❌ Operação cancelada.")
+	if action == "cancel_delete_all" {
+		edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, p.T("common.cancelled"))
 		edit.ReplyMarkup = nil
-		bot.Send(edit)
+		send(ctx, bot, edit)
 		return
 	}
 
-	err := database.DeleteAllExpenses(context.Background(), userID)
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	deleted, err := database.DeleteAllExpenses(ctx, userID)
 	if err != nil {
-		log.Printf("[ERROR] Failed to delete all expenses for userID=%d: %v", userID, err)
-		edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID,
-			"❌ Ocorreu um erro ao deletar os gastos. Tente novamente mais tarde.")
-		bot.Send(edit)
+		slog.ErrorContext(ctx, "Failed to delete all expenses", "user_id", userID, "deleted", len(deleted), "error", err)
+		sendPartialDelete(ctx, bot, callback, p, failureText(ctx, err, p.T("delete_all.failed")),
+			&models.UndoEntry{UserID: userID, Action: models.UndoDeleteAll, Trashed: topLevelIDs(deleted)})
 		return
 	}
 
-	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, "✅ Todos os gastos foram deletados com sucesso!")
-	edit.ReplyMarkup = nil
-	bot.Send(edit)
-	log.Printf("[INFO] All expenses deleted | userID=%d", userID)
-}
\ No newline at end of file
+	actionID := recordUndo(ctx, &models.UndoEntry{UserID: userID, Action: models.UndoDeleteAll, Trashed: topLevelIDs(deleted)})
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, p.T("delete_all.done")+p.T("delete.trash_note", trashDays()))
+	edit.ReplyMarkup = withUndoButton(p, nil, userID, actionID)
+	send(ctx, bot, edit)
+	slog.InfoContext(ctx, "All expenses deleted", "user_id", userID)
+}
+
+// sendPartialDelete reports a failed delete. When some expenses went to the
+// trash before the failure, it records entry so they can still be undone.
+func sendPartialDelete(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery, p *i18n.Printer, text string, entry *models.UndoEntry) {
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	if len(entry.Trashed) > 0 {
+		actionID := recordUndo(ctx, entry)
+		edit.Text += p.T("delete.partial")
+		edit.ReplyMarkup = withUndoButton(p, nil, entry.UserID, actionID)
+	}
+	send(ctx, bot, edit)
+}
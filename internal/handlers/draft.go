@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"money-telegram-bot/internal/billing"
+	"money-telegram-bot/internal/classifier"
+	"money-telegram-bot/internal/currency"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// expenseDraft is an expense the bot read from somewhere else (a receipt
+// photo, a voice message...) and proposes to the user before saving. It
+// travels in the callback data of the confirmation button, so saving needs
+// no server-side state.
+type expenseDraft struct {
+	UserID      int64 // who the draft is proposed to
+	Amount      float64
+	Date        time.Time // zero means "now"
+	Method      string    // empty when unknown
+	Description string
+}
+
+// callbackData encodes the draft as "draft:<userID>:<cents>:<yyyymmdd|->:<method>:<description>",
+// shortening the description to fit Telegram's 64-byte limit. The method goes
+// by name, as registered names are capped at maxMethodName, so a card added or
+// removed before the tap never changes which one is saved; a name that still
+// does not fit is dropped.
+func (d expenseDraft) callbackData() string {
+	date := "-"
+	if !d.Date.IsZero() {
+		date = d.Date.In(billing.Location).Format("20060102")
+	}
+	head := fmt.Sprintf("draft:%d:%d:%s:", d.UserID, int64(math.Round(d.Amount*100)), date)
+	method := strings.ReplaceAll(d.Method, ":", "")
+	if utf8.RuneCountInString(method) > maxMethodName || len(head)+len(method)+1 > maxCallbackData {
+		method = ""
+	}
+	prefix := head + method + ":"
+
+	description := d.Description
+	for description != "" && len(prefix)+len(description) > maxCallbackData {
+		_, size := utf8.DecodeLastRuneInString(description)
+		description = description[:len(description)-size]
+	}
+	return prefix + strings.TrimSpace(description)
+}
+
+func parseDraft(data string) (expenseDraft, bool) {
+	parts := strings.SplitN(data, ":", 6)
+	if len(parts) != 6 || parts[0] != "draft" {
+		return expenseDraft{}, false
+	}
+
+	userID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return expenseDraft{}, false
+	}
+	cents, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || cents <= 0 {
+		return expenseDraft{}, false
+	}
+
+	draft := expenseDraft{
+		UserID:      userID,
+		Amount:      float64(cents) / 100,
+		Method:      parts[4],
+		Description: parts[5],
+	}
+	if parts[3] != "-" {
+		date, err := time.ParseInLocation("20060102", parts[3], billing.Location)
+		if err != nil {
+			return expenseDraft{}, false
+		}
+		draft.Date = date
+	}
+	if draft.Description == "" {
+		draft.Description = "outros"
+	}
+	return draft, true
+}
+
+// draftTime dates a drafted expense on the day of date at the time of day of
+// now, both on the calendar of billing.Location, so that receipts of the same
+// day keep the order they were logged in.
+func draftTime(date, now time.Time) time.Time {
+	local := now.In(billing.Location)
+	year, month, day := date.In(billing.Location).Date()
+	return time.Date(year, month, day, local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), billing.Location).UTC()
+}
+
+// command renders the draft as the equivalent /gastei command.
+func (d expenseDraft) command() string {
+	command := fmt.Sprintf("/gastei %s %s", strings.Replace(fmt.Sprintf("%.2f", d.Amount), ".", ",", 1), d.Description)
+	if d.Method != "" {
+		command += " " + d.Method
+	}
+	return command
+}
+
+func buildDraftKeyboard(p *i18n.Printer, draft expenseDraft) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.T("draft.register_button"), draft.callbackData()),
+			tgbotapi.NewInlineKeyboardButtonData(p.T("common.cancel"), fmt.Sprintf("draft_cancel:%d", draft.UserID)),
+		),
+	)
+}
+
+// HandleDraftCallback saves (or discards) an expense proposed by the bot. Only
+// the user it was proposed to can use the buttons.
+func HandleDraftCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	user := callback.From
+	p := i18n.FromContext(ctx)
+
+	// data format: "draft_cancel:<userID>"
+	if owner, found := strings.CutPrefix(callback.Data, "draft_cancel:"); found {
+		if owner != strconv.FormatInt(user.ID, 10) {
+			answerCallback(ctx, bot, callback, p.T("draft.not_yours"))
+			return
+		}
+		answerCallback(ctx, bot, callback, "")
+		edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, p.T("common.cancelled"))
+		send(ctx, bot, edit)
+		return
+	}
+
+	draft, ok := parseDraft(callback.Data)
+	if !ok {
+		slog.WarnContext(ctx, "Invalid draft callback", "data", callback.Data)
+		answerCallback(ctx, bot, callback, p.T("draft.invalid"))
+		return
+	}
+	if draft.UserID != user.ID {
+		answerCallback(ctx, bot, callback, p.T("draft.not_yours"))
+		return
+	}
+
+	createdAt := time.Now().UTC()
+	if !draft.Date.IsZero() {
+		createdAt = draftTime(draft.Date, createdAt)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	method := draft.Method
+	if method == "" {
+		method = classifier.UnknownMethod
+	}
+
+	expense := &models.Expense{
+		UserID:      user.ID,
+		ChatID:      chatID,
+		Username:    user.UserName,
+		Amount:      draft.Amount,
+		Method:      method,
+		Description: draft.Description,
+		CreatedAt:   createdAt,
+	}
+	// Proposals are sent as a reply to the user's photo, which becomes the receipt.
+	if original := callback.Message.ReplyToMessage; original != nil && len(original.Photo) > 0 {
+		expense.ReceiptFileID = largestPhoto(original.Photo).FileID
+	}
+
+	// Receipts and voice messages are read in reais.
+	if err := convertExpense(ctx, expense, currency.Default); err != nil {
+		slog.ErrorContext(ctx, "Failed to convert drafted expense", "user_id", user.ID, "error", err)
+		answerCallback(ctx, bot, callback, callbackFailureText(ctx, err, conversionFailureText(p, err, currency.Default)))
+		return
+	}
+
+	keyboard, suggested, err := saveExpense(ctx, expense, 1)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save drafted expense", "user_id", user.ID, "error", err)
+		answerCallback(ctx, bot, callback, callbackFailureText(ctx, err, p.T("error.save_expense")))
+		return
+	}
+
+	answerCallback(ctx, bot, callback, p.T("draft.saved"))
+
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, buildSavedExpenseText(p, expense, suggested))
+	edit.ReplyMarkup = keyboard
+	send(ctx, bot, edit)
+	slog.InfoContext(ctx, "Drafted expense saved", "user_id", user.ID, "seq_id", expense.SeqID)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"money-telegram-bot/internal/billing"
+	"money-telegram-bot/internal/speech"
+)
+
+func TestDraftCallbackDataRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		draft expenseDraft
+		want  expenseDraft
+	}{
+		{
+			name:  "registered method",
+			draft: expenseDraft{UserID: 1234567890, Amount: 20, Method: "nubank", Description: "uber"},
+			want:  expenseDraft{UserID: 1234567890, Amount: 20, Method: "nubank", Description: "uber"},
+		},
+		{
+			name:  "common method",
+			draft: expenseDraft{UserID: 7, Amount: 12.5, Method: "pix", Description: "padaria"},
+			want:  expenseDraft{UserID: 7, Amount: 12.5, Method: "pix", Description: "padaria"},
+		},
+		{
+			name:  "no method nor description",
+			draft: expenseDraft{UserID: 7, Amount: 0.99},
+			want:  expenseDraft{UserID: 7, Amount: 0.99, Description: "outros"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.draft.callbackData()
+			got, ok := parseDraft(data)
+			if !ok {
+				t.Fatalf("parseDraft(%q) failed", data)
+			}
+			if got != tt.want {
+				t.Errorf("parseDraft(%q) = %+v, want %+v", data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDraftCallbackDataFitsTelegramLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		draft expenseDraft
+	}{
+		{"long description", expenseDraft{UserID: 9007199254740991, Amount: 1234.56, Description: strings.Repeat("supermercado", 10)}},
+		{"multibyte description", expenseDraft{UserID: 9007199254740991, Amount: 10, Description: strings.Repeat("pão de açúcar ", 8)}},
+		{"long unregistered method", expenseDraft{UserID: 9007199254740991, Amount: 10, Method: strings.Repeat("m", 70), Description: "café"}},
+		{"longest registered method", expenseDraft{UserID: 9007199254740991, Amount: 1e9, Method: strings.Repeat("ç", maxMethodName), Description: "café"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan string, 1)
+			go func() { done <- tt.draft.callbackData() }()
+
+			select {
+			case data := <-done:
+				if len(data) > maxCallbackData || !utf8.ValidString(data) {
+					t.Errorf("callback data has %d bytes: %q", len(data), data)
+				}
+				if _, ok := parseDraft(data); !ok {
+					t.Errorf("parseDraft(%q) failed", data)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("callbackData did not return")
+			}
+		})
+	}
+}
+
+func TestDraftKeepsMethodWhenMethodsChange(t *testing.T) {
+	_, draft, err := readVoice(context.Background(), speech.Fake{Text: "gastei trinta reais no mercado no nubank"},
+		[]byte("ogg"), "audio/ogg", 42, []string{"itau", "nubank"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := draft.callbackData()
+
+	// The card was second in the list at the preview. The user then registers
+	// "alelo" and removes "itau", making it first: the tap must still save
+	// nubank, which only holds if the card travels by name.
+	got, ok := parseDraft(data)
+	if !ok {
+		t.Fatalf("parseDraft(%q) failed", data)
+	}
+	if got.Method != "nubank" {
+		t.Errorf("parseDraft(%q) saves method %q, want nubank", data, got.Method)
+	}
+}
+
+func TestDraftDateIsOnTheBrazilianCalendar(t *testing.T) {
+	draft, ok := parseDraft("draft:42:1990:20250310::farmacia")
+	if !ok {
+		t.Fatal("parseDraft failed")
+	}
+
+	// 01:30 UTC on the 12th is still the 11th in Brazil.
+	now := time.Date(2025, time.March, 12, 1, 30, 0, 0, time.UTC)
+	got := draftTime(draft.Date, now).In(billing.Location)
+	want := time.Date(2025, time.March, 10, 22, 30, 0, 0, billing.Location)
+	if !got.Equal(want) {
+		t.Errorf("draftTime = %v, want %v", got, want)
+	}
+
+	// Two receipts of the same day get different times.
+	later := draftTime(draft.Date, now.Add(time.Millisecond))
+	if later.Equal(got) {
+		t.Error("receipts of the same day share a timestamp")
+	}
+}
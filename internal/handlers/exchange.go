@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"money-telegram-bot/internal/currency"
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// exchangeRates converts expenses paid in other currencies when the user has
+// not pinned a rate with /cotacao.
+var exchangeRates currency.ExchangeRateProvider = currency.FromEnv()
+
+// SetExchangeRateProvider replaces the provider of exchange rates.
+func SetExchangeRateProvider(provider currency.ExchangeRateProvider) {
+	exchangeRates = provider
+}
+
+// baseCurrency returns the currency the user keeps their totals in.
+func baseCurrency(settings *models.UserSettings) string {
+	if code, ok := currency.Parse(settings.BaseCurrency); ok {
+		return code
+	}
+	return currency.Default
+}
+
+// userRates returns the rates of the user: the ones they pinned, else the provider's.
+func userRates(settings *models.UserSettings) currency.ExchangeRateProvider {
+	return currency.Overrides{Base: baseCurrency(settings), Rates: settings.Rates, Next: exchangeRates}
+}
+
+// convertExpense keeps the amount of an expense paid in code and converts it
+// to the base currency of the user.
+func convertExpense(ctx context.Context, expense *models.Expense, code string) error {
+	settings, err := database.GetUserSettings(ctx, expense.UserID)
+	if err != nil {
+		return err
+	}
+	base := baseCurrency(settings)
+	if code == base {
+		return nil
+	}
+
+	amount, rate, err := currency.Convert(ctx, userRates(settings), expense.Amount, code, base)
+	if err != nil {
+		return err
+	}
+	expense.Currency = code
+	expense.OriginalAmount = expense.Amount
+	expense.ExchangeRate = rate
+	expense.Amount = amount
+	return nil
+}
+
+// conversionFailureText explains why an expense paid in code was not saved.
+func conversionFailureText(p *i18n.Printer, err error, code string) string {
+	if errors.Is(err, currency.ErrNoRate) {
+		return p.T("expense.no_rate", code, p.Currency(), code)
+	}
+	return p.T("error.save_expense")
+}
+
+// amountLabel shows the amount of an expense in the base currency, followed by
+// what was paid when it was another currency: "R$ 108,40 (US$ 20,00)".
+func amountLabel(p *i18n.Printer, expense *models.Expense) string {
+	if !expense.IsForeign() {
+		return p.Money(expense.Amount)
+	}
+	return p.Money(expense.Amount) + " (" + p.Amount(expense.OriginalAmount, expense.Currency) + ")"
+}
+
+// HandleExchangeRate handles /cotacao — shows the rates used to convert
+// expenses in other currencies, pins or unpins a rate, and changes the base
+// currency.
+func HandleExchangeRate(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /cotacao command", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+	p := i18n.FromContext(ctx)
+	usage := p.T("rate.usage", p.T("rate.help"))
+	args := strings.Fields(message.CommandArguments())
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	settings, err := database.GetUserSettings(ctx, message.From.ID)
+	if err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("rate.query_failed")))
+		return
+	}
+	base := baseCurrency(settings)
+
+	if len(args) == 0 {
+		reply(ctx, bot, message, describeRates(p, settings))
+		return
+	}
+	if len(args) > 2 {
+		reply(ctx, bot, message, usage)
+		return
+	}
+
+	command := strings.ToLower(args[0])
+	if len(args) == 2 && (command == "base" || command == "remover") {
+		code, ok := currency.Parse(args[1])
+		if !ok {
+			reply(ctx, bot, message, p.T("rate.unknown_currency", args[1]))
+			return
+		}
+
+		if command == "base" && code != base {
+			// Amounts are stored in the base currency, so existing expenses
+			// would be read in the new one.
+			hasExpenses, err := database.HasExpenses(ctx, message.From.ID)
+			if err != nil {
+				reply(ctx, bot, message, failureText(ctx, err, p.T("rate.save_failed")))
+				return
+			}
+			if hasExpenses {
+				reply(ctx, bot, message, p.T("rate.base_has_expenses", base, code, trashDays()))
+				return
+			}
+		}
+
+		var text string
+		if command == "base" {
+			// Pinned rates are in the old base currency, so they no longer apply.
+			settings.BaseCurrency = code
+			settings.Rates = nil
+			text = p.T("rate.base_changed", code)
+		} else {
+			if _, ok := settings.Rates[code]; !ok {
+				reply(ctx, bot, message, p.T("rate.not_pinned", code))
+				return
+			}
+			delete(settings.Rates, code)
+			text = p.T("rate.removed", code)
+		}
+		if err := saveSettings(ctx, settings); err != nil {
+			reply(ctx, bot, message, failureText(ctx, err, p.T("rate.save_failed")))
+			return
+		}
+		reply(ctx, bot, message, text)
+		return
+	}
+
+	code, ok := currency.Parse(args[0])
+	if !ok {
+		reply(ctx, bot, message, p.T("rate.unknown_currency", args[0]))
+		return
+	}
+	if code == base {
+		reply(ctx, bot, message, p.T("rate.is_base", code))
+		return
+	}
+
+	if len(args) == 1 {
+		rate, err := userRates(settings).Rate(ctx, code, base)
+		if err != nil {
+			slog.WarnContext(ctx, "No exchange rate", "from", code, "to", base, "error", err)
+			reply(ctx, bot, message, p.T("rate.no_rate", code, base, code))
+			return
+		}
+		source := p.T("rate.source_cache")
+		if _, pinned := settings.Rates[code]; pinned {
+			source = p.T("rate.source_pinned")
+		}
+		reply(ctx, bot, message, p.T("rate.current", code, p.Number(rate, 4), base, source))
+		return
+	}
+
+	rate, err := parseAmount(args[1])
+	if err != nil || rate <= 0 {
+		reply(ctx, bot, message, p.T("rate.invalid_value"))
+		return
+	}
+	if settings.Rates == nil {
+		settings.Rates = make(map[string]float64)
+	}
+	settings.Rates[code] = rate
+	if err := saveSettings(ctx, settings); err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("rate.save_failed")))
+		return
+	}
+	reply(ctx, bot, message, p.T("rate.saved", code, p.Number(rate, 4), base))
+}
+
+// describeRates lists the base currency and the rates pinned by the user.
+func describeRates(p *i18n.Printer, settings *models.UserSettings) string {
+	base := baseCurrency(settings)
+
+	var text strings.Builder
+	text.WriteString(p.T("rate.header", base))
+	if len(settings.Rates) == 0 {
+		text.WriteString(p.T("rate.none_pinned"))
+	} else {
+		codes := make([]string, 0, len(settings.Rates))
+		for code := range settings.Rates {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+
+		text.WriteString(p.T("rate.pinned_header"))
+		for _, code := range codes {
+			text.WriteString(p.T("rate.pinned_item", code, p.Number(settings.Rates[code], 4), base))
+		}
+	}
+	text.WriteString("\n" + p.T("rate.help"))
+	return text.String()
+}
+
+// saveSettings stores the settings of the user, stamping when they changed.
+func saveSettings(ctx context.Context, settings *models.UserSettings) error {
+	settings.UpdatedAt = time.Now().UTC()
+	return database.SaveUserSettings(ctx, settings)
+}
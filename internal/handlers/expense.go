@@ -2,89 +2,266 @@ package handlers
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"errors"
+	"log/slog"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 
+	"money-telegram-bot/internal/classifier"
+	"money-telegram-bot/internal/currency"
 	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
 	"money-telegram-bot/internal/models"
+	"money-telegram-bot/internal/nfce"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func HandleExpense(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	log.Println("[INFO] Processing /gastei command")
-	log.Printf("[DEBUG] Raw input: %q", message.Text)
+func HandleExpense(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /gastei command")
+	p := i18n.FromContext(ctx)
+	text := commandText(message)
+	slog.DebugContext(ctx, "Raw input", "text", text)
 
-	parts := strings.Fields(message.Text)
+	input, err := parseExpenseInput(strings.Fields(text))
+	if errors.Is(err, errExpenseUsage) {
+		slog.ErrorContext(ctx, "Invalid command format. Expected: /gastei <amount> <category> [method]")
+		reply(ctx, bot, message, p.T("expense.usage"))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to parse amount value", "error", err)
+		reply(ctx, bot, message, p.T("expense.invalid_amount"))
+		return
+	}
 
-	if len(parts) < 3 {
-		log.Println("[ERROR] Invalid command format. Expected: /gastei <amount> <category> [method]")
-		reply(bot, message, "⚠️ Formato incorreto — use: /gastei <valor> <categoria> [método] | Exemplo: /gastei 21.90 uber pix")
+	slog.InfoContext(ctx, "Expense parsed successfully",
+		"amount", input.amount,
+		"description", input.description,
+		"method", input.method,
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	expense := input.expense(message.From, message.Chat.ID)
+	if len(message.Photo) > 0 {
+		expense.ReceiptFileID = largestPhoto(message.Photo).FileID
+	}
+	if input.currency != "" {
+		if err := convertExpense(ctx, expense, input.currency); err != nil {
+			slog.ErrorContext(ctx, "Failed to convert expense", "currency", input.currency, "error", err)
+			reply(ctx, bot, message, failureText(ctx, err, conversionFailureText(p, err, input.currency)))
+			return
+		}
+	}
+
+	keyboard, suggested, err := saveExpense(ctx, expense, input.installments)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save expense", "error", err)
+		reply(ctx, bot, message, failureText(ctx, err, p.T("error.save_expense")))
 		return
 	}
 
-	amountStr := parts[1]
-	description := parts[2]
+	reply(ctx, bot, message, p.T("expense.saving"))
+	// The expense is already saved: a cancelled ctx only cuts the pause short.
+	select {
+	case <-time.After(1 * time.Second):
+	case <-ctx.Done():
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, buildSavedExpenseText(p, expense, suggested))
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	send(ctx, bot, msg)
+}
+
+// errExpenseUsage is returned by parseExpenseInput when the amount or the
+// description is missing.
+var errExpenseUsage = errors.New("expense: missing amount or description")
+
+// expenseInput is an expense as typed by the user:
+// "<amount> [currency] <description> [method] [Nx]".
+type expenseInput struct {
+	amount       float64
+	description  string
+	method       string // classifier.UnknownMethod when not informed
+	currency     string // "" when paid in the base currency
+	installments int
+}
+
+// parseExpenseInput reads an expense from the fields of a message, the first
+// of which is the command and is ignored.
+func parseExpenseInput(fields []string) (expenseInput, error) {
+	parts, installments := extractInstallments(fields)
+	parts, paidIn := extractCurrency(parts)
+	if len(parts) < 3 {
+		return expenseInput{}, errExpenseUsage
+	}
 
-	method := "desconhecido"
+	amount, err := parseAmount(parts[1])
+	if err != nil {
+		return expenseInput{}, err
+	}
+
+	input := expenseInput{
+		amount:       amount,
+		description:  parts[2],
+		method:       classifier.UnknownMethod,
+		currency:     paidIn,
+		installments: installments,
+	}
 	if len(parts) >= 4 {
-		method = parts[3]
+		input.method = parts[3]
 	}
+	return input, nil
+}
+
+// expense returns the expense of input, logged by user in chatID. Its amount
+// is still in the currency it was paid in.
+func (input expenseInput) expense(user *tgbotapi.User, chatID int64) *models.Expense {
+	return &models.Expense{
+		UserID:      user.ID,
+		ChatID:      chatID,
+		Username:    user.UserName,
+		Amount:      input.amount,
+		Method:      input.method,
+		Description: input.description,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
 
-	amount, err := strconv.ParseFloat(amountStr, 64)
+// saveExpense stores the expense as the user typed it, with the description as
+// its category, recording how to undo it. It returns the keyboard of the
+// confirmation, with the categories and methods the user's history suggests
+// and the undo button, and whether it has suggestions.
+func saveExpense(ctx context.Context, expense *models.Expense, installments int) (*tgbotapi.InlineKeyboardMarkup, bool, error) {
+	history, err := database.GetUserExpenses(ctx, expense.UserID)
 	if err != nil {
-		log.Printf("[ERROR] Failed to parse amount value: %q | error=%v", amountStr, err)
-		reply(bot, message, "Valor inválido, exemplo: /gastei 21.74 uber pix")
-		return
+		slog.WarnContext(ctx, "Failed to load history for suggestions", "user_id", expense.UserID, "error", err)
+	}
+	model := classifier.Train(history)
+	categories := model.SuggestCategories(expense.Description, maxSuggestions)
+	methods := model.SuggestMethods(expense.Description, maxSuggestions)
+
+	expense.Category = expense.Description
+
+	if installments > 1 {
+		err = database.SaveInstallmentPurchase(ctx, expense, installments)
+	} else {
+		err = database.SaveExpense(ctx, expense)
 	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	p := i18n.FromContext(ctx)
+	actionID := recordUndo(ctx, &models.UndoEntry{UserID: expense.UserID, Action: models.UndoSave, SeqID: expense.SeqID, Created: []string{expense.ExpenseID}})
+	suggestions := buildSuggestionKeyboard(p, expense, categories, methods)
+	return withUndoButton(p, suggestions, expense.UserID, actionID), suggestions != nil, nil
+}
 
-	log.Printf(
-		"[INFO] Expense parsed successfully | amount=R$%.2f | category=%s | method=%s",
-		amount,
-		description,
-		method,
+// buildSavedExpenseText renders the confirmation shown after an expense is saved.
+func buildSavedExpenseText(p *i18n.Printer, expense *models.Expense, withSuggestions bool) string {
+	text := p.T("expense.saved",
+		expense.SeqID,
+		amountLabel(p, expense),
+		expense.Label(),
+		expense.Category,
+		methodLabel(p, expense.Method),
 	)
+	if expense.StoreCNPJ != "" {
+		text += p.T("expense.cnpj", nfce.FormatCNPJ(expense.StoreCNPJ))
+	}
+	if expense.IsForeign() {
+		text += p.T("expense.exchange", expense.Currency, p.Number(expense.ExchangeRate, 4), p.Currency())
+	}
+	if expense.IsInstallmentPurchase() {
+		text += p.T("expense.installments", expense.InstallmentCount, p.Money(expense.Amount/float64(expense.InstallmentCount)))
+	}
+	if withSuggestions {
+		text += p.T("expense.suggested")
+	}
+	return text
+}
 
-	user := message.From
-	expense := &models.Expense{
-		UserID:    user.ID,
-		ChatID:    message.Chat.ID,
-		Username:  user.UserName,
-		Amount:    amount,
-		Category:  description,
-		Method:    method,
-		CreatedAt: time.Now().UTC(),
+// methodLabel shows the method of an expense, translating the placeholder
+// stored when the method is unknown.
+func methodLabel(p *i18n.Printer, method string) string {
+	if method == classifier.UnknownMethod {
+		return p.T("common.unknown_method")
 	}
+	return method
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// commandText returns the text of a command message, which for photos sent
+// with a "/gastei ..." caption lives in the caption.
+func commandText(message *tgbotapi.Message) string {
+	if message.Text != "" {
+		return message.Text
+	}
+	return message.Caption
+}
 
-	if err := database.SaveExpense(ctx, expense); err != nil {
-		log.Printf("[ERROR] Failed to save expense: %v", err)
-		reply(bot, message, "❌ Erro ao salvar gasto. Tente novamente.")
-		return
+// errInvalidAmount is returned by parseAmount for amounts that are not a
+// positive number ("0", "-5", "NaN", "Inf").
+var errInvalidAmount = errors.New("expense: amount must be a positive number")
+
+// parseAmount accepts both "89.90" and the Brazilian "89,90" / "1.234,56".
+func parseAmount(value string) (float64, error) {
+	if strings.Contains(value, ",") {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
 	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if !(amount > 0) || math.IsInf(amount, 1) {
+		return 0, errInvalidAmount
+	}
+	return amount, nil
+}
 
-	awaitingServerResponse := "⏳ Registrando seu gasto..."
-	response := fmt.Sprintf(
-		"✅ Gasto registrado com sucesso!\n\n"+
-			"💰 Valor: R$%.2f\n"+
-			"📝 Descrição: %s\n"+
-			"💳 Método: %s",
-		amount,
-		description,
-		method,
-	)
+// extractCurrency removes the currency code typed right after the amount
+// ("/gastei 20 USD jantar"), returning "" when none was typed. A code that
+// would leave no description behind is read as the description.
+func extractCurrency(parts []string) ([]string, string) {
+	if len(parts) < 4 {
+		return parts, ""
+	}
+	code, ok := currency.Parse(parts[2])
+	if !ok {
+		return parts, ""
+	}
+	return append(append([]string{}, parts[:2]...), parts[3:]...), code
+}
 
-	reply(bot, message, awaitingServerResponse)
-	time.Sleep(1 * time.Second)
-	reply(bot, message, response)
+// maxInstallments is the largest "Nx" accepted by /gastei.
+const maxInstallments = 48
+
+// extractInstallments removes an "Nx" installments token (e.g. "12x") from the
+// command fields, returning 1 when the purchase is not split.
+func extractInstallments(parts []string) ([]string, int) {
+	for i := 2; i < len(parts); i++ {
+		token := strings.ToLower(parts[i])
+		if !strings.HasSuffix(token, "x") {
+			continue
+		}
+		count, err := strconv.Atoi(strings.TrimSuffix(token, "x"))
+		if err != nil || count < 1 || count > maxInstallments {
+			continue
+		}
+		rest := append(append([]string{}, parts[:i]...), parts[i+1:]...)
+		return rest, count
+	}
+	return parts, 1
 }
 
-func reply(bot *tgbotapi.BotAPI, message *tgbotapi.Message, text string) {
+func reply(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, text string) {
 	user := message.From
 
 	username := user.UserName
@@ -93,15 +270,14 @@ func reply(bot *tgbotapi.BotAPI, message *tgbotapi.Message, text string) {
 	}
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text)
-	if _, err := bot.Send(msg); err != nil {
-		log.Printf("[ERROR] Failed to send message | chatID=%d | userID=%d | error=%v", message.Chat.ID, user.ID, err)
+	if _, err := send(ctx, bot, msg); err != nil {
 		return
 	}
 
-	log.Printf(
-		"[INFO] Response sent | chatID=%d | userID=%d | userName=%s | status=success",
-		message.Chat.ID,
-		user.ID,
-		username,
+	slog.InfoContext(ctx, "Response sent",
+		"chat_id", message.Chat.ID,
+		"user_id", user.ID,
+		"user_name", username,
+		"status", "success",
 	)
-}
\ No newline at end of file
+}
@@ -1,45 +1,21 @@
 package handlers
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"strings"
+
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/render"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func HandleHelp(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	log.Println("[INFO] Processing /help command")
-
-	helpText := `🆘 *Ajuda — Comandos Disponíveis*
-
-🚀 *Comandos principais:*
-
-▶️ */start*  
-Inicia o bot e exibe a mensagem de boas-vindas.
-
-💸 */gastei <valor> <categoria> [método]*  
-Registra uma nova despesa.  
-Exemplo: /gastei 45.50 supermercado débito
-
-📋 */consulta*  
-Exibe todos os seus gastos com IDs em ordem (1, 2, 3...).
-
-📌 */consulta <ID>*  
-Ver detalhes de um gasto específico com navegação ⬅️ ➡️ entre registros.  
-Exemplo: /consulta 3
-
-🗑️ */deletar <ID>*  
-Deleta um gasto específico pelo ID (com confirmação).  
-Exemplo: /deletar 2
+func HandleHelp(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /help command")
 
-❌ */deletartudo*  
-Deleta *todos* os gastos registrados (com confirmação).
-
-💡 *Dica:* Os IDs são sequenciais (1, 2, 3...). Use /consulta para ver os IDs antes de deletar.
-
-🔙 Digite */start* para voltar ao menu inicial.`
-
-	msg := tgbotapi.NewMessage(message.Chat.ID, helpText)
-	msg.ParseMode = "Markdown"
+	msg := tgbotapi.NewMessage(message.Chat.ID, buildHelpText(i18n.FromContext(ctx)))
+	msg.ParseMode = render.ParseMode
 
 	user := message.From
 
@@ -53,12 +29,26 @@ Deleta *todos* os gastos registrados (com confirmação).
 		lastName = "-"
 	}
 
-	bot.Send(msg)
+	if _, err := send(ctx, bot, msg); err != nil {
+		return
+	}
 
-	log.Printf(
-		"[INFO] Response sent | chatID=%d | userID=%d | userName=%s | command=/help | status=success",
-		message.Chat.ID,
-		user.ID,
-		username,
+	slog.InfoContext(ctx, "Response sent",
+		"chat_id", message.Chat.ID,
+		"user_id", user.ID,
+		"user_name", username,
+		"command", "help",
+		"status", "success",
 	)
 }
+
+// buildHelpText describes every command, with usage and examples.
+func buildHelpText(p *i18n.Printer) string {
+	var text strings.Builder
+	text.WriteString(p.Format("help.header"))
+	for _, command := range Commands() {
+		text.WriteString(command.Emoji + " " + p.Format("help."+command.Name) + "\n\n")
+	}
+	text.WriteString(p.Format("help.footer"))
+	return text.String()
+}
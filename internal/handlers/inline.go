@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"money-telegram-bot/internal/billing"
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+	"money-telegram-bot/internal/render"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Inline mode ("@MoneySaviorBot 25 café pix" in any chat) shows results built
+// from the user's own data, so answers are personal and never cached. The
+// expense is only logged when a result is chosen, which needs inline feedback
+// enabled in BotFather, and by default the message posted in the chat says
+// that an expense was logged without saying which.
+
+const (
+	// inlineResultExpense posts a message without the amount or description.
+	inlineResultExpense = "expense"
+	// inlineResultExpenseShared posts the details of the expense.
+	inlineResultExpenseShared = "expense:share"
+	// inlineResultSummary posts the summary of the month.
+	inlineResultSummary = "summary"
+	// inlineSummaryCategories is how many categories the summary lists.
+	inlineSummaryCategories = 3
+)
+
+// inlineSummaryWords are the queries answered with the summary of the month.
+var inlineSummaryWords = map[string]bool{"resumo": true, "summary": true, "resumen": true}
+
+// HandleInlineQuery answers "@bot <expense>" with the results that log it, and
+// "@bot resumo" with the summary of the month. Anything else gets a hint.
+func HandleInlineQuery(ctx context.Context, bot *tgbotapi.BotAPI, query *tgbotapi.InlineQuery) {
+	p := i18n.FromContext(ctx)
+	text := strings.TrimSpace(query.Query)
+
+	var results []any
+	switch {
+	case text == "":
+	case inlineSummaryWords[strings.ToLower(text)]:
+		result, err := inlineSummary(ctx, p, query.From.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to build inline summary", "error", err)
+			break
+		}
+		results = append(results, result)
+	default:
+		input, err := parseInlineExpense(text)
+		if err != nil {
+			break
+		}
+		results = inlineExpenseResults(p, bot, input)
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID:     query.ID,
+		Results:           results,
+		IsPersonal:        true,
+		CacheTime:         0,
+		SwitchPMText:      p.T("inline.hint"),
+		SwitchPMParameter: "inline",
+	}
+	if err := request(ctx, bot, answer); err != nil {
+		return
+	}
+	slog.InfoContext(ctx, "Inline query answered", "user_id", query.From.ID, "results", len(results))
+}
+
+// parseInlineExpense reads an inline query, which is written like the
+// arguments of /gastei: "25 café pix", "20 USD jantar", "1200 notebook nubank 12x".
+func parseInlineExpense(query string) (expenseInput, error) {
+	return parseExpenseInput(append([]string{""}, strings.Fields(query)...))
+}
+
+// inlineExpenseResults offers to log input: first without sharing anything but
+// the fact that an expense was logged, then with its details.
+func inlineExpenseResults(p *i18n.Printer, bot *tgbotapi.BotAPI, input expenseInput) []any {
+	code := input.currency
+	if code == "" {
+		code = p.Currency()
+	}
+	amount := p.Amount(input.amount, code)
+
+	// The button makes Telegram report the id of the posted message, so it can
+	// be edited once the expense is saved.
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonURL(p.T("inline.open_button"), "https://t.me/"+bot.Self.UserName),
+	))
+
+	private := tgbotapi.NewInlineQueryResultArticle(inlineResultExpense, p.T("inline.expense_title", amount), p.T("inline.expense_pending"))
+	private.Description = p.T("inline.expense_description", input.description, methodLabel(p, input.method))
+	private.ReplyMarkup = &keyboard
+
+	shared := tgbotapi.NewInlineQueryResultArticle(inlineResultExpenseShared, p.T("inline.expense_share_title"), p.T("inline.expense_pending"))
+	shared.Description = p.T("inline.expense_share_description")
+	shared.ReplyMarkup = &keyboard
+
+	return []any{private, shared}
+}
+
+// inlineSummary builds the summary card of the current month: total, count and
+// the largest categories.
+func inlineSummary(ctx context.Context, p *i18n.Printer, userID int64) (tgbotapi.InlineQueryResultArticle, error) {
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	expenses, err := database.GetBillableExpenses(ctx, userID)
+	if err != nil {
+		return tgbotapi.InlineQueryResultArticle{}, err
+	}
+
+	now := time.Now().In(billing.Location)
+	month := p.T("chart.month_title", p.Month(now.Month()), now.Year())
+	var inMonth []models.Expense
+	var total float64
+	for _, expense := range expenses {
+		created := expense.CreatedAt.In(billing.Location)
+		if created.Year() == now.Year() && created.Month() == now.Month() {
+			inMonth = append(inMonth, expense)
+			total += expense.Amount
+		}
+	}
+
+	title := p.T("inline.summary_title", month)
+	if len(inMonth) == 0 {
+		return tgbotapi.NewInlineQueryResultArticleHTML(inlineResultSummary, title, render.Sprintf(p.Format("inline.summary_empty"), month)), nil
+	}
+
+	var card strings.Builder
+	card.WriteString(render.Sprintf(p.Format("inline.summary"), month, p.Money(total), len(inMonth)))
+	card.WriteString(p.T("inline.summary_categories"))
+	labels, values := categoryTotals(p, inMonth, inlineSummaryCategories+1)
+	for i, label := range labels {
+		card.WriteString(render.Sprintf(p.Format("inline.summary_item"), label, p.Money(values[i]), p.Percent(values[i]/total)))
+	}
+
+	result := tgbotapi.NewInlineQueryResultArticleHTML(inlineResultSummary, title, card.String())
+	result.Description = p.T("inline.summary_description", p.Money(total), len(inMonth))
+	return result, nil
+}
+
+// HandleChosenInlineResult logs the expense of a chosen inline result and
+// edits the posted message to say how it went.
+func HandleChosenInlineResult(ctx context.Context, bot *tgbotapi.BotAPI, chosen *tgbotapi.ChosenInlineResult) {
+	if chosen.ResultID != inlineResultExpense && chosen.ResultID != inlineResultExpenseShared {
+		return
+	}
+	slog.InfoContext(ctx, "Processing inline expense", "user_id", chosen.From.ID)
+	p := i18n.FromContext(ctx)
+
+	text := p.T("inline.expense_failed")
+	if expense, err := saveInlineExpense(ctx, chosen); err != nil {
+		slog.ErrorContext(ctx, "Failed to save inline expense", "error", err)
+		text = failureText(ctx, err, text)
+	} else if chosen.ResultID == inlineResultExpenseShared {
+		text = buildSavedExpenseText(p, expense, false)
+	} else {
+		text = p.T("inline.expense_saved")
+	}
+
+	if chosen.InlineMessageID == "" {
+		return
+	}
+	// Edits of inline messages return true instead of a message.
+	request(ctx, bot, tgbotapi.EditMessageTextConfig{
+		BaseEdit: tgbotapi.BaseEdit{InlineMessageID: chosen.InlineMessageID},
+		Text:     text,
+	})
+}
+
+// saveInlineExpense parses the query of a chosen result again and saves its
+// expense in the user's private chat with the bot.
+func saveInlineExpense(ctx context.Context, chosen *tgbotapi.ChosenInlineResult) (*models.Expense, error) {
+	input, err := parseInlineExpense(chosen.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	expense := input.expense(chosen.From, chosen.From.ID)
+	if input.currency != "" {
+		if err := convertExpense(ctx, expense, input.currency); err != nil {
+			return nil, err
+		}
+	}
+	if _, _, err := saveExpense(ctx, expense, input.installments); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "Inline expense saved", "user_id", expense.UserID, "seq_id", expense.SeqID)
+	return expense, nil
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"money-telegram-bot/internal/classifier"
+	"money-telegram-bot/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestParseInlineExpense(t *testing.T) {
+	tests := []struct {
+		query string
+		want  expenseInput
+	}{
+		{"25 café pix", expenseInput{amount: 25, description: "café", method: "pix", installments: 1}},
+		{"  25   café  ", expenseInput{amount: 25, description: "café", method: classifier.UnknownMethod, installments: 1}},
+		{"25,50 padaria", expenseInput{amount: 25.5, description: "padaria", method: classifier.UnknownMethod, installments: 1}},
+		{"1.234,56 mercado nubank", expenseInput{amount: 1234.56, description: "mercado", method: "nubank", installments: 1}},
+		{"89.90 farmácia débito", expenseInput{amount: 89.9, description: "farmácia", method: "débito", installments: 1}},
+		{"20 USD jantar", expenseInput{amount: 20, description: "jantar", method: classifier.UnknownMethod, currency: "USD", installments: 1}},
+		{"20 eur jantar itau", expenseInput{amount: 20, description: "jantar", method: "itau", currency: "EUR", installments: 1}},
+		// A code with nothing after it is the description.
+		{"20 usd", expenseInput{amount: 20, description: "usd", method: classifier.UnknownMethod, installments: 1}},
+		{"1200 notebook nubank 12x", expenseInput{amount: 1200, description: "notebook", method: "nubank", installments: 12}},
+		{"1200 12X notebook nubank", expenseInput{amount: 1200, description: "notebook", method: "nubank", installments: 12}},
+		{"300 USD passagem nubank 3x", expenseInput{amount: 300, description: "passagem", method: "nubank", currency: "USD", installments: 3}},
+		// Too many installments is not an installments token.
+		{"100 tv 99x", expenseInput{amount: 100, description: "tv", method: "99x", installments: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := parseInlineExpense(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("parseInlineExpense(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInlineExpenseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  error // nil for any parse error
+	}{
+		{"", errExpenseUsage},
+		{"25", errExpenseUsage},
+		{"resumo", errExpenseUsage},
+		{"12x", errExpenseUsage},
+		{"café 25", nil},
+		{"R$25 café", nil},
+		{"0 café", errInvalidAmount},
+		{"-5 café", errInvalidAmount},
+		{"NaN café", errInvalidAmount},
+		{"Inf café", errInvalidAmount},
+		{"1e400 café", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := parseInlineExpense(tt.query)
+			if err == nil || (tt.want != nil && !errors.Is(err, tt.want)) {
+				t.Errorf("parseInlineExpense(%q) = %v, want %v", tt.query, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestInlineExpenseResults(t *testing.T) {
+	p := i18n.New(i18n.Portuguese)
+	bot := &tgbotapi.BotAPI{Self: tgbotapi.User{UserName: "MoneySaviorBot"}}
+
+	tests := []struct {
+		query  string
+		amount string // as shown in the private result's title
+		method string
+	}{
+		{"25 café pix", p.Amount(25, p.Currency()), "pix"},
+		{"20 USD jantar", p.Amount(20, "USD"), p.T("common.unknown_method")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			input, err := parseInlineExpense(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results := inlineExpenseResults(p, bot, input)
+			if len(results) != 2 {
+				t.Fatalf("got %d results, want the private and the shared one", len(results))
+			}
+
+			private := results[0].(tgbotapi.InlineQueryResultArticle)
+			if private.ID != inlineResultExpense || !strings.Contains(private.Title, tt.amount) || !strings.Contains(private.Description, tt.method) {
+				t.Errorf("private result = %q / %q, want %s and %s", private.Title, private.Description, tt.amount, tt.method)
+			}
+			// Only the user sees titles and descriptions; the posted message
+			// must not carry the amount nor the description.
+			for _, result := range results {
+				article := result.(tgbotapi.InlineQueryResultArticle)
+				posted := article.InputMessageContent.(tgbotapi.InputTextMessageContent).Text
+				if strings.Contains(posted, input.description) || strings.Contains(posted, tt.amount) {
+					t.Errorf("result %s posts %q", article.ID, posted)
+				}
+				if article.ReplyMarkup == nil {
+					t.Errorf("result %s has no button, so its message could not be edited", article.ID)
+				}
+			}
+		})
+	}
+}
@@ -1,27 +1,64 @@
 package handlers
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/render"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func HandleInvalidCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	log.Printf("[WARN] Invalid command received: /%s", message.Command())
+const (
+	// maxCommandDistance is the largest edit distance at which a registered
+	// command is suggested for a mistyped one.
+	maxCommandDistance = 3
+	// maxButtonArguments is how many characters of the arguments the run
+	// button shows.
+	maxButtonArguments = 24
+)
 
-	errorText := fmt.Sprintf(
-		`❌ *Comando não reconhecido*
+// commandMatch is a registered command and its edit distance to a typed one.
+type commandMatch struct {
+	command  Command
+	distance int
+}
 
-O comando *%s* não existe no *Money Savior* 😕  
+// HandleInvalidCommand answers a command that is not registered, suggesting
+// the registered ones it is likely a typo of. For a likely typo it also offers
+// a button that runs the intended command with the same arguments.
+func HandleInvalidCommand(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.WarnContext(ctx, "Invalid command received", "command", message.Command())
+	p := i18n.FromContext(ctx)
 
-📋 Para ver a lista de comandos disponíveis, digite */help*.
+	errorText := render.Sprintf(p.Format("invalid.command"), message.Command())
 
-💡 Dica: confira se o comando foi digitado corretamente.`,
-		message.Command(),
-	)
+	matches := similarCommands(message.Command())
+	if len(matches) == 0 {
+		errorText += p.T("invalid.tip")
+	} else {
+		names := make([]string, len(matches))
+		for i, match := range matches {
+			names[i] = "/" + match.command.Name
+		}
+		errorText += render.Sprintf(p.Format("invalid.did_you_mean"), strings.Join(names, p.T("invalid.or")))
+	}
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, errorText)
+	msg.ParseMode = render.ParseMode
+	if len(matches) > 0 && isLikelyTypo(message.Command(), matches[0].distance) {
+		// The button reads the arguments from the message it replies to.
+		name := matches[0].command.Name
+		msg.ReplyToMessageID = message.MessageID
+		msg.AllowSendingWithoutReply = true
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.T("invalid.run_button", commandPreview(name, message.CommandArguments())), "run:"+name),
+		))
+	}
 
 	user := message.From
 
@@ -35,12 +72,128 @@ O comando *%s* não existe no *Money Savior* 😕
 		lastName = "-"
 	}
 
-	bot.Send(msg)
+	if _, err := send(ctx, bot, msg); err != nil {
+		return
+	}
 
-	log.Printf(
-		"[INFO] Response sent | chatID=%d | userID=%d | userName=%s | status=success",
-		message.Chat.ID,
-		user.ID,
-		username,
+	slog.InfoContext(ctx, "Response sent",
+		"chat_id", message.Chat.ID,
+		"user_id", user.ID,
+		"user_name", username,
+		"status", "success",
 	)
 }
+
+// HandleRunCommandCallback handles the button offered for a mistyped command:
+// it runs the suggested command with the arguments of the mistyped message.
+func HandleRunCommandCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+	p := i18n.FromContext(ctx)
+
+	// data format: "run:<command>"
+	command, ok := LookupCommand(strings.TrimPrefix(callback.Data, "run:"))
+	original := callback.Message.ReplyToMessage
+	if !ok || original == nil || !original.IsCommand() {
+		answerCallback(ctx, bot, callback, p.T("invalid.run_expired"))
+		return
+	}
+	// In groups anyone can press the button, but only the author of the
+	// command may run it as themselves.
+	if original.From == nil || original.From.ID != callback.From.ID {
+		answerCallback(ctx, bot, callback, p.T("invalid.run_not_yours"))
+		return
+	}
+	answerCallback(ctx, bot, callback, "")
+
+	edit := tgbotapi.NewEditMessageReplyMarkup(callback.Message.Chat.ID, callback.Message.MessageID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	})
+	send(ctx, bot, edit)
+
+	slog.InfoContext(ctx, "Running suggested command", "typed", original.Command(), "command", command.Name)
+	command.Handle(ctx, bot, retypeCommand(original, command.Name))
+}
+
+// similarCommands returns up to maxSuggestions registered commands close to
+// name, the closest first. Ties keep the order of the registry.
+func similarCommands(name string) []commandMatch {
+	name = strings.ToLower(name)
+	limit := min(maxCommandDistance, max(1, utf8.RuneCountInString(name)/2))
+
+	var matches []commandMatch
+	for _, command := range commands {
+		if distance := editDistance(name, command.Name); distance <= limit {
+			matches = append(matches, commandMatch{command, distance})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	return matches
+}
+
+// isLikelyTypo reports whether a command typed as name is, at distance, most
+// likely a typo rather than a different command: one slip in a short name,
+// two in a longer one.
+func isLikelyTypo(name string, distance int) bool {
+	if utf8.RuneCountInString(name) <= 4 {
+		return distance <= 1
+	}
+	return distance <= 2
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// commandPreview shows a command with its arguments, shortened to fit a button.
+func commandPreview(name, arguments string) string {
+	preview := "/" + name
+	arguments = strings.Join(strings.Fields(arguments), " ")
+	if arguments == "" {
+		return preview
+	}
+	if runes := []rune(arguments); len(runes) > maxButtonArguments {
+		arguments = string(runes[:maxButtonArguments]) + "…"
+	}
+	return preview + " " + arguments
+}
+
+// retypeCommand returns a copy of message as if name had been typed instead of
+// its command, keeping the arguments.
+func retypeCommand(message *tgbotapi.Message, name string) *tgbotapi.Message {
+	retyped := *message
+	retyped.Text = "/" + name
+	if arguments := message.CommandArguments(); arguments != "" {
+		retyped.Text += " " + arguments
+	}
+	retyped.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(name) + 1}}
+	return &retyped
+}
+
+// HandleUnknownCallback answers a button press the bot no longer understands,
+// for example from a keyboard sent by an older version.
+func HandleUnknownCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+	slog.WarnContext(ctx, "Unknown callback received", "data", callback.Data)
+	answerCallback(ctx, bot, callback, "")
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"gastei", "gastei", 0},
+		{"", "fatura", 6},
+		{"gaste", "gastei", 1},
+		{"gatsei", "gastei", 2}, // a transposition is two edits
+		{"consutla", "consulta", 2},
+		// Accents count per letter, not per byte.
+		{"cotação", "cotacao", 2},
+		{"gráfico", "grafico", 1},
+		{"métódo", "metodo", 2},
+		{"💸", "$", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := editDistance(tt.a, tt.b); got != tt.want {
+				t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := editDistance(tt.b, tt.a); got != tt.want {
+				t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarCommands(t *testing.T) {
+	tests := []struct {
+		typed string
+		want  []string // closest first
+		typo  bool     // whether the first one gets the run button
+	}{
+		{"gasteu", []string{"gastei"}, true},
+		{"GASTEI", []string{"gastei"}, true},
+		{"cotação", []string{"cotacao"}, true},
+		{"gráfico", []string{"grafico"}, true},
+		{"deleta", []string{"deletar"}, true},
+		{"deletartud", []string{"deletartudo", "deletar"}, true},
+		// Short names allow a single slip: "hepl" is two edits from "help".
+		{"hepl", []string{"help"}, false},
+		{"hel", []string{"help"}, true},
+		// Three letters allow one edit, so "gas" is too far from "gastei".
+		{"gas", nil, false},
+		// Long names stop at maxCommandDistance.
+		{"consultas", []string{"consulta"}, true},
+		{"xonsutlas", nil, false},
+		{"pizza", nil, false},
+		{"", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typed, func(t *testing.T) {
+			matches := similarCommands(tt.typed)
+			var got []string
+			for _, match := range matches {
+				got = append(got, match.command.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("similarCommands(%q) = %v, want %v", tt.typed, got, tt.want)
+			}
+			if len(matches) > 0 {
+				if typo := isLikelyTypo(tt.typed, matches[0].distance); typo != tt.typo {
+					t.Errorf("isLikelyTypo(%q, %d) = %v, want %v", tt.typed, matches[0].distance, typo, tt.typo)
+				}
+			}
+		})
+	}
+}
+
+func TestIsLikelyTypo(t *testing.T) {
+	tests := []struct {
+		name     string
+		distance int
+		want     bool
+	}{
+		{"hepl", 1, true},
+		{"hepl", 2, false},
+		{"fatrua", 2, true},
+		{"fatrua", 3, false},
+		// Four runes, even if more bytes.
+		{"ajdá", 1, true},
+		{"ajdá", 2, false},
+		{"cotaçao", 2, true},
+	}
+
+	for _, tt := range tests {
+		if got := isLikelyTypo(tt.name, tt.distance); got != tt.want {
+			t.Errorf("isLikelyTypo(%q, %d) = %v, want %v", tt.name, tt.distance, got, tt.want)
+		}
+	}
+}
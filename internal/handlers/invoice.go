@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"money-telegram-bot/internal/billing"
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ReminderDaysBefore is how many days before the due date the invoice reminder is sent.
+const ReminderDaysBefore = 3
+
+// HandleInvoice handles /fatura <cartão> — shows the open invoice of a credit card.
+func HandleInvoice(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /fatura command", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+	p := i18n.FromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	methods, err := database.GetPaymentMethods(ctx, message.From.ID)
+	if err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("invoice.cards_failed")))
+		return
+	}
+
+	var cards []models.PaymentMethod
+	for _, method := range methods {
+		if method.IsCredit() {
+			cards = append(cards, method)
+		}
+	}
+
+	if len(cards) == 0 {
+		reply(ctx, bot, message, p.T("invoice.no_cards"))
+		return
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	var card *models.PaymentMethod
+	switch {
+	case len(args) == 1:
+		for i := range cards {
+			if cards[i].Matches(args[0]) {
+				card = &cards[i]
+			}
+		}
+		if card == nil {
+			reply(ctx, bot, message, p.T("invoice.card_not_found", args[0]))
+			return
+		}
+	case len(args) == 0 && len(cards) == 1:
+		card = &cards[0]
+	default:
+		names := make([]string, len(cards))
+		for i := range cards {
+			names[i] = cards[i].Name
+		}
+		reply(ctx, bot, message, p.T("invoice.usage", strings.Join(names, ", ")))
+		return
+	}
+
+	expenses, err := database.GetBillableExpenses(ctx, message.From.ID)
+	if err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("error.query_expenses")))
+		return
+	}
+
+	invoice := billing.Open(card, expenses, time.Now())
+	text := buildInvoiceText(p, &invoice, p.T("invoice.open_title"))
+
+	// Future installments already land in the invoices of the months they fall in.
+	var upcoming strings.Builder
+	for _, next := range billing.Group(card, expenses) {
+		if next.Closing.After(invoice.Closing) {
+			upcoming.WriteString(p.T("invoice.upcoming_item", p.Month(next.Due.Month()), next.Due.Year(), p.Money(next.Total)))
+		}
+	}
+	if upcoming.Len() > 0 {
+		text += p.T("invoice.upcoming") + upcoming.String()
+	}
+
+	reply(ctx, bot, message, text)
+}
+
+func buildInvoiceText(p *i18n.Printer, invoice *billing.Invoice, title string) string {
+	var text strings.Builder
+	text.WriteString(p.T("invoice.summary",
+		title,
+		invoice.Card,
+		p.Money(invoice.Total),
+		p.Date(invoice.Closing),
+		p.Date(invoice.Due),
+		len(invoice.Expenses),
+	))
+
+	if len(invoice.Expenses) > 0 {
+		text.WriteString("\n")
+	}
+	for _, expense := range invoice.Expenses {
+		text.WriteString(p.T("invoice.item",
+			p.ShortDate(expense.CreatedAt.In(billing.Location)),
+			amountLabel(p, &expense),
+			expense.Label(),
+		))
+		if expense.IsInstallment() {
+			text.WriteString(p.T("invoice.item_installment", expense.Installment, expense.InstallmentCount))
+		}
+	}
+	return text.String()
+}
+
+// SendInvoiceReminders notifies every user whose credit card invoice is due in
+// ReminderDaysBefore days. It is meant to run once a day.
+func SendInvoiceReminders(ctx context.Context, bot *tgbotapi.BotAPI, now time.Time) error {
+	cards, err := database.GetAllCreditCards(ctx)
+	if err != nil {
+		return err
+	}
+
+	dueDay := now.In(billing.Location).AddDate(0, 0, ReminderDaysBefore)
+	sent := 0
+	for i := range cards {
+		card := &cards[i]
+		closing, ok := billing.InvoiceDueOn(card, dueDay)
+		if !ok {
+			continue
+		}
+
+		expenses, err := database.GetBillableExpenses(ctx, card.UserID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to load expenses for reminder", "user_id", card.UserID, "card", card.Name, "error", err)
+			continue
+		}
+
+		reminded, err := sendInvoiceReminder(ctx, bot, card, billing.Find(card, expenses, closing))
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to send invoice reminder", "user_id", card.UserID, "card", card.Name, "error", err)
+			continue
+		}
+		if reminded {
+			sent++
+		}
+	}
+
+	slog.InfoContext(ctx, "Invoice reminders sent", "cards", len(cards), "sent", sent)
+	return nil
+}
+
+// sendInvoiceReminder reminds the owner of card of invoice, unless it is empty.
+// Reminders go to the user's private chat, whose ID is the user ID, never to
+// the chat /metodo was typed in: in a group everyone would see the invoice.
+func sendInvoiceReminder(ctx context.Context, bot *tgbotapi.BotAPI, card *models.PaymentMethod, invoice billing.Invoice) (bool, error) {
+	if invoice.Total == 0 {
+		return false, nil
+	}
+	// Reminders run outside any update, so the language and currency come from the settings.
+	p := userPrinter(ctx, card.UserID, "")
+	title := p.T("invoice.reminder_title", ReminderDaysBefore)
+	if _, err := send(ctx, bot, tgbotapi.NewMessage(card.UserID, buildInvoiceText(p, &invoice, title))); err != nil {
+		return false, err
+	}
+	return true, nil
+}
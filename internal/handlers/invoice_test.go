@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"money-telegram-bot/internal/billing"
+	"money-telegram-bot/internal/models"
+)
+
+func TestInvoiceReminderGoesToPrivateChat(t *testing.T) {
+	api := &botAPI{replies: []string{sentReply}}
+	bot := newTestBot(t, api)
+	card := &models.PaymentMethod{UserID: 42, Name: "nubank", Type: models.MethodCredit, ClosingDay: 10, DueDay: 17}
+	closing := time.Date(2025, time.March, 10, 0, 0, 0, 0, billing.Location)
+
+	empty := billing.Find(card, nil, closing)
+	if sent, err := sendInvoiceReminder(context.Background(), bot, card, empty); sent || err != nil {
+		t.Errorf("empty invoice: sent = %v, err = %v; want nothing sent", sent, err)
+	}
+
+	expenses := []models.Expense{{UserID: 42, ChatID: -100123, Method: "nubank", Amount: 50, CreatedAt: time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)}}
+	invoice := billing.Find(card, expenses, closing)
+	if sent, err := sendInvoiceReminder(context.Background(), bot, card, invoice); !sent || err != nil {
+		t.Fatalf("sent = %v, err = %v", sent, err)
+	}
+	if len(api.chatIDs) != 1 || api.chatIDs[0] != "42" {
+		t.Errorf("reminder sent to chats %v, want the user's private chat 42", api.chatIDs)
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// settingsLookupTimeout bounds the settings read made for every update; a
+// slow read costs the user their preferences, not their reply.
+const settingsLookupTimeout = 2 * time.Second
+
+// WithUserSettings returns a copy of ctx whose messages are in the language of
+// user (the one chosen with /idioma, else the language of their Telegram app)
+// and whose amounts are in their base currency.
+func WithUserSettings(ctx context.Context, user *tgbotapi.User) context.Context {
+	if user == nil {
+		return i18n.NewContext(ctx, i18n.New(i18n.Default))
+	}
+	return i18n.NewContext(ctx, userPrinter(ctx, user.ID, user.LanguageCode))
+}
+
+// userPrinter returns the Printer of the user's stored settings. Without
+// settings, or when they can't be read, the language comes from fallback (a
+// Telegram language_code) and amounts are in the default currency.
+func userPrinter(ctx context.Context, userID int64, fallback string) *i18n.Printer {
+	ctx, cancel := context.WithTimeout(ctx, settingsLookupTimeout)
+	defer cancel()
+
+	settings, err := database.GetUserSettings(ctx, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load user settings", "user_id", userID, "error", err)
+		return i18n.New(i18n.FromCode(fallback))
+	}
+	lang, ok := i18n.Parse(settings.Language)
+	if !ok {
+		lang = i18n.FromCode(fallback)
+	}
+	return i18n.New(lang).WithCurrency(baseCurrency(settings))
+}
+
+// HandleLanguage handles /idioma [pt|en|es] — changes the language of the
+// messages, or offers a button per language when no argument is given.
+func HandleLanguage(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /idioma command", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+	p := i18n.FromContext(ctx)
+
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		var rows [][]tgbotapi.InlineKeyboardButton
+		for _, lang := range i18n.Languages {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(i18n.New(lang).Name(), "lang:"+string(lang)),
+			))
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, p.T("language.choose", p.Name()))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		send(ctx, bot, msg)
+		return
+	}
+
+	lang, ok := i18n.Parse(arg)
+	if !ok {
+		reply(ctx, bot, message, p.T("language.invalid", arg))
+		return
+	}
+
+	if err := saveLanguage(ctx, message.From.ID, lang); err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("language.save_failed")))
+		return
+	}
+
+	p = i18n.New(lang).WithCurrency(p.Currency())
+	reply(ctx, bot, message, p.T("language.changed", p.Name()))
+}
+
+// HandleLanguageCallback handles the buttons of /idioma.
+func HandleLanguageCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	p := i18n.FromContext(ctx)
+
+	// data format: "lang:<tag>"
+	lang, ok := i18n.Parse(strings.TrimPrefix(callback.Data, "lang:"))
+	if !ok {
+		answerCallback(ctx, bot, callback, "")
+		return
+	}
+
+	if err := saveLanguage(ctx, callback.From.ID, lang); err != nil {
+		answerCallback(ctx, bot, callback, callbackFailureText(ctx, err, p.T("language.save_failed")))
+		return
+	}
+	answerCallback(ctx, bot, callback, "")
+
+	p = i18n.New(lang).WithCurrency(p.Currency())
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, p.T("language.changed", p.Name()))
+	edit.ReplyMarkup = nil
+	send(ctx, bot, edit)
+}
+
+// saveLanguage stores lang in the settings of the user, keeping the others.
+func saveLanguage(ctx context.Context, userID int64, lang i18n.Lang) error {
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	settings, err := database.GetUserSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	settings.Language = string(lang)
+	if err := saveSettings(ctx, settings); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "User language changed", "user_id", userID, "language", lang)
+	return nil
+}
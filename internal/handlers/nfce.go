@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"money-telegram-bot/internal/billing"
+	"money-telegram-bot/internal/classifier"
+	"money-telegram-bot/internal/currency"
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+	"money-telegram-bot/internal/nfce"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// nfceFetcher downloads SEFAZ pages for QR codes that do not carry the total.
+var nfceFetcher nfce.Fetcher = nfce.NewHTTPFetcher()
+
+// SetNFCeFetcher replaces the fetcher used for SEFAZ consultation pages.
+func SetNFCeFetcher(fetcher nfce.Fetcher) {
+	nfceFetcher = fetcher
+}
+
+// HandleNFCeLink handles a pasted NFC-e consultation URL.
+func HandleNFCeLink(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing NFC-e link", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+
+	link, ok := nfce.FindURL(message.Text)
+	if !ok {
+		return
+	}
+	note, err := nfce.ParseURL(link)
+	if err != nil {
+		reply(ctx, bot, message, i18n.FromContext(ctx).T("nfce.invalid_link"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, nfceTimeout)
+	defer cancel()
+
+	importNFCe(ctx, bot, message, note, "")
+}
+
+// importNFCe registers the expense of an NFC-e, tagged with the store's CNPJ.
+// Receipts already imported are not registered again.
+func importNFCe(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, note *nfce.Note, receiptFileID string) {
+	p := i18n.FromContext(ctx)
+
+	expenses, err := database.GetUserExpenses(ctx, message.From.ID)
+	if err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("error.query_expenses")))
+		return
+	}
+	for _, expense := range expenses {
+		if expense.NFCeKey == note.AccessKey {
+			reply(ctx, bot, message, p.T("nfce.already_imported", expense.SeqID))
+			return
+		}
+	}
+
+	// The page gives the store name and date, and the total for online QR codes.
+	var page nfce.Page
+	if body, err := nfceFetcher.Fetch(ctx, note); err != nil {
+		slog.WarnContext(ctx, "Failed to fetch SEFAZ page", "key", note.AccessKey, "error", err)
+	} else {
+		page = nfce.ParsePage(body, billing.Location)
+	}
+
+	total := note.Total
+	if total == 0 {
+		total = page.Total
+	}
+	if total == 0 {
+		reply(ctx, bot, message, p.T("nfce.no_total"))
+		return
+	}
+
+	description := "nfce"
+	if page.Merchant != "" {
+		description = merchantDescription(page.Merchant)
+	}
+	createdAt := time.Now().UTC()
+	if !page.IssuedAt.IsZero() {
+		createdAt = page.IssuedAt.UTC()
+	}
+
+	expense := &models.Expense{
+		UserID:        message.From.ID,
+		ChatID:        message.Chat.ID,
+		Username:      message.From.UserName,
+		Amount:        total,
+		Method:        classifier.UnknownMethod,
+		Description:   description,
+		CreatedAt:     createdAt,
+		ReceiptFileID: receiptFileID,
+		StoreCNPJ:     note.CNPJ,
+		NFCeKey:       note.AccessKey,
+	}
+	// NFC-e are Brazilian, always in reais.
+	if err := convertExpense(ctx, expense, currency.Default); err != nil {
+		slog.ErrorContext(ctx, "Failed to convert NFC-e expense", "error", err)
+		reply(ctx, bot, message, failureText(ctx, err, conversionFailureText(p, err, currency.Default)))
+		return
+	}
+
+	keyboard, suggested, err := saveExpense(ctx, expense, 1)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save NFC-e expense", "error", err)
+		reply(ctx, bot, message, failureText(ctx, err, p.T("error.save_expense")))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, buildSavedExpenseText(p, expense, suggested))
+	msg.ReplyToMessageID = message.MessageID
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	send(ctx, bot, msg)
+	slog.InfoContext(ctx, "NFC-e imported", "user_id", message.From.ID, "seq_id", expense.SeqID, "cnpj", note.CNPJ)
+}
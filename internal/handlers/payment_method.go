@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var methodTypeAliases = strings.NewReplacer("é", "e", "É", "e")
+
+// maxMethodName caps the length of method names, which travel in the callback
+// data of suggestion buttons.
+const maxMethodName = 20
+
+// HandlePaymentMethod handles /metodo — lists, registers or removes payment methods.
+func HandlePaymentMethod(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /metodo command", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+
+	p := i18n.FromContext(ctx)
+	usage := p.T("method.usage", p.T("method.help"))
+	args := strings.Fields(message.CommandArguments())
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	if len(args) == 0 {
+		listPaymentMethods(ctx, bot, message)
+		return
+	}
+
+	if strings.EqualFold(args[0], "remover") {
+		if len(args) != 2 {
+			reply(ctx, bot, message, usage)
+			return
+		}
+		if _, err := database.GetPaymentMethod(ctx, message.From.ID, args[1]); err != nil {
+			reply(ctx, bot, message, failureText(ctx, err, p.T("method.not_found", args[1])))
+			return
+		}
+		if err := database.DeletePaymentMethod(ctx, message.From.ID, args[1]); err != nil {
+			reply(ctx, bot, message, failureText(ctx, err, p.T("method.remove_failed")))
+			return
+		}
+		reply(ctx, bot, message, p.T("method.removed", args[1]))
+		return
+	}
+
+	if len(args) < 2 {
+		reply(ctx, bot, message, usage)
+		return
+	}
+	if utf8.RuneCountInString(args[0]) > maxMethodName {
+		reply(ctx, bot, message, p.T("method.name_too_long", maxMethodName))
+		return
+	}
+
+	method := &models.PaymentMethod{
+		UserID:    message.From.ID,
+		Name:      strings.ToLower(args[0]),
+		Type:      strings.ToLower(methodTypeAliases.Replace(args[1])),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	switch method.Type {
+	case models.MethodCredit:
+		if len(args) != 4 {
+			reply(ctx, bot, message, usage)
+			return
+		}
+		closingDay, errClosing := strconv.Atoi(args[2])
+		dueDay, errDue := strconv.Atoi(args[3])
+		if errClosing != nil || errDue != nil || !validDay(closingDay) || !validDay(dueDay) {
+			reply(ctx, bot, message, p.T("method.invalid_days"))
+			return
+		}
+		method.ClosingDay = closingDay
+		method.DueDay = dueDay
+	case models.MethodDebit, models.MethodPix:
+		if len(args) > 3 {
+			reply(ctx, bot, message, usage)
+			return
+		}
+		method.AccountType = "corrente"
+		if len(args) == 3 {
+			method.AccountType = strings.ToLower(args[2])
+		}
+	case models.MethodCash:
+		if len(args) != 2 {
+			reply(ctx, bot, message, usage)
+			return
+		}
+	default:
+		reply(ctx, bot, message, usage)
+		return
+	}
+
+	if err := database.SavePaymentMethod(ctx, method); err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("method.save_failed")))
+		return
+	}
+
+	reply(ctx, bot, message, p.T("method.saved", describePaymentMethod(p, method), method.Name))
+}
+
+func listPaymentMethods(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	p := i18n.FromContext(ctx)
+	methods, err := database.GetPaymentMethods(ctx, message.From.ID)
+	if err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("method.query_failed")))
+		return
+	}
+
+	if len(methods) == 0 {
+		reply(ctx, bot, message, p.T("method.none", p.T("method.help")))
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString(p.T("method.list_header", len(methods)))
+	for i := range methods {
+		response.WriteString(describePaymentMethod(p, &methods[i]))
+		response.WriteString("\n")
+	}
+	response.WriteString(p.T("method.list_footer"))
+	reply(ctx, bot, message, response.String())
+}
+
+func describePaymentMethod(p *i18n.Printer, method *models.PaymentMethod) string {
+	switch method.Type {
+	case models.MethodCredit:
+		return p.T("method.credit", method.Name, method.ClosingDay, method.DueDay)
+	case models.MethodDebit:
+		return p.T("method.debit", method.Name, method.AccountType)
+	case models.MethodPix:
+		return p.T("method.pix", method.Name, method.AccountType)
+	default:
+		return p.T("method.other", method.Name, method.Type)
+	}
+}
+
+func validDay(day int) bool {
+	return day >= 1 && day <= 31
+}
@@ -3,19 +3,25 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
+	"time"
 
+	"money-telegram-bot/internal/billing"
 	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
 	"money-telegram-bot/internal/models"
+	"money-telegram-bot/internal/nfce"
+	"money-telegram-bot/internal/render"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // HandleQuery handles /consulta — lists all expenses or shows a specific one with navigation.
-func HandleQuery(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	log.Printf("[INFO] Processing /consulta command | chatID=%d | userID=%d", message.Chat.ID, message.From.ID)
+func HandleQuery(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /consulta command", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+	p := i18n.FromContext(ctx)
 
 	args := strings.Fields(message.CommandArguments())
 
@@ -23,108 +29,136 @@ func HandleQuery(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
 		// /consulta <id> — show single expense with navigation
 		seqID, err := strconv.Atoi(args[0])
 		if err != nil || seqID < 1 {
-			reply(bot, message, "❌ ID inválido. Use um número inteiro maior que zero.\nExemplo: /consulta 3")
+			reply(ctx, bot, message, p.T("query.invalid_id"))
 			return
 		}
-		sendExpenseView(bot, message.Chat.ID, message.From.ID, seqID, 0)
+		sendExpenseView(ctx, bot, message.Chat.ID, message.From.ID, seqID, 0)
 		return
 	}
 
 	// /consulta — list all expenses
-	expenses, err := database.GetUserExpenses(context.Background(), message.From.ID)
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	expenses, err := database.GetUserExpenses(ctx, message.From.ID)
 	if err != nil {
-		log.Printf("[ERROR] Failed to query expenses for userID=%d: %v", message.From.ID, err)
-		reply(bot, message, "❌ Ocorreu um erro ao consultar seus gastos. Tente novamente mais tarde.")
+		slog.ErrorContext(ctx, "Failed to query expenses", "user_id", message.From.ID, "error", err)
+		reply(ctx, bot, message, failureText(ctx, err, p.T("error.query_expenses")))
 		return
 	}
 
 	if len(expenses) == 0 {
-		reply(bot, message, "📝 Você ainda não registrou nenhum gasto.")
+		reply(ctx, bot, message, p.T("query.empty"))
 		return
 	}
 
-	var response strings.Builder
-	response.WriteString(fmt.Sprintf("📋 *Seus gastos (%d registros):*\n\n", len(expenses)))
-
-	for _, expense := range expenses {
-		response.WriteString(fmt.Sprintf(
-			"🆔 *#%d* | 💰 R$ %.2f | 📝 %s | 💳 %s\n",
-			expense.SeqID,
-			expense.Amount,
-			expense.Category,
-			expense.Method,
-		))
-	}
-
-	response.WriteString("\n💡 Use /consulta <ID> para ver detalhes de um gasto específico.\nExemplo: /consulta 2")
-
-	msg := tgbotapi.NewMessage(message.Chat.ID, response.String())
-	msg.ParseMode = "Markdown"
-	bot.Send(msg)
+	msg := tgbotapi.NewMessage(message.Chat.ID, buildExpenseList(p, expenses))
+	msg.ParseMode = render.ParseMode
+	send(ctx, bot, msg)
 }
 
 // sendExpenseView sends a single expense card with prev/next navigation buttons.
 // replyToMessageID is optional (0 = new message).
-func sendExpenseView(bot *tgbotapi.BotAPI, chatID int64, userID int64, seqID int, editMessageID int) {
-	expense, err := database.GetExpenseBySeqID(context.Background(), userID, seqID)
+func sendExpenseView(ctx context.Context, bot *tgbotapi.BotAPI, chatID int64, userID int64, seqID int, editMessageID int) {
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+	p := i18n.FromContext(ctx)
+
+	expense, err := database.GetExpenseBySeqID(ctx, userID, seqID)
 	if err != nil {
-		text := fmt.Sprintf("❌ Nenhum gasto encontrado com o ID *%d*.\nUse /consulta para ver a lista completa.", seqID)
+		text := failureText(ctx, err, p.T("query.not_found", seqID))
 		if editMessageID != 0 {
 			edit := tgbotapi.NewEditMessageText(chatID, editMessageID, text)
-			edit.ParseMode = "Markdown"
-			bot.Send(edit)
+			edit.ParseMode = render.ParseMode
+			send(ctx, bot, edit)
 		} else {
 			msg := tgbotapi.NewMessage(chatID, text)
-			msg.ParseMode = "Markdown"
-			bot.Send(msg)
+			msg.ParseMode = render.ParseMode
+			send(ctx, bot, msg)
 		}
 		return
 	}
 
-	total, _ := database.GetTotalExpenses(context.Background(), userID)
-	text := buildExpenseCard(expense, seqID, total)
-	keyboard := buildNavKeyboard(userID, seqID, total)
+	total, _ := database.GetTotalExpenses(ctx, userID)
+	text := buildExpenseCard(p, expense, seqID, total)
+	keyboard := buildNavKeyboard(p, userID, seqID, total, expense.ReceiptFileID != "")
 
 	if editMessageID != 0 {
 		edit := tgbotapi.NewEditMessageText(chatID, editMessageID, text)
-		edit.ParseMode = "Markdown"
+		edit.ParseMode = render.ParseMode
 		edit.ReplyMarkup = &keyboard
-		bot.Send(edit)
+		send(ctx, bot, edit)
 	} else {
 		msg := tgbotapi.NewMessage(chatID, text)
-		msg.ParseMode = "Markdown"
+		msg.ParseMode = render.ParseMode
 		msg.ReplyMarkup = keyboard
-		bot.Send(msg)
+		send(ctx, bot, msg)
 	}
 }
 
-func buildExpenseCard(expense *models.Expense, seqID int, total int) string {
-	return fmt.Sprintf(
-		"📄 *Gasto %d de %d*\n\n"+
-			"🆔 ID: *%d*\n"+
-			"💰 Valor: *R$ %.2f*\n"+
-			"📝 Categoria: *%s*\n"+
-			"💳 Método: *%s*\n"+
-			"🕐 Data: *%s*",
+// buildExpenseList renders the /consulta list of every expense.
+func buildExpenseList(p *i18n.Printer, expenses []models.Expense) string {
+	var response strings.Builder
+	response.WriteString(p.T("query.list_header", len(expenses)))
+	for _, expense := range expenses {
+		response.WriteString(render.Sprintf(p.Format("query.list_item"),
+			expense.SeqID,
+			amountLabel(p, &expense),
+			expense.Category,
+			methodLabel(p, expense.Method),
+			installmentNote(p, &expense, time.Now().In(billing.Location)),
+		))
+	}
+	response.WriteString(p.T("query.list_footer"))
+	return response.String()
+}
+
+func buildExpenseCard(p *i18n.Printer, expense *models.Expense, seqID int, total int) string {
+	card := render.Sprintf(p.Format("query.card"),
 		seqID, total,
 		expense.SeqID,
-		expense.Amount,
+		amountLabel(p, expense),
 		expense.Category,
-		expense.Method,
-		expense.CreatedAt.Format("02/01/2006 15:04"),
+		methodLabel(p, expense.Method),
+		p.DateTime(expense.CreatedAt),
 	)
+
+	if expense.IsForeign() {
+		card += render.Sprintf(p.Format("query.card_exchange"), expense.Currency, p.Number(expense.ExchangeRate, 4), p.Currency())
+	}
+
+	if expense.StoreCNPJ != "" {
+		card += render.Sprintf(p.Format("query.card_cnpj"), nfce.FormatCNPJ(expense.StoreCNPJ))
+	}
+	if expense.IsInstallmentPurchase() {
+		card += p.T("query.card_installments",
+			expense.InstallmentCount,
+			p.Money(expense.Amount/float64(expense.InstallmentCount)),
+			expense.CurrentInstallment(time.Now().In(billing.Location)),
+			expense.InstallmentCount,
+		)
+	}
+	return card
 }
 
-func buildNavKeyboard(userID int64, seqID int, total int) tgbotapi.InlineKeyboardMarkup {
+// installmentNote returns the " | 🧾 parcela N/M" suffix used in expense lists.
+func installmentNote(p *i18n.Printer, expense *models.Expense, now time.Time) string {
+	if !expense.IsInstallmentPurchase() {
+		return ""
+	}
+	return p.T("query.installment_note", expense.CurrentInstallment(now), expense.InstallmentCount)
+}
+
+func buildNavKeyboard(p *i18n.Printer, userID int64, seqID int, total int, hasReceipt bool) tgbotapi.InlineKeyboardMarkup {
 	var row []tgbotapi.InlineKeyboardButton
 
 	if seqID > 1 {
 		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
-			"⬅️ Anterior",
+			p.T("query.previous_button"),
 			fmt.Sprintf("qnav:%d:%d", userID, seqID-1),
 		))
 	} else {
-		row = append(row, tgbotapi.NewInlineKeyboardButtonData("⬅️ Anterior", "qnav_disabled"))
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(p.T("query.previous_button"), "qnav_disabled"))
 	}
 
 	row = append(row, tgbotapi.NewInlineKeyboardButtonData(
@@ -134,57 +168,56 @@ func buildNavKeyboard(userID int64, seqID int, total int) tgbotapi.InlineKeyboar
 
 	if seqID < total {
 		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
-			"Próximo ➡️",
+			p.T("query.next_button"),
 			fmt.Sprintf("qnav:%d:%d", userID, seqID+1),
 		))
 	} else {
-		row = append(row, tgbotapi.NewInlineKeyboardButtonData("Próximo ➡️", "qnav_disabled"))
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(p.T("query.next_button"), "qnav_disabled"))
 	}
 
 	deleteRow := tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData(
-			fmt.Sprintf("🗑️ Deletar #%d", seqID),
-			fmt.Sprintf("confirm_delete:%d", seqID),
+			p.T("query.delete_button", seqID),
+			fmt.Sprintf("confirm_delete:%d:%d", userID, seqID),
 		),
-		tgbotapi.NewInlineKeyboardButtonData("📋 Ver todos", "qnav_list"),
+		tgbotapi.NewInlineKeyboardButtonData(p.T("query.all_button"), "qnav_list"),
 	)
 
+	if hasReceipt {
+		receiptRow := tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.T("query.receipt_button"), fmt.Sprintf("receipt:%d:%d", userID, seqID)),
+		)
+		return tgbotapi.NewInlineKeyboardMarkup(row, receiptRow, deleteRow)
+	}
+
 	return tgbotapi.NewInlineKeyboardMarkup(row, deleteRow)
 }
 
 // HandleQueryCallback handles inline navigation callbacks for the expense viewer.
-func HandleQueryCallback(bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+func HandleQueryCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
 	chatID := callback.Message.Chat.ID
 	userID := callback.From.ID
 
 	switch {
 	case callback.Data == "qnav_disabled" || callback.Data == "qnav_info":
-		bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+		answerCallback(ctx, bot, callback, "")
 		return
 
 	case callback.Data == "qnav_list":
-		bot.Request(tgbotapi.NewCallback(callback.ID, ""))
-		expenses, err := database.GetUserExpenses(context.Background(), userID)
+		answerCallback(ctx, bot, callback, "")
+		ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+		defer cancel()
+
+		p := i18n.FromContext(ctx)
+		expenses, err := database.GetUserExpenses(ctx, userID)
 		if err != nil || len(expenses) == 0 {
-			edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, "📝 Nenhum gasto registrado.")
-			bot.Send(edit)
+			edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, failureText(ctx, err, p.T("query.empty_short")))
+			send(ctx, bot, edit)
 			return
 		}
-		var response strings.Builder
-		response.WriteString(fmt.Sprintf("📋 *Seus gastos (%d registros):*\n\n", len(expenses)))
-		for _, expense := range expenses {
-			response.WriteString(fmt.Sprintf(
-				"🆔 *#%d* | 💰 R$ %.2f | 📝 %s | 💳 %s\n",
-				expense.SeqID,
-				expense.Amount,
-				expense.Category,
-				expense.Method,
-			))
-		}
-		response.WriteString("\n💡 Use /consulta <ID> para ver detalhes.\nExemplo: /consulta 2")
-		edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, response.String())
-		edit.ParseMode = "Markdown"
-		bot.Send(edit)
+		edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, buildExpenseList(p, expenses))
+		edit.ParseMode = render.ParseMode
+		send(ctx, bot, edit)
 		return
 
 	default:
@@ -193,7 +226,7 @@ func HandleQueryCallback(bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery)
 		var seqID int
 		fmt.Sscanf(callback.Data, "qnav:%d:%d", &targetUserID, &seqID)
 
-		bot.Request(tgbotapi.NewCallback(callback.ID, ""))
-		sendExpenseView(bot, chatID, userID, seqID, callback.Message.MessageID)
+		answerCallback(ctx, bot, callback, "")
+		sendExpenseView(ctx, bot, chatID, userID, seqID, callback.Message.MessageID)
 	}
 }
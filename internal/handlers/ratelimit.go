@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"money-telegram-bot/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HandleRateLimited tells the user to slow down. The router calls it only on
+// the first refused message, so spamming does not get one reply per message.
+func HandleRateLimited(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, retryAfter time.Duration) {
+	p := i18n.FromContext(ctx)
+	reply(ctx, bot, message, p.T("ratelimit.message", describeWait(p, retryAfter)))
+}
+
+// HandleRateLimitedCallback answers a refused button press. Every press is
+// answered, otherwise Telegram keeps the button loading.
+func HandleRateLimitedCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery, retryAfter time.Duration) {
+	p := i18n.FromContext(ctx)
+	answerCallback(ctx, bot, callback, p.T("ratelimit.callback", describeWait(p, retryAfter)))
+}
+
+func describeWait(p *i18n.Printer, wait time.Duration) string {
+	seconds := int(math.Ceil(wait.Seconds()))
+	switch {
+	case seconds <= 1:
+		return p.T("wait.second")
+	case seconds < 60:
+		return p.T("wait.seconds", seconds)
+	case seconds < 120:
+		return p.T("wait.minute")
+	default:
+		return p.T("wait.minutes", (seconds+59)/60)
+	}
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"money-telegram-bot/internal/currency"
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+	"money-telegram-bot/internal/nfce"
+	"money-telegram-bot/internal/ocr"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// expenseIDPattern finds the expense ID in the bot messages that show one
+// (confirmation, expense card, delete prompt).
+var expenseIDPattern = regexp.MustCompile(`🆔 ID: (\d+)`)
+
+// HandlePhoto handles photo messages: a photo captioned "/gastei ..." registers
+// an expense with the receipt attached, and a photo sent as a reply to an
+// expense message attaches it to that expense.
+func HandlePhoto(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	caption := strings.TrimSpace(message.Caption)
+	if strings.HasPrefix(caption, "/gastei") {
+		HandleExpense(ctx, bot, message)
+		return
+	}
+
+	if owner, seqID, ok := repliedExpense(bot, message); ok {
+		if owner != message.From.ID {
+			reply(ctx, bot, message, i18n.FromContext(ctx).T("receipt.not_yours", seqID))
+			return
+		}
+		attachReceipt(ctx, bot, message, seqID)
+		return
+	}
+
+	proposeFromReceipt(ctx, bot, message)
+}
+
+// receiptOCR reads receipt photos sent without a caption.
+var receiptOCR ocr.Engine = ocr.FromEnv()
+
+// SetOCREngine replaces the engine used to read receipt photos.
+func SetOCREngine(engine ocr.Engine) {
+	receiptOCR = engine
+}
+
+// proposeFromReceipt imports the receipt when its NFC-e QR code can be read,
+// and otherwise runs OCR on the cupom fiscal and proposes the prefilled
+// expense for confirmation. Without an OCR engine it only explains how to
+// attach receipts.
+func proposeFromReceipt(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Reading receipt photo", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+	p := i18n.FromContext(ctx)
+	hint := p.T("receipt.hint")
+
+	ctx, cancel := context.WithTimeout(ctx, ocrTimeout)
+	defer cancel()
+
+	image, err := downloadFile(ctx, bot, largestPhoto(message.Photo).FileID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to download receipt photo", "chat_id", message.Chat.ID, "error", err)
+		reply(ctx, bot, message, failureText(ctx, err, hint))
+		return
+	}
+
+	if note, err := nfce.DecodePhoto(image); err == nil {
+		importNFCe(ctx, bot, message, note, largestPhoto(message.Photo).FileID)
+		return
+	}
+
+	receipt, draft, err := readReceipt(ctx, receiptOCR, image, message.From.ID)
+	switch {
+	case errors.Is(err, ocr.ErrUnavailable):
+		slog.DebugContext(ctx, "OCR unavailable, skipping receipt reading", "chat_id", message.Chat.ID)
+		reply(ctx, bot, message, hint)
+		return
+	case errors.Is(err, errNoTotal):
+		reply(ctx, bot, message, p.T("receipt.no_total", hint))
+		return
+	case err != nil:
+		slog.ErrorContext(ctx, "OCR failed", "chat_id", message.Chat.ID, "error", err)
+		reply(ctx, bot, message, failureText(ctx, err, p.T("receipt.read_failed", hint)))
+		return
+	}
+
+	var summary strings.Builder
+	summary.WriteString(p.T("receipt.read"))
+	summary.WriteString(p.T("receipt.total", p.Amount(receipt.Total, currency.Default)))
+	if receipt.Merchant != "" {
+		summary.WriteString(p.T("receipt.merchant", receipt.Merchant))
+	}
+	if !receipt.Date.IsZero() {
+		summary.WriteString(p.T("receipt.date", p.Date(receipt.Date)))
+	}
+	summary.WriteString(p.T("receipt.confirm", draft.command()))
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, summary.String())
+	msg.ReplyToMessageID = message.MessageID
+	msg.ReplyMarkup = buildDraftKeyboard(p, draft)
+	send(ctx, bot, msg)
+}
+
+// errNoTotal is returned by readReceipt when the receipt shows no total.
+var errNoTotal = errors.New("receipt: no total found")
+
+// readReceipt runs OCR on a receipt photo and drafts the expense it shows for
+// userID, described by the first word of the store name.
+func readReceipt(ctx context.Context, engine ocr.Engine, image []byte, userID int64) (ocr.Receipt, expenseDraft, error) {
+	text, err := engine.Recognize(ctx, image)
+	if err != nil {
+		return ocr.Receipt{}, expenseDraft{}, err
+	}
+	receipt, ok := ocr.ParseReceipt(text)
+	if !ok {
+		return receipt, expenseDraft{}, errNoTotal
+	}
+	return receipt, expenseDraft{
+		UserID:      userID,
+		Amount:      receipt.Total,
+		Date:        receipt.Date,
+		Description: merchantDescription(receipt.Merchant),
+	}, nil
+}
+
+// merchantDescription turns "SUPERMERCADO BOM PRECO LTDA" into "supermercado",
+// keeping the single-word descriptions /gastei uses.
+func merchantDescription(merchant string) string {
+	for _, word := range strings.Fields(strings.ToLower(merchant)) {
+		word = strings.Trim(word, ".,-/*")
+		if len([]rune(word)) >= 3 {
+			return word
+		}
+	}
+	return "outros"
+}
+
+// repliedExpense returns the owner and ID of the expense shown in the bot
+// message being replied to. The owner is read from the buttons of the message;
+// in private chats, where it can only be the user, the buttons may be gone.
+func repliedExpense(bot *tgbotapi.BotAPI, message *tgbotapi.Message) (int64, int, bool) {
+	replied := message.ReplyToMessage
+	if replied == nil || replied.From == nil || replied.From.ID != bot.Self.ID {
+		return 0, 0, false
+	}
+
+	match := expenseIDPattern.FindStringSubmatch(replied.Text)
+	if match == nil {
+		return 0, 0, false
+	}
+	seqID, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	owner, ok := keyboardOwner(replied.ReplyMarkup)
+	if !ok {
+		if !message.Chat.IsPrivate() {
+			return 0, 0, false
+		}
+		owner = message.Chat.ID
+	}
+	return owner, seqID, true
+}
+
+// ownerPrefixes are the callback data prefixes of the buttons shown with an
+// expense, which carry the ID of its owner right after the prefix.
+var ownerPrefixes = map[string]bool{
+	"sug_ok": true, "sugcat": true, "sugmet": true, "undo": true,
+	"qnav": true, "confirm_delete": true, "receipt": true,
+}
+
+// keyboardOwner returns the user the buttons of a bot message belong to.
+func keyboardOwner(markup *tgbotapi.InlineKeyboardMarkup) (int64, bool) {
+	if markup == nil {
+		return 0, false
+	}
+	for _, row := range markup.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData == nil {
+				continue
+			}
+			prefix, rest, _ := strings.Cut(*button.CallbackData, ":")
+			if !ownerPrefixes[prefix] {
+				continue
+			}
+			owner, _, _ := strings.Cut(rest, ":")
+			if userID, err := strconv.ParseInt(owner, 10, 64); err == nil {
+				return userID, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func attachReceipt(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, seqID int) {
+	slog.InfoContext(ctx, "Attaching receipt", "chat_id", message.Chat.ID, "user_id", message.From.ID, "seq_id", seqID)
+	p := i18n.FromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	expense, err := database.GetExpenseBySeqID(ctx, message.From.ID, seqID)
+	if err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("common.expense_not_found", seqID)))
+		return
+	}
+
+	previous, err := expenseSnapshot(ctx, expense)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to snapshot expense for undo", "seq_id", seqID, "error", err)
+	}
+
+	expense.ReceiptFileID = largestPhoto(message.Photo).FileID
+	if err := database.UpdateExpense(ctx, expense); err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("receipt.save_failed")))
+		return
+	}
+
+	var actionID string
+	if previous != nil {
+		actionID = recordUndo(ctx, &models.UndoEntry{UserID: message.From.ID, Action: models.UndoEdit, SeqID: seqID, Previous: previous})
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, p.T("receipt.attached", seqID, seqID))
+	if keyboard := withUndoButton(p, nil, message.From.ID, actionID); keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	send(ctx, bot, msg)
+}
+
+// HandleReceiptCallback re-sends the receipt photo of an expense to its owner.
+func HandleReceiptCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	p := i18n.FromContext(ctx)
+
+	// data format: "receipt:<userID>:<seqID>"
+	var userID int64
+	var seqID int
+	fmt.Sscanf(callback.Data, "receipt:%d:%d", &userID, &seqID)
+	if userID != callback.From.ID {
+		answerCallback(ctx, bot, callback, p.T("receipt.not_yours", seqID))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	expense, err := database.GetExpenseBySeqID(ctx, userID, seqID)
+	if err != nil || expense.ReceiptFileID == "" {
+		answerCallback(ctx, bot, callback, callbackFailureText(ctx, err, p.T("receipt.not_found")))
+		return
+	}
+
+	answerCallback(ctx, bot, callback, "")
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(expense.ReceiptFileID))
+	photo.Caption = p.T("receipt.caption", expense.SeqID, p.Money(expense.Amount), expense.Label())
+	send(ctx, bot, photo)
+}
+
+// largestPhoto returns the biggest size Telegram generated for a photo.
+func largestPhoto(sizes []tgbotapi.PhotoSize) tgbotapi.PhotoSize {
+	best := sizes[0]
+	for _, size := range sizes[1:] {
+		if size.Width*size.Height > best.Width*best.Height {
+			best = size
+		}
+	}
+	return best
+}
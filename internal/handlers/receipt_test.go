@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"money-telegram-bot/internal/ocr"
+)
+
+const cupomFiscal = `SUPERMERCADO BOM PRECO LTDA
+CNPJ: 12.345.678/0001-90
+RUA DAS FLORES, 123 - CENTRO
+CUPOM FISCAL ELETRONICO
+ARROZ 5KG            1 UN   24,90
+FEIJAO 1KG           2 UN   17,80
+QTD. TOTAL DE ITENS  3
+VALOR TOTAL R$              42,70
+VALOR A PAGAR R$            42,70
+EMISSAO: 14/03/2025 18:22:10
+`
+
+func TestReadReceipt(t *testing.T) {
+	receipt, draft, err := readReceipt(context.Background(), ocr.Fake{Text: cupomFiscal}, []byte("jpeg"), 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if receipt.Merchant != "SUPERMERCADO BOM PRECO LTDA" {
+		t.Errorf("merchant = %q", receipt.Merchant)
+	}
+	want := expenseDraft{
+		UserID:      42,
+		Amount:      42.70,
+		Date:        time.Date(2025, time.March, 14, 12, 0, 0, 0, time.UTC),
+		Description: "supermercado",
+	}
+	if draft != want {
+		t.Errorf("draft = %+v, want %+v", draft, want)
+	}
+	if got := draft.command(); got != "/gastei 42,70 supermercado" {
+		t.Errorf("command = %q", got)
+	}
+}
+
+func TestReadReceiptErrors(t *testing.T) {
+	failure := errors.New("tesseract crashed")
+	tests := []struct {
+		name   string
+		engine ocr.Engine
+		want   error
+	}{
+		{"no engine", ocr.Disabled{}, ocr.ErrUnavailable},
+		{"engine failure", ocr.Fake{Err: failure}, failure},
+		{"no total", ocr.Fake{Text: "PADARIA CENTRAL\nOBRIGADO PELA PREFERENCIA\n"}, errNoTotal},
+		{"blank photo", ocr.Fake{Text: ""}, errNoTotal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, draft, err := readReceipt(context.Background(), tt.engine, []byte("jpeg"), 42)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			if draft != (expenseDraft{}) {
+				t.Errorf("draft = %+v, want none", draft)
+			}
+		})
+	}
+}
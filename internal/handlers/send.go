@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Every call to the Telegram API goes through send or request, so no reply is
+// lost silently: flood limits (429) are retried after the retry_after Telegram
+// asks for, messages whose Markdown Telegram rejects are sent again as plain
+// text, and what still fails is logged with the update's context.
+
+// maxSendAttempts bounds the calls made for one message.
+const maxSendAttempts = 3
+
+// maxRetryWait is the longest retry_after worth waiting for; beyond it the
+// user is better served by a failed reply than by a handler stuck for minutes.
+const maxRetryWait = 30 * time.Second
+
+// send sends a message (or edits one) and returns the message Telegram stored.
+func send(ctx context.Context, bot *tgbotapi.BotAPI, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	var sent tgbotapi.Message
+	err := deliver(ctx, c, func(c tgbotapi.Chattable) error {
+		var err error
+		sent, err = bot.Send(c)
+		return err
+	})
+	return sent, err
+}
+
+// request makes a call whose result is not a message, such as answering a
+// callback query.
+func request(ctx context.Context, bot *tgbotapi.BotAPI, c tgbotapi.Chattable) error {
+	return deliver(ctx, c, func(c tgbotapi.Chattable) error {
+		_, err := bot.Request(c)
+		return err
+	})
+}
+
+// answerCallback stops the loading animation of a pressed button, showing text
+// as a toast when it is not empty.
+func answerCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery, text string) {
+	request(ctx, bot, tgbotapi.NewCallback(callback.ID, text))
+}
+
+func deliver(ctx context.Context, c tgbotapi.Chattable, call func(tgbotapi.Chattable) error) error {
+	kind := fmt.Sprintf("%T", c)
+
+	var err error
+	for attempt := 1; attempt <= maxSendAttempts; attempt++ {
+		if err = call(c); err == nil {
+			return nil
+		}
+
+		var apiErr *tgbotapi.Error
+		if !errors.As(err, &apiErr) {
+			break
+		}
+
+		if notModified(apiErr) {
+			// Pressing the same button twice edits a message into itself.
+			slog.DebugContext(ctx, "Telegram message not modified", "request", kind)
+			return nil
+		}
+
+		if formattingRejected(apiErr) {
+			plain, ok := withoutParseMode(c)
+			if !ok {
+				break
+			}
+			slog.WarnContext(ctx, "Telegram rejected message formatting, sending as plain text", "request", kind, "error", err)
+			c = plain
+			continue
+		}
+
+		wait := time.Duration(apiErr.RetryAfter) * time.Second
+		if apiErr.Code != 429 || wait > maxRetryWait || attempt == maxSendAttempts {
+			break
+		}
+		slog.WarnContext(ctx, "Telegram flood limit, retrying", "request", kind, "retry_after", apiErr.RetryAfter)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			slog.ErrorContext(ctx, "Failed to send to Telegram", "request", kind, "error", err)
+			return err
+		}
+	}
+
+	slog.ErrorContext(ctx, "Failed to send to Telegram", "request", kind, "error", err)
+	return err
+}
+
+// notModified reports an edit that would not change the message.
+func notModified(err *tgbotapi.Error) bool {
+	return err.Code == 400 && strings.Contains(err.Message, "message is not modified")
+}
+
+// formattingRejected reports a message whose Markdown or HTML is invalid.
+func formattingRejected(err *tgbotapi.Error) bool {
+	return err.Code == 400 && strings.Contains(err.Message, "can't parse entities")
+}
+
+// withoutParseMode returns a copy of c sent as plain text, or false when c has
+// no parse mode to drop.
+func withoutParseMode(c tgbotapi.Chattable) (tgbotapi.Chattable, bool) {
+	switch config := c.(type) {
+	case tgbotapi.MessageConfig:
+		if config.ParseMode == "" {
+			return nil, false
+		}
+		config.ParseMode = ""
+		return config, true
+	case tgbotapi.EditMessageTextConfig:
+		if config.ParseMode == "" {
+			return nil, false
+		}
+		config.ParseMode = ""
+		return config, true
+	case tgbotapi.PhotoConfig:
+		if config.ParseMode == "" {
+			return nil, false
+		}
+		config.ParseMode = ""
+		return config, true
+	}
+	return nil, false
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// botAPI is a fake Bot API server. Each sendMessage call gets the next of
+// replies (a JSON body); the last one repeats.
+type botAPI struct {
+	mu         sync.Mutex
+	replies    []string
+	parseModes []string // parse_mode of each sendMessage call
+	chatIDs    []string // chat_id of each sendMessage call
+	times      []time.Time
+}
+
+func (api *botAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if path.Base(r.URL.Path) == "getMe" {
+		fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"bot","username":"test_bot"}}`)
+		return
+	}
+
+	r.ParseForm()
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.parseModes = append(api.parseModes, r.Form.Get("parse_mode"))
+	api.chatIDs = append(api.chatIDs, r.Form.Get("chat_id"))
+	api.times = append(api.times, time.Now())
+	reply := api.replies[min(len(api.parseModes), len(api.replies))-1]
+	fmt.Fprint(w, reply)
+}
+
+func newTestBot(t *testing.T, api *botAPI) *tgbotapi.BotAPI {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("123:test", server.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bot
+}
+
+const (
+	sentReply        = `{"ok":true,"result":{"message_id":5,"date":0,"chat":{"id":7,"type":"private"},"text":"oi"}}`
+	parseErrorReply  = `{"ok":false,"error_code":400,"description":"Bad Request: can't parse entities: Unsupported start tag \"b\" at byte offset 3"}`
+	floodReply       = `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`
+	longFloodReply   = `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 600","parameters":{"retry_after":600}}`
+	forbiddenReply   = `{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`
+	notModifiedReply = `{"ok":false,"error_code":400,"description":"Bad Request: message is not modified"}`
+)
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name       string
+		replies    []string
+		parseModes []string // of the calls the server should see
+		wantErr    bool
+		minGap     time.Duration // between the first two calls
+	}{
+		{"sent", []string{sentReply}, []string{"HTML"}, false, 0},
+		{"HTML rejected", []string{parseErrorReply, sentReply}, []string{"HTML", ""}, false, 0},
+		{"flood limit", []string{floodReply, sentReply}, []string{"HTML", "HTML"}, false, time.Second},
+		{"flood limit too long", []string{longFloodReply}, []string{"HTML"}, true, 0},
+		{"flood limit every time", []string{floodReply}, []string{"HTML", "HTML", "HTML"}, true, time.Second},
+		{"blocked", []string{forbiddenReply}, []string{"HTML"}, true, 0},
+		{"not modified", []string{notModifiedReply}, []string{"HTML"}, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if tt.minGap > 0 && testing.Short() {
+				t.Skip("waits for retry_after")
+			}
+			api := &botAPI{replies: tt.replies}
+			bot := newTestBot(t, api)
+
+			msg := tgbotapi.NewMessage(7, "<b>oi")
+			msg.ParseMode = tgbotapi.ModeHTML
+			_, err := send(context.Background(), bot, msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(api.parseModes) != fmt.Sprint(tt.parseModes) {
+				t.Errorf("calls with parse modes %q, want %q", api.parseModes, tt.parseModes)
+			}
+			if len(api.times) > 1 && api.times[1].Sub(api.times[0]) < tt.minGap {
+				t.Errorf("retried after %v, want at least %v", api.times[1].Sub(api.times[0]), tt.minGap)
+			}
+		})
+	}
+}
+
+func TestSendPlainTextRejected(t *testing.T) {
+	// A message without parse mode cannot be sent any plainer.
+	api := &botAPI{replies: []string{parseErrorReply}}
+	bot := newTestBot(t, api)
+
+	var apiErr *tgbotapi.Error
+	if _, err := send(context.Background(), bot, tgbotapi.NewMessage(7, "oi")); !errors.As(err, &apiErr) || apiErr.Code != 400 {
+		t.Errorf("err = %v, want the 400", err)
+	}
+	if len(api.parseModes) != 1 {
+		t.Errorf("made %d calls, want 1", len(api.parseModes))
+	}
+}
+
+func TestSendFloodLimitStopsWithContext(t *testing.T) {
+	api := &botAPI{replies: []string{floodReply}}
+	bot := newTestBot(t, api)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := send(ctx, bot, tgbotapi.NewMessage(7, "oi")); err == nil {
+		t.Fatal("expected the flood limit error")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("waited %v past the deadline", elapsed)
+	}
+	if len(api.parseModes) != 1 {
+		t.Errorf("made %d calls, want 1", len(api.parseModes))
+	}
+}
@@ -1,40 +1,25 @@
 package handlers
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/render"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func HandleStart(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	log.Println("[INFO] Processing /start command")
-
-	welcomeText := `👋 Bem-vindo ao *Money Savior*!
-
-💰 Seu assistente pessoal para controle de gastos e organização financeira.
-
-📌 *Comandos disponíveis:*
-
-➕ /gastei — Registre um novo gasto  
-Exemplo: /gastei 21.90 uber pix
-
-📋 /consulta — Veja todos os gastos (IDs em ordem)
-
-🔎 /consulta <ID> — Veja um gasto específico com navegação  
-Exemplo: /consulta 3
-
-🗑️ /deletar <ID> — Delete um gasto pelo ID
+func HandleStart(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /start command")
 
-❌ /deletartudo — Delete todos os gastos
-
-ℹ️ /help — Veja todos os comandos e exemplos
-
-✨ Dica: os IDs são sequenciais (1, 2, 3...), facilitando o gerenciamento!
-
-Digite /help para mais detalhes 🚀`
-
-	msg := tgbotapi.NewMessage(message.Chat.ID, welcomeText)
-	bot.Send(msg)
+	msg := tgbotapi.NewMessage(message.Chat.ID, buildWelcomeText(i18n.FromContext(ctx)))
+	msg.ParseMode = render.ParseMode
+	if _, err := send(ctx, bot, msg); err != nil {
+		return
+	}
 	user := message.From
 
 	username := user.UserName
@@ -47,10 +32,24 @@ Digite /help para mais detalhes 🚀`
 		lastName = "-"
 	}
 
-	log.Printf(
-		"[INFO] Response sent | chatID=%d | userID=%d | userName=%s | status=success",
-		message.Chat.ID,
-		user.ID,
-		username,
+	slog.InfoContext(ctx, "Response sent",
+		"chat_id", message.Chat.ID,
+		"user_id", user.ID,
+		"user_name", username,
+		"status", "success",
 	)
 }
+
+// buildWelcomeText lists every command but /start with its short description.
+func buildWelcomeText(p *i18n.Printer) string {
+	var text strings.Builder
+	text.WriteString(p.Format("start.header"))
+	for _, command := range Commands() {
+		if command.Name == "start" {
+			continue
+		}
+		text.WriteString(fmt.Sprintf("%s /%s — %s\n\n", command.Emoji, command.Name, p.T("commands."+command.Name)))
+	}
+	text.WriteString(p.Format("start.footer"))
+	return text.String()
+}
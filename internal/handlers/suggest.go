@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"money-telegram-bot/internal/classifier"
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxSuggestions is how many alternatives are offered for category and method.
+	maxSuggestions = 3
+	// maxCallbackData is Telegram's limit for inline button payloads, in bytes.
+	maxCallbackData = 64
+)
+
+// buildSuggestionKeyboard offers the alternatives the classifier found for a
+// freshly saved expense. It returns nil when there is nothing to choose from.
+func buildSuggestionKeyboard(p *i18n.Printer, expense *models.Expense, categories, methods []classifier.Suggestion) *tgbotapi.InlineKeyboardMarkup {
+	var categoryRow []tgbotapi.InlineKeyboardButton
+	// The expense is saved with its description as the category, so only the
+	// classifier's other guesses are worth a button.
+	seen := map[string]bool{expense.Category: true}
+	for _, suggestion := range categories {
+		if seen[suggestion.Value] {
+			continue
+		}
+		seen[suggestion.Value] = true
+		if button, ok := suggestionButton("🏷️ "+suggestion.Value, "sugcat", expense, suggestion.Value); ok {
+			categoryRow = append(categoryRow, button)
+		}
+	}
+
+	var methodRow []tgbotapi.InlineKeyboardButton
+	for _, suggestion := range methods {
+		if suggestion.Value == expense.Method {
+			continue
+		}
+		if button, ok := suggestionButton("💳 "+suggestion.Value, "sugmet", expense, suggestion.Value); ok {
+			methodRow = append(methodRow, button)
+		}
+	}
+
+	if len(categoryRow) == 0 && len(methodRow) == 0 {
+		return nil
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(p.T("suggest.ok_button"), fmt.Sprintf("sug_ok:%d", expense.UserID))),
+	}
+	if len(categoryRow) > 0 {
+		rows = append(rows, categoryRow)
+	}
+	if len(methodRow) > 0 {
+		rows = append(rows, methodRow)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
+
+// suggestionButton builds a "<prefix>:<userID>:<seqID>:<value>" button for
+// expense, skipping values that would not fit in Telegram's callback data limit.
+func suggestionButton(label, prefix string, expense *models.Expense, value string) (tgbotapi.InlineKeyboardButton, bool) {
+	data := fmt.Sprintf("%s:%d:%d:%s", prefix, expense.UserID, expense.SeqID, value)
+	if len(data) > maxCallbackData {
+		return tgbotapi.InlineKeyboardButton{}, false
+	}
+	return tgbotapi.NewInlineKeyboardButtonData(label, data), true
+}
+
+// HandleSuggestionCallback applies the category or method picked on the
+// suggestion keyboard. The corrected expense is what the classifier learns
+// from the next time the user logs something similar. Only the owner of the
+// expense can use the buttons.
+func HandleSuggestionCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	p := i18n.FromContext(ctx)
+
+	// data format: "sug_ok:<userID>", "sugcat:<userID>:<seqID>:<value>" or
+	// "sugmet:<userID>:<seqID>:<value>"
+	parts := strings.SplitN(callback.Data, ":", 4)
+	var userID int64
+	if len(parts) > 1 {
+		fmt.Sscanf(parts[1], "%d", &userID)
+	}
+	if userID != callback.From.ID {
+		answerCallback(ctx, bot, callback, p.T("suggest.not_yours"))
+		return
+	}
+
+	if parts[0] == "sug_ok" {
+		answerCallback(ctx, bot, callback, "👍")
+		edit := tgbotapi.NewEditMessageReplyMarkup(chatID, callback.Message.MessageID, undoRows(callback.Message.ReplyMarkup))
+		send(ctx, bot, edit)
+		return
+	}
+
+	seqID := 0
+	if len(parts) == 4 {
+		fmt.Sscanf(parts[2], "%d", &seqID)
+	}
+	if seqID < 1 || parts[len(parts)-1] == "" {
+		answerCallback(ctx, bot, callback, "")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	expense, err := database.GetExpenseBySeqID(ctx, userID, seqID)
+	if err != nil {
+		answerCallback(ctx, bot, callback, callbackFailureText(ctx, err, p.T("suggest.not_found", seqID)))
+		return
+	}
+
+	previous, err := expenseSnapshot(ctx, expense)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to snapshot expense for undo", "user_id", userID, "seq_id", seqID, "error", err)
+	}
+
+	switch parts[0] {
+	case "sugcat":
+		expense.Category = parts[3]
+	case "sugmet":
+		expense.Method = parts[3]
+	}
+
+	if err := database.UpdateExpense(ctx, expense); err != nil {
+		slog.ErrorContext(ctx, "Failed to apply suggestion", "user_id", userID, "seq_id", seqID, "error", err)
+		answerCallback(ctx, bot, callback, callbackFailureText(ctx, err, p.T("suggest.failed")))
+		return
+	}
+
+	answerCallback(ctx, bot, callback, p.T("suggest.updated"))
+
+	var actionID string
+	if previous != nil {
+		actionID = recordUndo(ctx, &models.UndoEntry{UserID: userID, Action: models.UndoEdit, SeqID: seqID, Previous: previous})
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, buildSavedExpenseText(p, expense, true))
+	edit.ReplyMarkup = withUndoButton(p, callback.Message.ReplyMarkup, userID, actionID)
+	send(ctx, bot, edit)
+	slog.InfoContext(ctx, "Suggestion applied", "user_id", userID, "seq_id", seqID, "field", parts[0])
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"money-telegram-bot/internal/classifier"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+)
+
+func TestBuildSuggestionKeyboard(t *testing.T) {
+	p := i18n.New(i18n.Portuguese)
+	expense := &models.Expense{UserID: 1, SeqID: 9, Description: "ifood", Category: "ifood", Method: "nubank"}
+
+	tests := []struct {
+		name       string
+		categories []classifier.Suggestion
+		methods    []classifier.Suggestion
+		want       []string // callback data of the suggestion buttons
+	}{
+		{"nothing new", []classifier.Suggestion{{Value: "ifood"}}, []classifier.Suggestion{{Value: "nubank"}}, nil},
+		{
+			name:       "other guesses",
+			categories: []classifier.Suggestion{{Value: "delivery"}, {Value: "ifood"}, {Value: "delivery"}},
+			methods:    []classifier.Suggestion{{Value: "nubank"}, {Value: "itau"}},
+			want:       []string{"sugcat:1:9:delivery", "sugmet:1:9:itau"},
+		},
+		{"too long", []classifier.Suggestion{{Value: strings.Repeat("x", maxCallbackData)}}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyboard := buildSuggestionKeyboard(p, expense, tt.categories, tt.methods)
+			if tt.want == nil {
+				if keyboard != nil {
+					t.Errorf("keyboard = %+v, want none", keyboard)
+				}
+				return
+			}
+			var got []string
+			for _, row := range keyboard.InlineKeyboard[1:] { // the first row accepts
+				for _, button := range row {
+					got = append(got, *button.CallbackData)
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("buttons = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
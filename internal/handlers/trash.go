@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+	"money-telegram-bot/internal/render"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxTrashItems is how many deleted expenses /lixeira lists, with a button each.
+const maxTrashItems = 10
+
+// trashDays is the retention of the trash, in days, for messages.
+func trashDays() int {
+	return int(database.TrashRetention.Hours() / 24)
+}
+
+// HandleTrash handles /lixeira — lists the recently deleted expenses with a
+// button to restore each one.
+func HandleTrash(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /lixeira command", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+	p := i18n.FromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	deleted, err := database.GetDeletedExpenses(ctx, message.From.ID)
+	if err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("trash.failed")))
+		return
+	}
+
+	text, keyboard := buildTrashView(p, message.From.ID, deleted)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = render.ParseMode
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	send(ctx, bot, msg)
+}
+
+// buildTrashView lists the deleted expenses of userID, most recent first, with
+// a restore button for each. The keyboard is nil when the trash is empty.
+func buildTrashView(p *i18n.Printer, userID int64, deleted []models.Expense) (string, *tgbotapi.InlineKeyboardMarkup) {
+	if len(deleted) == 0 {
+		return p.T("trash.empty", trashDays()), nil
+	}
+
+	listed := deleted
+	if len(listed) > maxTrashItems {
+		listed = listed[:maxTrashItems]
+	}
+
+	text := p.T("trash.header", trashDays())
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, expense := range listed {
+		text += render.Sprintf(p.Format("trash.item"),
+			expense.SeqID,
+			amountLabel(p, &expense),
+			expense.Label(),
+			p.ShortDate(*expense.DeletedAt),
+		)
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			p.T("trash.restore_button", expense.SeqID),
+			fmt.Sprintf("trash:%d:%d", userID, expense.SeqID),
+		))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	if hidden := len(deleted) - len(listed); hidden > 0 {
+		text += p.T("trash.more", hidden)
+	}
+	text += p.T("trash.footer")
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return text, &keyboard
+}
+
+// HandleTrashCallback restores the expense of a /lixeira button and shows the
+// trash again without it.
+func HandleTrashCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	p := i18n.FromContext(ctx)
+
+	// data format: "trash:<userID>:<seqID>"
+	var userID int64
+	var seqID int
+	fmt.Sscanf(callback.Data, "trash:%d:%d", &userID, &seqID)
+	if userID != callback.From.ID {
+		answerCallback(ctx, bot, callback, p.T("trash.not_yours"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	var actionID string
+	if restored, err := database.RestoreExpenseBySeqID(ctx, userID, seqID); errors.Is(err, database.ErrNotInTrash) {
+		answerCallback(ctx, bot, callback, p.T("trash.not_found"))
+	} else if err != nil {
+		answerCallback(ctx, bot, callback, callbackFailureText(ctx, err, p.T("trash.restore_failed")))
+		return
+	} else {
+		actionID = recordUndo(ctx, &models.UndoEntry{UserID: userID, Action: models.UndoRestore, SeqID: seqID, Restored: []string{restored.ExpenseID}})
+		answerCallback(ctx, bot, callback, p.T("trash.restored", seqID))
+	}
+
+	deleted, err := database.GetDeletedExpenses(ctx, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to refresh trash", "user_id", userID, "error", err)
+		return
+	}
+	text, keyboard := buildTrashView(p, userID, deleted)
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, text)
+	edit.ParseMode = render.ParseMode
+	edit.ReplyMarkup = withUndoButton(p, keyboard, userID, actionID)
+	send(ctx, bot, edit)
+}
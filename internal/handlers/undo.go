@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// undoWindow is how long the last action can be undone, with /desfazer or the
+// button on its confirmation.
+const undoWindow = 10 * time.Minute
+
+// undoPrefix starts the data of undo buttons: "undo:<userID>:<actionID>".
+const undoPrefix = "undo:"
+
+// recordUndo stores how to revert an action on the expenses of a user, given
+// by entry's UserID, Action and the fields that action uses. It returns the id
+// of the action for its undo button, or "" when it could not be stored: the
+// action stands, it just can't be undone.
+func recordUndo(ctx context.Context, entry *models.UndoEntry) string {
+	now := time.Now().UTC()
+	entry.ActionID = strconv.FormatInt(now.UnixNano(), 36)
+	entry.CreatedAt = now
+	entry.ExpiresAt = now.Add(undoWindow).Unix()
+	if err := database.SaveUndoEntry(ctx, entry); err != nil {
+		slog.WarnContext(ctx, "Failed to record undo entry", "user_id", entry.UserID, "action", entry.Action, "error", err)
+		return ""
+	}
+	return entry.ActionID
+}
+
+// topLevelIDs returns the expense_ids of expenses, with installments standing
+// for their purchase: they follow it, and the purchase may not be among
+// expenses when a delete failed halfway.
+func topLevelIDs(expenses []models.Expense) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, expense := range expenses {
+		id := expense.ExpenseID
+		if expense.IsInstallment() {
+			id = expense.ParentID
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// expenseSnapshot returns expense as stored, with its installments when it is
+// an installment purchase, since changes to a purchase reach them too.
+func expenseSnapshot(ctx context.Context, expense *models.Expense) ([]models.Expense, error) {
+	snapshot := []models.Expense{*expense}
+	if !expense.IsInstallmentPurchase() {
+		return snapshot, nil
+	}
+	installments, err := database.GetInstallments(ctx, expense)
+	if err != nil {
+		return nil, err
+	}
+	return append(snapshot, installments...), nil
+}
+
+// withUndoButton returns keyboard with the button undoing action actionID of
+// userID as its last row, replacing the one of an earlier action. Without an
+// actionID only the earlier button is removed. It returns nil rather than an
+// empty keyboard.
+func withUndoButton(p *i18n.Printer, keyboard *tgbotapi.InlineKeyboardMarkup, userID int64, actionID string) *tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if keyboard != nil {
+		for _, row := range keyboard.InlineKeyboard {
+			if !isUndoRow(row) {
+				rows = append(rows, row)
+			}
+		}
+	}
+	if actionID != "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.T("undo.button"), fmt.Sprintf("%s%d:%s", undoPrefix, userID, actionID)),
+		))
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// undoRows keeps only the undo button of keyboard, for when its other buttons
+// are done with.
+func undoRows(keyboard *tgbotapi.InlineKeyboardMarkup) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	if keyboard != nil {
+		for _, row := range keyboard.InlineKeyboard {
+			if isUndoRow(row) {
+				rows = append(rows, row)
+			}
+		}
+	}
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func isUndoRow(row []tgbotapi.InlineKeyboardButton) bool {
+	return len(row) == 1 && row[0].CallbackData != nil && strings.HasPrefix(*row[0].CallbackData, undoPrefix)
+}
+
+// HandleUndo handles /desfazer — reverts the last action on the user's expenses.
+func HandleUndo(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing /desfazer command", "chat_id", message.Chat.ID, "user_id", message.From.ID)
+	p := i18n.FromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	entry, err := undo(ctx, message.From.ID, "")
+	if errors.Is(err, database.ErrNothingToUndo) {
+		reply(ctx, bot, message, p.T("undo.nothing", int(undoWindow.Minutes())))
+		return
+	}
+	if err != nil {
+		reply(ctx, bot, message, failureText(ctx, err, p.T("undo.failed")))
+		return
+	}
+	reply(ctx, bot, message, undoneText(p, entry))
+}
+
+// HandleUndoCallback handles the undo button on the confirmation of an action.
+func HandleUndoCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	p := i18n.FromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+
+	// data format: "undo:<userID>:<actionID>"
+	var userID int64
+	owner, actionID, _ := strings.Cut(strings.TrimPrefix(callback.Data, undoPrefix), ":")
+	fmt.Sscanf(owner, "%d", &userID)
+	if userID != callback.From.ID {
+		answerCallback(ctx, bot, callback, p.T("undo.not_yours"))
+		return
+	}
+	if actionID == "" {
+		// Without an id the last action would be undone, whichever it is.
+		answerCallback(ctx, bot, callback, p.T("undo.expired"))
+		return
+	}
+
+	entry, err := undo(ctx, userID, actionID)
+	if errors.Is(err, database.ErrNothingToUndo) {
+		answerCallback(ctx, bot, callback, p.T("undo.expired"))
+		edit := tgbotapi.NewEditMessageReplyMarkup(chatID, callback.Message.MessageID, tgbotapi.InlineKeyboardMarkup{
+			InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+		})
+		send(ctx, bot, edit)
+		return
+	}
+	if err != nil {
+		answerCallback(ctx, bot, callback, callbackFailureText(ctx, err, p.T("undo.failed")))
+		return
+	}
+	answerCallback(ctx, bot, callback, "")
+
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, undoneText(p, entry))
+	edit.ReplyMarkup = nil
+	send(ctx, bot, edit)
+}
+
+// undo takes the undo entry of the user (the one of actionID, when given) and
+// reverts its action. When reverting fails the entry is stored again, so the
+// user can retry.
+func undo(ctx context.Context, userID int64, actionID string) (*models.UndoEntry, error) {
+	entry, err := database.TakeUndoEntry(ctx, userID, actionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.RevertUndoEntry(ctx, entry); err != nil {
+		slog.ErrorContext(ctx, "Failed to undo action", "user_id", userID, "action", entry.Action, "error", err)
+		if err := database.SaveUndoEntry(ctx, entry); err != nil {
+			slog.WarnContext(ctx, "Failed to keep undo entry", "user_id", userID, "error", err)
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+// undoneText tells what undoing entry did.
+func undoneText(p *i18n.Printer, entry *models.UndoEntry) string {
+	switch entry.Action {
+	case models.UndoSave:
+		return p.T("undo.saved", entry.SeqID)
+	case models.UndoEdit:
+		return p.T("undo.edited", entry.SeqID)
+	case models.UndoDelete:
+		return p.T("undo.deleted", entry.SeqID)
+	case models.UndoRestore:
+		return p.T("undo.restored", entry.SeqID)
+	default:
+		return p.T("undo.deleted_all", len(entry.Trashed))
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/models"
+)
+
+func TestUndoEntryOfDeleteAllKeepsOnlyKeys(t *testing.T) {
+	var deleted []models.Expense
+	for i := 1; i <= 3000; i++ {
+		purchase := models.Expense{
+			UserID:      9007199254740991,
+			ExpenseID:   fmt.Sprintf("9007199254740991#2025-03-14T18:22:10.%09d-03:00", i),
+			SeqID:       i,
+			Amount:      1234.56,
+			Category:    "supermercado",
+			Method:      "nubank",
+			Description: "compras do mês no supermercado do bairro",
+		}
+		deleted = append(deleted, purchase, models.Expense{ExpenseID: purchase.ExpenseID + "#01", ParentID: purchase.ExpenseID})
+	}
+
+	entry := &models.UndoEntry{UserID: 9007199254740991, Action: models.UndoDeleteAll, Trashed: topLevelIDs(deleted)}
+	if len(entry.Trashed) != 3000 || !slices.Contains(entry.Trashed, deleted[0].ExpenseID) {
+		t.Fatalf("trashed %d expenses, want the 3000 purchases", len(entry.Trashed))
+	}
+
+	// DynamoDB items are limited to 400 KB; JSON is a little larger than the
+	// stored item.
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > 400<<10 {
+		t.Errorf("undo entry of 3000 expenses takes %d bytes", len(data))
+	}
+
+	p := i18n.New(i18n.Portuguese)
+	if got, want := undoneText(p, entry), p.T("undo.deleted_all", 3000); got != want {
+		t.Errorf("undoneText = %q, want %q", got, want)
+	}
+}
+
+func TestTopLevelIDsOfPartialDelete(t *testing.T) {
+	// Installments go to the trash first, so a failed delete can leave them
+	// without their purchase.
+	deleted := []models.Expense{
+		{ExpenseID: "1#a#01", ParentID: "1#a"},
+		{ExpenseID: "1#a#02", ParentID: "1#a"},
+		{ExpenseID: "1#b"},
+		{ExpenseID: "1#c#01", ParentID: "1#c"},
+		{ExpenseID: "1#c"},
+	}
+
+	got := topLevelIDs(deleted)
+	if want := []string{"1#a", "1#b", "1#c"}; !slices.Equal(got, want) {
+		t.Errorf("topLevelIDs = %v, want %v", got, want)
+	}
+}
+
+func TestUndoneText(t *testing.T) {
+	p := i18n.New(i18n.Portuguese)
+	tests := []struct {
+		entry models.UndoEntry
+		want  string
+	}{
+		{models.UndoEntry{Action: models.UndoSave, SeqID: 3}, p.T("undo.saved", 3)},
+		{models.UndoEntry{Action: models.UndoEdit, SeqID: 4}, p.T("undo.edited", 4)},
+		{models.UndoEntry{Action: models.UndoDelete, SeqID: 5, Trashed: []string{"1#a"}}, p.T("undo.deleted", 5)},
+		{models.UndoEntry{Action: models.UndoRestore, SeqID: 6, Restored: []string{"1#b"}}, p.T("undo.restored", 6)},
+		{models.UndoEntry{Action: models.UndoDeleteAll, Trashed: []string{"1#a", "1#b"}}, p.T("undo.deleted_all", 2)},
+	}
+	for _, tt := range tests {
+		if got := undoneText(p, &tt.entry); got != tt.want {
+			t.Errorf("undoneText(%s) = %q, want %q", tt.entry.Action, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"money-telegram-bot/internal/currency"
+	"money-telegram-bot/internal/database"
+	"money-telegram-bot/internal/i18n"
+	"money-telegram-bot/internal/parser"
+	"money-telegram-bot/internal/speech"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// voiceTranscriber turns voice messages into text.
+var voiceTranscriber speech.SpeechToText = speech.FromEnv()
+
+// SetSpeechToText replaces the engine used to transcribe voice messages.
+func SetSpeechToText(engine speech.SpeechToText) {
+	voiceTranscriber = engine
+}
+
+// maxVoiceSeconds skips long recordings, which are unlikely to be an expense.
+const maxVoiceSeconds = 60
+
+// HandleVoice handles voice messages like "gastei vinte reais de uber no pix":
+// it transcribes the audio, reads the expense from the transcript and asks
+// for confirmation before saving.
+func HandleVoice(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	slog.InfoContext(ctx, "Processing voice message", "chat_id", message.Chat.ID, "user_id", message.From.ID, "duration", message.Voice.Duration)
+	p := i18n.FromContext(ctx)
+
+	if message.Voice.Duration > maxVoiceSeconds {
+		reply(ctx, bot, message, p.T("voice.too_long", maxVoiceSeconds))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, transcribeTimeout)
+	defer cancel()
+
+	audio, err := downloadFile(ctx, bot, message.Voice.FileID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to download voice message", "chat_id", message.Chat.ID, "error", err)
+		reply(ctx, bot, message, failureText(ctx, err, p.T("voice.download_failed")))
+		return
+	}
+
+	var methodNames []string
+	if methods, err := database.GetPaymentMethods(ctx, message.From.ID); err == nil {
+		for _, method := range methods {
+			methodNames = append(methodNames, method.Name)
+		}
+	}
+
+	transcript, draft, err := readVoice(ctx, voiceTranscriber, audio, message.Voice.MimeType, message.From.ID, methodNames)
+	switch {
+	case errors.Is(err, speech.ErrUnavailable):
+		reply(ctx, bot, message, p.T("voice.unavailable"))
+		return
+	case errors.Is(err, errNoAmount):
+		reply(ctx, bot, message, p.T("voice.no_amount", transcript))
+		return
+	case err != nil:
+		slog.ErrorContext(ctx, "Failed to transcribe voice message", "chat_id", message.Chat.ID, "error", err)
+		reply(ctx, bot, message, failureText(ctx, err, p.T("voice.transcribe_failed")))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, p.T("voice.proposal",
+		transcript,
+		p.Amount(draft.Amount, currency.Default),
+		draft.Description,
+		methodOrUnknown(p, draft.Method),
+		draft.command(),
+	))
+	msg.ReplyToMessageID = message.MessageID
+	msg.ReplyMarkup = buildDraftKeyboard(p, draft)
+	send(ctx, bot, msg)
+}
+
+// errNoAmount is returned by readVoice when the transcript has no amount.
+var errNoAmount = errors.New("voice: no amount found")
+
+// readVoice transcribes a voice message and drafts the expense it describes
+// for userID, matching the payment method against the user's methodNames.
+func readVoice(ctx context.Context, engine speech.SpeechToText, audio []byte, mimeType string, userID int64, methodNames []string) (string, expenseDraft, error) {
+	transcript, err := engine.Transcribe(ctx, audio, mimeType)
+	if err != nil {
+		return "", expenseDraft{}, err
+	}
+
+	parsed, ok := parser.Parse(transcript, methodNames)
+	if !ok {
+		return transcript, expenseDraft{}, errNoAmount
+	}
+	return transcript, expenseDraft{
+		UserID:      userID,
+		Amount:      parsed.Amount,
+		Method:      parsed.Method,
+		Description: parsed.Description,
+	}, nil
+}
+
+func methodOrUnknown(p *i18n.Printer, method string) string {
+	if method == "" {
+		return p.T("voice.method_unknown")
+	}
+	return method
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"money-telegram-bot/internal/speech"
+)
+
+func TestReadVoice(t *testing.T) {
+	methods := []string{"itau", "nubank"}
+	tests := []struct {
+		name       string
+		transcript string
+		want       expenseDraft
+	}{
+		{
+			name:       "common method",
+			transcript: "gastei vinte reais de uber no pix",
+			want:       expenseDraft{UserID: 42, Amount: 20, Method: "pix", Description: "uber"},
+		},
+		{
+			name:       "registered method",
+			transcript: "gastei quarenta e dois reais no mercado no nubank",
+			want:       expenseDraft{UserID: 42, Amount: 42, Method: "nubank", Description: "mercado"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcript, draft, err := readVoice(context.Background(), speech.Fake{Text: tt.transcript}, []byte("ogg"), "audio/ogg", 42, methods)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if transcript != tt.transcript {
+				t.Errorf("transcript = %q", transcript)
+			}
+			if draft != tt.want {
+				t.Errorf("draft = %+v, want %+v", draft, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadVoiceErrors(t *testing.T) {
+	failure := errors.New("whisper crashed")
+	tests := []struct {
+		name   string
+		engine speech.SpeechToText
+		want   error
+	}{
+		{"no engine", speech.Disabled{}, speech.ErrUnavailable},
+		{"engine failure", speech.Fake{Err: failure}, failure},
+		{"no amount", speech.Fake{Text: "bom dia tudo bem"}, errNoAmount},
+		{"silence", speech.Fake{Text: ""}, errNoAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, draft, err := readVoice(context.Background(), tt.engine, []byte("ogg"), "audio/ogg", 42, nil)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			if draft != (expenseDraft{}) {
+				t.Errorf("draft = %+v, want none", draft)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+// Package i18n holds the message catalogues of the bot and formats numbers and
+// dates the way each language expects. Messages are looked up by key and
+// formatted with fmt verbs; every catalogue must have the same keys and verbs
+// as the Portuguese one, which Validate checks at startup.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"money-telegram-bot/internal/currency"
+)
+
+// Lang is a supported language, as an IETF tag.
+type Lang string
+
+const (
+	Portuguese Lang = "pt-BR"
+	English    Lang = "en"
+	Spanish    Lang = "es"
+)
+
+// Code returns the ISO 639-1 code of the language, as Telegram's language_code.
+func (l Lang) Code() string {
+	code, _, _ := strings.Cut(string(l), "-")
+	return strings.ToLower(code)
+}
+
+// Default is the language of users whose Telegram language is not supported.
+const Default = Portuguese
+
+// Languages lists the supported languages, in the order they are offered.
+var Languages = []Lang{Portuguese, English, Spanish}
+
+// locale describes how a language writes numbers and dates.
+type locale struct {
+	name      string // in the language itself, as shown in /idioma
+	flag      string
+	decimal   string
+	thousands string
+	money     string // layout of an amount: currency symbol, then the formatted number
+	date      string // time layouts
+	shortDate string
+	dateTime  string
+	months    [12]string
+}
+
+var locales = map[Lang]locale{
+	Portuguese: {
+		name: "Português", flag: "🇧🇷",
+		decimal: ",", thousands: ".", money: "%s %s",
+		date: "02/01/2006", shortDate: "02/01", dateTime: "02/01/2006 15:04",
+		months: [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+			"julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+	},
+	English: {
+		name: "English", flag: "🇺🇸",
+		decimal: ".", thousands: ",", money: "%s%s",
+		date: "01/02/2006", shortDate: "01/02", dateTime: "01/02/2006 3:04 PM",
+		months: [12]string{"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"},
+	},
+	Spanish: {
+		name: "Español", flag: "🇪🇸",
+		decimal: ",", thousands: ".", money: "%s %s",
+		date: "02/01/2006", shortDate: "02/01", dateTime: "02/01/2006 15:04",
+		months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+	},
+}
+
+var catalogues = map[Lang]map[string]string{
+	Portuguese: portuguese,
+	English:    english,
+	Spanish:    spanish,
+}
+
+// Parse reads a language from a Telegram language_code ("pt-br", "en-US",
+// "es-419") or from what a user types in /idioma ("en", "español").
+func Parse(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if base, _, ok := strings.Cut(strings.ReplaceAll(code, "_", "-"), "-"); ok {
+		code = base
+	}
+	switch code {
+	case "pt", "português", "portugues", "portuguese":
+		return Portuguese, true
+	case "en", "english", "inglês", "ingles", "inglés":
+		return English, true
+	case "es", "español", "espanol", "espanhol", "spanish":
+		return Spanish, true
+	}
+	return "", false
+}
+
+// FromCode returns the language of a Telegram language_code, or Default.
+func FromCode(code string) Lang {
+	if lang, ok := Parse(code); ok {
+		return lang
+	}
+	return Default
+}
+
+// Printer formats the messages of one language, and amounts in the base
+// currency of the user.
+type Printer struct {
+	lang     Lang
+	locale   locale
+	currency string
+}
+
+// New returns the Printer of lang, or of Default when lang is not supported.
+func New(lang Lang) *Printer {
+	loc, ok := locales[lang]
+	if !ok {
+		lang, loc = Default, locales[Default]
+	}
+	return &Printer{lang: lang, locale: loc, currency: currency.Default}
+}
+
+// WithCurrency returns a copy of p whose Money formats amounts in code.
+func (p *Printer) WithCurrency(code string) *Printer {
+	c := *p
+	c.currency = code
+	return &c
+}
+
+// Currency returns the ISO 4217 code of the amounts formatted by Money.
+func (p *Printer) Currency() string {
+	return p.currency
+}
+
+// Lang returns the language of the printer.
+func (p *Printer) Lang() Lang {
+	return p.lang
+}
+
+// Name returns the language's name in itself, with its flag.
+func (p *Printer) Name() string {
+	return p.locale.flag + " " + p.locale.name
+}
+
+// T formats the message key with args.
+func (p *Printer) T(key string, args ...any) string {
+	return fmt.Sprintf(p.Format(key), args...)
+}
+
+// Format returns the message key unformatted, for callers that format it
+// themselves, like render.Sprintf for HTML messages. A key missing from the
+// catalogue falls back to Portuguese, and then to the key itself.
+func (p *Printer) Format(key string) string {
+	if format, ok := catalogues[p.lang][key]; ok {
+		return format
+	}
+	if format, ok := catalogues[Default][key]; ok {
+		return format
+	}
+	slog.Warn("Missing message", "key", key, "lang", string(p.lang))
+	return key
+}
+
+// Number formats v with the given decimals and the language's separators.
+func (p *Printer) Number(v float64, decimals int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(p.locale.thousands)
+		}
+		b.WriteRune(digit)
+	}
+	if frac != "" {
+		b.WriteString(p.locale.decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// Money formats an amount in the base currency: "R$ 1.234,56" or "R$1,234.56".
+func (p *Printer) Money(v float64) string {
+	return p.Amount(v, p.currency)
+}
+
+// Amount formats an amount in the currency code: "US$ 20,00" or "US$20.00".
+func (p *Printer) Amount(v float64, code string) string {
+	return fmt.Sprintf(p.locale.money, currency.Symbol(code), p.Number(v, 2))
+}
+
+// Percent formats a ratio (0.25) as a whole percentage ("25%").
+func (p *Printer) Percent(ratio float64) string {
+	return p.Number(ratio*100, 0) + "%"
+}
+
+// Date formats the day of t.
+func (p *Printer) Date(t time.Time) string {
+	return t.Format(p.locale.date)
+}
+
+// ShortDate formats the day and month of t.
+func (p *Printer) ShortDate(t time.Time) string {
+	return t.Format(p.locale.shortDate)
+}
+
+// DateTime formats the day and time of t.
+func (p *Printer) DateTime(t time.Time) string {
+	return t.Format(p.locale.dateTime)
+}
+
+// Month returns the name of month m.
+func (p *Printer) Month(m time.Month) string {
+	return p.locale.months[m-1]
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx whose messages are formatted by p.
+func NewContext(ctx context.Context, p *Printer) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the Printer stored by NewContext, or the Default one.
+func FromContext(ctx context.Context) *Printer {
+	if p, ok := ctx.Value(contextKey{}).(*Printer); ok {
+		return p
+	}
+	return New(Default)
+}
+
+// verbPattern matches the fmt verbs of a message, "%%" included so it can be
+// skipped.
+var verbPattern = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+// Validate checks that every catalogue has exactly the keys of the Portuguese
+// one, with the same fmt verbs, so no user ever sees a key or a %!d(MISSING).
+func Validate() error {
+	var problems []string
+	base := catalogues[Default]
+	for _, lang := range Languages {
+		messages, ok := catalogues[lang]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: no catalogue", lang))
+			continue
+		}
+		for key, format := range base {
+			translated, ok := messages[key]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("%s: missing %q", lang, key))
+			case verbs(translated) != verbs(format):
+				problems = append(problems, fmt.Sprintf("%s: %q uses %s, want %s", lang, key, verbs(translated), verbs(format)))
+			}
+		}
+		for key := range messages {
+			if _, ok := base[key]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown %q", lang, key))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("i18n: %s", strings.Join(problems, "; "))
+}
+
+// verbs lists the verbs of format in argument order, so translations may
+// reorder them with explicit indexes ("%[2]s").
+func verbs(format string) string {
+	var found []string
+	next := 1
+	for _, match := range verbPattern.FindAllStringSubmatch(format, -1) {
+		if strings.HasSuffix(match[0], "%") {
+			continue
+		}
+		index := next
+		if match[1] != "" {
+			index, _ = strconv.Atoi(strings.Trim(match[1], "[]"))
+		}
+		verb := match[0][len(match[0])-1:]
+		found = append(found, fmt.Sprintf("%d:%s", index, verb))
+		next = index + 1
+	}
+	sort.Strings(found)
+	return "[" + strings.Join(found, " ") + "]"
+}
@@ -0,0 +1,76 @@
+package i18n
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestCataloguesAreComplete(t *testing.T) {
+	if err := Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateReportsBrokenCatalogues(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(messages map[string]string)
+		want   string
+	}{
+		{
+			name:   "missing key",
+			change: func(m map[string]string) { delete(m, "invoice.upcoming_item") },
+			want:   `en: missing "invoice.upcoming_item"`,
+		},
+		{
+			name:   "different verbs",
+			change: func(m map[string]string) { m["invoice.upcoming_item"] = "\n• %s | %s" },
+			want:   `en: "invoice.upcoming_item" uses`,
+		},
+		{
+			name:   "unknown key",
+			change: func(m map[string]string) { m["invoice.typo"] = "x" },
+			want:   `en: unknown "invoice.typo"`,
+		},
+	}
+
+	original := catalogues[English]
+	defer func() { catalogues[English] = original }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broken := maps.Clone(original)
+			tt.change(broken)
+			catalogues[English] = broken
+
+			err := Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want an error with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReorderedVerbs(t *testing.T) {
+	if verbs("%[2]s de %[1]d") != verbs("%[2]s %[1]d") {
+		t.Error("explicit indexes compared by position")
+	}
+	if verbs("%s %d") == verbs("%d %s") {
+		t.Error("swapped verbs not detected")
+	}
+}
+
+func TestUpcomingInvoiceMonth(t *testing.T) {
+	tests := map[Lang]string{
+		Portuguese: "março/2025",
+		English:    "March 2025",
+		Spanish:    "marzo/2025",
+	}
+	for lang, want := range tests {
+		p := New(lang)
+		if got := p.T("invoice.upcoming_item", p.Month(3), 2025, "R$ 10,00"); !strings.Contains(got, want+" | ") {
+			t.Errorf("%s: upcoming invoice %q, want %q", lang, got, want)
+		}
+	}
+}
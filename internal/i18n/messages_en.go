@@ -0,0 +1,254 @@
+package i18n
+
+// english is the English catalogue. Commands keep their Portuguese names.
+var english = map[string]string{
+	"error.timeout":        "⌛ The operation took too long and was interrupted. Please try again in a moment.\nIf you were logging an expense, check /consulta before trying again.",
+	"error.timeout_short":  "⌛ Took too long. Please try again in a moment.",
+	"error.query_expenses": "❌ Something went wrong while loading your expenses. Please try again later.",
+	"error.save_expense":   "❌ Could not save the expense. Please try again.",
+
+	"common.cancel":            "❌ Cancel",
+	"common.cancelled":         "❌ Cancelled.",
+	"common.expense_not_found": "❌ No expense found with ID %d.",
+	"common.unknown_method":    "unknown",
+
+	"start.header": "👋 Welcome to <b>Money Savior</b>!\n\n💰 Your personal assistant for tracking expenses and organizing your finances.\n\n📌 <b>Available commands:</b>\n\n",
+	"start.footer": "✨ Tip: IDs are sequential (1, 2, 3...), which makes managing your expenses easy!\n\nType /help for more details 🚀",
+
+	"help.header":      "🆘 <b>Help — Available Commands</b>\n\n🚀 <b>Main commands:</b>\n\n",
+	"help.footer":      "💡 <b>Tip:</b> IDs are sequential (1, 2, 3...). Use /consulta to check the IDs before deleting.\n\n🔙 Type <b>/start</b> to go back to the main menu.",
+	"help.start":       "<b>/start</b>  \nStarts the bot and shows the welcome message.",
+	"help.gastei":      "<b>/gastei &lt;amount&gt; &lt;category&gt; [method] [installments]</b>  \nLogs a new expense.  \nExample: /gastei 45.50 groceries debit  \nIn installments: /gastei 1200 laptop nubank 12x  \nWith a receipt: send the photo with the caption /gastei 89.90 pharmacy  \nIn another currency: /gastei 20 USD dinner nubank",
+	"help.consulta":    "<b>/consulta [ID]</b>  \nLists all your expenses with their IDs in order (1, 2, 3...).  \nWith an ID, shows the details of that expense, with ⬅️ ➡️ navigation between records.  \nExample: /consulta 3",
+	"help.grafico":     "<b>/grafico [month|year]</b>  \nSends charts of your expenses: a pie by category and bars by day (month) or by month (year).  \nExample: /grafico year",
+	"help.deletar":     "<b>/deletar &lt;ID&gt;</b>  \nDeletes one expense by its ID (asks for confirmation).  \nExample: /deletar 2",
+	"help.deletartudo": "<b>/deletartudo</b>  \nDeletes <b>all</b> your expenses (asks for confirmation).",
+	"help.metodo":      "<b>/metodo &lt;name&gt; &lt;type&gt; ...</b>  \nRegisters a payment method (credito, debito, pix or dinheiro). Without arguments, lists your methods.  \nExample: /metodo nubank credito 3 10 (closes on the 3rd, due on the 10th)",
+	"help.fatura":      "<b>/fatura &lt;card&gt;</b>  \nShows the total and due date of the card's open invoice.  \nExample: /fatura nubank",
+	"help.cotacao":     "<b>/cotacao [currency] [rate]</b>  \nShows or pins the rate used for expenses in other currencies. /cotacao base &lt;currency&gt; changes the base currency of your totals.  \nExample: /cotacao USD 5.10",
+	"help.idioma":      "<b>/idioma [pt|en|es]</b>  \nChanges the language of the bot's messages.",
+	"help.desfazer":    "<b>/desfazer</b>  \nUndoes the last action (logging, editing or deleting expenses) if it is recent. Confirmation messages also carry the ↩️ Undo button.",
+	"help.lixeira":     "<b>/lixeira</b>  \nLists recently deleted expenses, with a button to restore each one.",
+	"help.help":        "<b>/help</b>  \nShows this message.",
+
+	"commands.start":       "Start the bot and see the welcome message",
+	"commands.gastei":      "Log a new expense",
+	"commands.consulta":    "See your expenses, or one by its ID",
+	"commands.grafico":     "See your expenses in charts",
+	"commands.deletar":     "Delete an expense by its ID",
+	"commands.deletartudo": "Delete all your expenses",
+	"commands.metodo":      "Register your cards and accounts",
+	"commands.fatura":      "See the open invoice of a card",
+	"commands.cotacao":     "See or pin rates of other currencies",
+	"commands.idioma":      "Change the bot's language",
+	"commands.desfazer":    "Undo the last action",
+	"commands.lixeira":     "Restore deleted expenses",
+	"commands.help":        "See every command with examples",
+
+	"invalid.command": `❌ <b>Unknown command</b>
+
+The command <b>%s</b> does not exist in <b>Money Savior</b> 😕  
+
+📋 To see the list of available commands, type <b>/help</b>.`,
+	"invalid.tip":           "\n\n💡 Tip: check that the command was typed correctly.",
+	"invalid.did_you_mean":  "\n\n💡 Did you mean %s?",
+	"invalid.or":            " or ",
+	"invalid.run_button":    "▶️ Run %s",
+	"invalid.run_expired":   "I couldn't find the original message. Type the command again.",
+	"invalid.run_not_yours": "Only whoever typed the command can use this button.",
+
+	"expense.usage":          "⚠️ Wrong format — use: /gastei <amount> <category> [method] [installments] | Example: /gastei 21.90 uber pix or /gastei 1200 laptop nubank 12x",
+	"expense.invalid_amount": "Invalid amount, example: /gastei 21.74 uber pix",
+	"expense.saving":         "⏳ Logging your expense...",
+	"expense.saved":          "✅ Expense logged!\n\n🆔 ID: %d\n💰 Amount: %s\n📝 Description: %s\n🏷️ Category: %s\n💳 Method: %s",
+	"expense.cnpj":           "\n🏪 CNPJ: %s",
+	"expense.installments":   "\n🧾 Installments: %dx of %s",
+	"expense.suggested":      "\n\n🤖 Your history suggests the options below. Tap one to apply it.",
+	"expense.exchange":       "\n💱 Rate: 1 %s = %s %s",
+	"expense.no_rate":        "❌ I don't have the %s to %s rate. Pin one with /cotacao %s <rate> and try again.",
+
+	"query.invalid_id":        "❌ Invalid ID. Use a whole number greater than zero.\nExample: /consulta 3",
+	"query.empty":             "📝 You have not logged any expenses yet.",
+	"query.empty_short":       "📝 No expenses logged.",
+	"query.list_header":       "📋 <b>Your expenses (%d records):</b>\n\n",
+	"query.list_item":         "🆔 <b>#%d</b> | 💰 %s | 📝 %s | 💳 %s%s\n",
+	"query.list_footer":       "\n💡 Use /consulta &lt;ID&gt; to see the details of an expense.\nExample: /consulta 2",
+	"query.installment_note":  " | 🧾 installment %d/%d",
+	"query.not_found":         "❌ No expense found with ID <b>%d</b>.\nUse /consulta to see the full list.",
+	"query.card":              "📄 <b>Expense %d of %d</b>\n\n🆔 ID: <b>%d</b>\n💰 Amount: <b>%s</b>\n📝 Category: <b>%s</b>\n💳 Method: <b>%s</b>\n🕐 Date: <b>%s</b>",
+	"query.card_cnpj":         "\n🏪 CNPJ: <b>%s</b>",
+	"query.card_exchange":     "\n💱 Rate: <b>1 %s = %s %s</b>",
+	"query.card_installments": "\n🧾 Installments: <b>%dx of %s</b> (installment %d/%d)",
+	"query.previous_button":   "⬅️ Previous",
+	"query.next_button":       "Next ➡️",
+	"query.delete_button":     "🗑️ Delete #%d",
+	"query.all_button":        "📋 See all",
+	"query.receipt_button":    "🧾 See receipt",
+
+	"delete.usage":                "❌ Wrong usage. Use: /deletar <expense ID>\nExample: /deletar 3\n\nUse /consulta to see the IDs of your expenses.",
+	"delete.invalid_id":           "❌ Invalid ID. Use a whole number greater than zero.\nExample: /deletar 3",
+	"delete.not_found":            "❌ No expense found with ID %d.\nUse /consulta to see the available IDs.",
+	"delete.confirm":              "⚠️ Are you sure you want to delete this expense?\n\n🆔 ID: %d\n💰 Amount: %s\n📝 Category: %s\n💳 Method: %s",
+	"delete.confirm_installments": "\n\n🧾 All %d installments of this purchase will be deleted too.",
+	"delete.confirm_receipt":      "\n\n📎 The attached receipt stays with the expense and comes back if you restore it.",
+	"delete.confirm_button":       "✅ Yes, delete",
+	"delete.done":                 "✅ Expense #%d deleted!",
+	"delete.not_yours":            "Only whoever asked for the deletion can confirm it.",
+	"delete_all.none":             "📝 You have no expenses logged.",
+	"delete_all.confirm":          "⚠️ <b>Warning!</b> You are about to delete <b>all %d expenses</b> you logged.\n\nYou can undo it for %d minutes. Do you want to continue?",
+	"delete_all.confirm_button":   "🗑️ Yes, delete all (%d)",
+	"delete_all.failed":           "❌ Something went wrong while deleting your expenses. Please try again later.",
+	"delete_all.done":             "✅ All your expenses were deleted!",
+	"delete.trash_note":           "\n🗑️ Deleted expenses stay in /lixeira for %d days.",
+	"delete.partial":              "\n🗑️ Some of the expenses already went to /lixeira; use the button below to undo.",
+
+	"draft.register_button": "✅ Log it",
+	"draft.invalid":         "❌ Invalid proposal.",
+	"draft.saved":           "✅ Logged",
+	"draft.not_yours":       "Only whoever sent the receipt or voice message can log this expense.",
+
+	"suggest.ok_button": "✅ Looks right",
+	"suggest.not_found": "❌ Expense #%d not found.",
+	"suggest.failed":    "❌ Could not update the expense.",
+	"suggest.updated":   "✅ Updated",
+	"suggest.not_yours": "Only whoever logged the expense can change it.",
+
+	"chart.usage":       "❌ Usage: /grafico [month|year]\nExample: /grafico year",
+	"chart.month_title": "%s %d",
+	"chart.empty":       "📝 No expenses logged in %s.",
+	"chart.failed":      "❌ Could not draw the chart. Please try again later.",
+	"chart.pie_caption": "🥧 Expenses by category — %s\n💰 Total: %s\n",
+	"chart.pie_item":    "\n%s %s — %s (%s)",
+	"chart.others":      "others",
+	"chart.bars_year":   "📊 Total per month — %s (Jan → Dec)\n🔝 Top month: %s, %s",
+	"chart.bars_month":  "📊 Total per day — %s (day 1 → %d)\n🔝 Top day: %02d, %s",
+
+	"invoice.cards_failed":     "❌ Something went wrong while loading your cards. Please try again later.",
+	"invoice.no_cards":         "💳 No credit card registered.\nRegister one with: /metodo <name> credito <closing day> <due day>\nExample: /metodo nubank credito 3 10",
+	"invoice.card_not_found":   "❌ No credit card named %s.\nUse /metodo to see your registered methods.",
+	"invoice.usage":            "❌ Usage: /fatura <card>\nYour cards: %s",
+	"invoice.open_title":       "🧾 Open invoice",
+	"invoice.reminder_title":   "⏰ Reminder: invoice due in %d days",
+	"invoice.summary":          "%s — %s\n\n💰 Total: %s\n🔒 Closes on: %s\n📅 Due on: %s\n🧮 Entries: %d",
+	"invoice.item":             "\n• %s | %s | %s",
+	"invoice.item_installment": " (installment %d/%d)",
+	"invoice.upcoming":         "\n\n📆 Upcoming invoices (due date):",
+	"invoice.upcoming_item":    "\n• %s %d | %s",
+
+	"nfce.invalid_link":     "❌ This link does not look like an NFC-e.",
+	"nfce.already_imported": "ℹ️ This receipt was already imported as expense #%d.",
+	"nfce.no_total":         "❌ Could not get the amount of this receipt from the SEFAZ website. Please try again later or log it with /gastei.",
+
+	"method.help":          "Use:\n/metodo <name> credito <closing day> <due day>\n/metodo <name> debito [account]\n/metodo <name> pix [account]\n/metodo <name> dinheiro\n/metodo remover <name>\n\nExample: /metodo nubank credito 3 10",
+	"method.usage":         "❌ Wrong usage. %s",
+	"method.not_found":     "❌ No method registered with the name %s.",
+	"method.remove_failed": "❌ Could not remove the method. Please try again.",
+	"method.removed":       "✅ Method %s removed.",
+	"method.invalid_days":  "❌ Invalid days. Use numbers between 1 and 31.\nExample: /metodo nubank credito 3 10",
+	"method.name_too_long": "❌ Method names can have up to %d characters.",
+	"method.save_failed":   "❌ Could not save the method. Please try again.",
+	"method.saved":         "✅ Method registered!\n\n%s\n\n💡 Use its name in /gastei to link the expense. Example: /gastei 50 groceries %s",
+	"method.query_failed":  "❌ Something went wrong while loading your methods. Please try again later.",
+	"method.none":          "💳 You have not registered any payment method yet.\n\n%s",
+	"method.list_header":   "💳 Your payment methods (%d):\n\n",
+	"method.list_footer":   "\n💡 Use /fatura <card> to see the open invoice of a credit card.",
+	"method.credit":        "💳 %s — credit | closes on day %d | due on day %d",
+	"method.debit":         "🏦 %s — debit | account %s",
+	"method.pix":           "⚡ %s — pix | account %s",
+	"method.other":         "💵 %s — %s",
+
+	"rate.help":              "Use:\n/cotacao <currency> — rate in use\n/cotacao <currency> <rate> — pins the rate\n/cotacao remover <currency> — goes back to the cached rate\n/cotacao base <currency> — changes the base currency\n\nExample: /cotacao USD 5.10",
+	"rate.usage":             "❌ Wrong usage. %s",
+	"rate.query_failed":      "❌ Something went wrong while loading your rates. Please try again later.",
+	"rate.header":            "💱 Base currency: %s\n\n",
+	"rate.none_pinned":       "No pinned rates: I use the cached rates.\n",
+	"rate.pinned_header":     "📌 Pinned rates:\n",
+	"rate.pinned_item":       "• 1 %s = %s %s\n",
+	"rate.unknown_currency":  "❌ Unsupported currency: %s.\nUse the 3-letter code, like USD or EUR.",
+	"rate.is_base":           "❌ %s is already your base currency.",
+	"rate.no_rate":           "❌ I don't have the %s to %s rate. Pin one with /cotacao %s <rate>.",
+	"rate.current":           "💱 1 %s = %s %s (%s)",
+	"rate.source_cache":      "cached rate",
+	"rate.source_pinned":     "pinned by you",
+	"rate.invalid_value":     "❌ Invalid rate. Use a number greater than zero.\nExample: /cotacao USD 5.10",
+	"rate.saved":             "✅ Rate pinned: 1 %s = %s %s.\nYour next expenses in this currency use it.",
+	"rate.removed":           "✅ %s rate unpinned. I'm back to the cached rate.",
+	"rate.not_pinned":        "❌ No rate pinned for %s.",
+	"rate.base_changed":      "✅ Your base currency is now %s.\nYour pinned rates were cleared.",
+	"rate.base_has_expenses": "❌ Your expenses are logged in %s, so the base currency can't change: the amounts already saved would be read as %s.\nThe base currency can only be switched with no expenses logged, not even in the trash (it empties itself after %d days).",
+	"rate.save_failed":       "❌ Could not save the rate. Please try again.",
+
+	"receipt.hint":        "🧾 To keep a receipt, send the photo with the caption /gastei <amount> <category> [method] or reply to an expense message with the photo.",
+	"receipt.read_failed": "❌ Could not read the receipt.\n\n%s",
+	"receipt.no_total":    "🤔 Could not find the total on this receipt.\n\n%s",
+	"receipt.read":        "🧾 I read the receipt:\n\n",
+	"receipt.total":       "💰 Total: %s\n",
+	"receipt.merchant":    "🏪 Store: %s\n",
+	"receipt.date":        "📅 Date: %s\n",
+	"receipt.confirm":     "\nLog it as %s?",
+	"receipt.save_failed": "❌ Could not save the receipt. Please try again.",
+	"receipt.attached":    "🧾 Receipt attached to expense #%d.\nUse /consulta %d to see it.",
+	"receipt.not_found":   "❌ Receipt not found.",
+	"receipt.not_yours":   "Only whoever logged expense #%d can see or attach its receipt.",
+	"receipt.caption":     "🧾 Receipt of expense #%d — %s | %s",
+
+	"voice.too_long":          "🎙️ Audio too long. Send a recording of up to %d seconds, in Portuguese, for example: \"gastei vinte reais de uber no pix\".",
+	"voice.download_failed":   "❌ Could not download the audio. Please try again.",
+	"voice.unavailable":       "🎙️ Audio transcription is not available right now. Use /gastei <amount> <category> [method].",
+	"voice.transcribe_failed": "❌ Could not understand the audio. Please try again.",
+	"voice.no_amount":         "🎙️ I heard: \"%s\"\n\n🤔 But I could not find the amount. Try something like \"gastei vinte reais de uber no pix\".",
+	"voice.proposal":          "🎙️ I heard: \"%s\"\n\n💰 Amount: %s\n📝 Description: %s\n💳 Method: %s\n\nLog it as %s?",
+	"voice.method_unknown":    "not given (I will suggest one from your history)",
+
+	"ratelimit.message":  "⏳ Easy! You sent too many commands in a short time.\nPlease try again in %s.",
+	"ratelimit.callback": "⏳ Too many taps. Please try again in %s.",
+
+	"wait.second":  "1 second",
+	"wait.seconds": "%d seconds",
+	"wait.minute":  "1 minute",
+	"wait.minutes": "%d minutes",
+
+	"language.choose":      "🌐 Current language: %s\n\nChoose the language of the messages:",
+	"language.changed":     "✅ Done! I will talk to you in %s from now on.",
+	"language.invalid":     "❌ Unsupported language: %s.\nUse /idioma pt, /idioma en or /idioma es.",
+	"language.save_failed": "❌ Could not save the language. Please try again.",
+
+	"undo.button":      "↩️ Undo",
+	"undo.nothing":     "🤷 There is nothing to undo. Only the last action of the last %d minutes can be undone.",
+	"undo.expired":     "This action can no longer be undone.",
+	"undo.not_yours":   "Only whoever made the action can undo it.",
+	"undo.failed":      "❌ Couldn't undo. Please try again.",
+	"undo.saved":       "↩️ Undone: expense #%d was removed.",
+	"undo.edited":      "↩️ Undone: expense #%d is back to how it was.",
+	"undo.deleted":     "↩️ Undone: expense #%d was restored.",
+	"undo.deleted_all": "↩️ Undone: %d expenses were restored.",
+	"undo.restored":    "↩️ Undone: expense #%d is back in the trash.",
+
+	"trash.header":         "🗑️ <b>Trash</b> — expenses deleted in the last %d days:\n\n",
+	"trash.item":           "🆔 <b>#%d</b> | 💰 %s | 📝 %s | 🗑️ %s\n",
+	"trash.more":           "\n… and %d more. Restore some to see the others.\n",
+	"trash.footer":         "\n💡 Tap a button to restore the expense.",
+	"trash.empty":          "🗑️ The trash is empty. Deleted expenses stay here for %d days.",
+	"trash.restore_button": "♻️ Restore #%d",
+	"trash.restored":       "♻️ Expense #%d restored.",
+	"trash.not_found":      "This expense is no longer in the trash.",
+	"trash.not_yours":      "Only the owner of the trash can restore these expenses.",
+	"trash.failed":         "❌ Couldn't read the trash. Please try again.",
+	"trash.restore_failed": "❌ Couldn't restore the expense.",
+
+	"inline.hint":                      "💡 Type an expense (25 coffee pix) or \"summary\"",
+	"inline.expense_title":             "💸 Log %s",
+	"inline.expense_description":       "%s · %s — the amount isn't shown in the chat",
+	"inline.expense_share_title":       "📢 Log and show in the chat",
+	"inline.expense_share_description": "Sends the details of the expense to the conversation",
+	"inline.expense_pending":           "⏳ Logging expense in Money Savior...",
+	"inline.expense_saved":             "✅ Expense logged in Money Savior.",
+	"inline.expense_failed":            "❌ The expense couldn't be logged. Try again or use /gastei in the chat with the bot.",
+	"inline.open_button":               "💰 Open Money Savior",
+	"inline.summary_title":             "📊 Summary of %s",
+	"inline.summary_description":       "%s in %d expenses",
+	"inline.summary":                   "📊 <b>Summary of %s</b>\n\n💰 Total: <b>%s</b>\n🧾 Expenses: <b>%d</b>\n",
+	"inline.summary_categories":        "\n🏷️ <b>Top categories:</b>\n",
+	"inline.summary_item":              "• %s: <b>%s</b> (%s)\n",
+	"inline.summary_empty":             "📊 No expenses logged in %s.",
+}
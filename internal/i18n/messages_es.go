@@ -0,0 +1,261 @@
+package i18n
+
+// spanish is the Spanish catalogue. Commands keep their Portuguese names.
+var spanish = map[string]string{
+	"error.timeout":        "⌛ La operación tardó demasiado y fue interrumpida. Inténtalo de nuevo en unos instantes.\nSi estabas registrando un gasto, revisa /consulta antes de repetirlo.",
+	"error.timeout_short":  "⌛ Tardó demasiado. Inténtalo de nuevo en unos instantes.",
+	"error.query_expenses": "❌ Ocurrió un error al consultar tus gastos. Inténtalo de nuevo más tarde.",
+	"error.save_expense":   "❌ Error al guardar el gasto. Inténtalo de nuevo.",
+
+	"common.cancel":            "❌ Cancelar",
+	"common.cancelled":         "❌ Operación cancelada.",
+	"common.expense_not_found": "❌ No se encontró ningún gasto con el ID %d.",
+	"common.unknown_method":    "desconocido",
+
+	"start.header": "👋 ¡Bienvenido a <b>Money Savior</b>!\n\n💰 Tu asistente personal para controlar gastos y organizar tus finanzas.\n\n📌 <b>Comandos disponibles:</b>\n\n",
+	"start.footer": "✨ Consejo: los IDs son secuenciales (1, 2, 3...), ¡así es fácil administrar tus gastos!\n\nEscribe /help para más detalles 🚀",
+
+	"help.header":      "🆘 <b>Ayuda — Comandos Disponibles</b>\n\n🚀 <b>Comandos principales:</b>\n\n",
+	"help.footer":      "💡 <b>Consejo:</b> Los IDs son secuenciales (1, 2, 3...). Usa /consulta para ver los IDs antes de borrar.\n\n🔙 Escribe <b>/start</b> para volver al menú inicial.",
+	"help.start":       "<b>/start</b>  \nInicia el bot y muestra el mensaje de bienvenida.",
+	"help.gastei":      "<b>/gastei &lt;valor&gt; &lt;categoría&gt; [método] [cuotas]</b>  \nRegistra un nuevo gasto.  \nEjemplo: /gastei 45,50 supermercado débito  \nEn cuotas: /gastei 1200 portátil nubank 12x  \nCon comprobante: envía la foto con el pie de foto /gastei 89,90 farmacia  \nEn otra moneda: /gastei 20 USD cena nubank",
+	"help.consulta":    "<b>/consulta [ID]</b>  \nMuestra todos tus gastos con sus IDs en orden (1, 2, 3...).  \nCon un ID, muestra los detalles del gasto, con navegación ⬅️ ➡️ entre registros.  \nEjemplo: /consulta 3",
+	"help.grafico":     "<b>/grafico [mes|año]</b>  \nEnvía gráficos de tus gastos: torta por categoría y barras por día (mes) o por mes (año).  \nEjemplo: /grafico año",
+	"help.deletar":     "<b>/deletar &lt;ID&gt;</b>  \nBorra un gasto por su ID (con confirmación).  \nEjemplo: /deletar 2",
+	"help.deletartudo": "<b>/deletartudo</b>  \nBorra <b>todos</b> tus gastos (con confirmación).",
+	"help.metodo":      "<b>/metodo &lt;nombre&gt; &lt;tipo&gt; ...</b>  \nRegistra un método de pago (credito, debito, pix o dinheiro). Sin argumentos, lista tus métodos.  \nEjemplo: /metodo nubank credito 3 10 (cierra el día 3, vence el día 10)",
+	"help.fatura":      "<b>/fatura &lt;tarjeta&gt;</b>  \nMuestra el total y el vencimiento de la factura abierta de la tarjeta.  \nEjemplo: /fatura nubank",
+	"help.cotacao":     "<b>/cotacao [moneda] [valor]</b>  \nMuestra o fija la cotización usada en los gastos en otras monedas. /cotacao base &lt;moneda&gt; cambia la moneda base de tus totales.  \nEjemplo: /cotacao USD 5,10",
+	"help.idioma":      "<b>/idioma [pt|en|es]</b>  \nCambia el idioma de los mensajes del bot.",
+	"help.desfazer":    "<b>/desfazer</b>  \nDeshace la última acción (registro, edición o borrado de gastos) si es reciente. Los mensajes de confirmación también traen el botón ↩️ Deshacer.",
+	"help.lixeira":     "<b>/lixeira</b>  \nLista los gastos borrados recientemente, con un botón para restaurar cada uno.",
+	"help.help":        "<b>/help</b>  \nMuestra este mensaje.",
+
+	"commands.start":       "Inicia el bot y muestra la bienvenida",
+	"commands.gastei":      "Registra un nuevo gasto",
+	"commands.consulta":    "Mira tus gastos o uno por su ID",
+	"commands.grafico":     "Mira tus gastos en gráficos",
+	"commands.deletar":     "Borra un gasto por su ID",
+	"commands.deletartudo": "Borra todos tus gastos",
+	"commands.metodo":      "Registra tus tarjetas y cuentas",
+	"commands.fatura":      "Mira la factura abierta de la tarjeta",
+	"commands.cotacao":     "Mira o fija cotizaciones de otras monedas",
+	"commands.idioma":      "Cambia el idioma del bot",
+	"commands.desfazer":    "Deshaz la última acción",
+	"commands.lixeira":     "Restaura gastos borrados",
+	"commands.help":        "Mira todos los comandos con ejemplos",
+
+	"invalid.command": `❌ <b>Comando no reconocido</b>
+
+El comando <b>%s</b> no existe en <b>Money Savior</b> 😕  
+
+📋 Para ver la lista de comandos disponibles, escribe <b>/help</b>.`,
+	"invalid.tip":           "\n\n💡 Consejo: revisa que el comando esté bien escrito.",
+	"invalid.did_you_mean":  "\n\n💡 ¿Quisiste decir %s?",
+	"invalid.or":            " o ",
+	"invalid.run_button":    "▶️ Ejecutar %s",
+	"invalid.run_expired":   "No encontré el mensaje original. Escribe el comando de nuevo.",
+	"invalid.run_not_yours": "Solo quien escribió el comando puede usar este botón.",
+
+	"expense.usage":          "⚠️ Formato incorrecto — usa: /gastei <valor> <categoría> [método] [cuotas] | Ejemplo: /gastei 21,90 uber pix o /gastei 1200 portátil nubank 12x",
+	"expense.invalid_amount": "Valor inválido, ejemplo: /gastei 21,74 uber pix",
+	"expense.saving":         "⏳ Registrando tu gasto...",
+	"expense.saved":          "✅ ¡Gasto registrado con éxito!\n\n🆔 ID: %d\n💰 Valor: %s\n📝 Descripción: %s\n🏷️ Categoría: %s\n💳 Método: %s",
+	"expense.cnpj":           "\n🏪 CNPJ: %s",
+	"expense.installments":   "\n🧾 En cuotas: %dx de %s",
+	"expense.suggested":      "\n\n🤖 Tu historial sugiere las opciones de abajo. Toca una para aplicarla.",
+	"expense.exchange":       "\n💱 Cotización: 1 %s = %s %s",
+	"expense.no_rate":        "❌ No tengo la cotización de %s a %s. Fija una con /cotacao %s <valor> e inténtalo de nuevo.",
+
+	"query.invalid_id":        "❌ ID inválido. Usa un número entero mayor que cero.\nEjemplo: /consulta 3",
+	"query.empty":             "📝 Todavía no registraste ningún gasto.",
+	"query.empty_short":       "📝 Ningún gasto registrado.",
+	"query.list_header":       "📋 <b>Tus gastos (%d registros):</b>\n\n",
+	"query.list_item":         "🆔 <b>#%d</b> | 💰 %s | 📝 %s | 💳 %s%s\n",
+	"query.list_footer":       "\n💡 Usa /consulta &lt;ID&gt; para ver los detalles de un gasto.\nEjemplo: /consulta 2",
+	"query.installment_note":  " | 🧾 cuota %d/%d",
+	"query.not_found":         "❌ No se encontró ningún gasto con el ID <b>%d</b>.\nUsa /consulta para ver la lista completa.",
+	"query.card":              "📄 <b>Gasto %d de %d</b>\n\n🆔 ID: <b>%d</b>\n💰 Valor: <b>%s</b>\n📝 Categoría: <b>%s</b>\n💳 Método: <b>%s</b>\n🕐 Fecha: <b>%s</b>",
+	"query.card_cnpj":         "\n🏪 CNPJ: <b>%s</b>",
+	"query.card_exchange":     "\n💱 Cotización: <b>1 %s = %s %s</b>",
+	"query.card_installments": "\n🧾 Cuotas: <b>%dx de %s</b> (cuota %d/%d)",
+	"query.previous_button":   "⬅️ Anterior",
+	"query.next_button":       "Siguiente ➡️",
+	"query.delete_button":     "🗑️ Borrar #%d",
+	"query.all_button":        "📋 Ver todos",
+	"query.receipt_button":    "🧾 Ver comprobante",
+
+	"delete.usage":                "❌ Uso incorrecto. Usa: /deletar <ID del gasto>\nEjemplo: /deletar 3\n\nUsa /consulta para ver los IDs de tus gastos.",
+	"delete.invalid_id":           "❌ ID inválido. Usa un número entero mayor que cero.\nEjemplo: /deletar 3",
+	"delete.not_found":            "❌ No se encontró ningún gasto con el ID %d.\nUsa /consulta para ver los IDs disponibles.",
+	"delete.confirm":              "⚠️ ¿Seguro que quieres borrar este gasto?\n\n🆔 ID: %d\n💰 Valor: %s\n📝 Categoría: %s\n💳 Método: %s",
+	"delete.confirm_installments": "\n\n🧾 Las %d cuotas de esta compra también serán borradas.",
+	"delete.confirm_receipt":      "\n\n📎 El comprobante adjunto se guarda con el gasto y vuelve si lo restauras.",
+	"delete.confirm_button":       "✅ Sí, borrar",
+	"delete.done":                 "✅ ¡Gasto #%d borrado con éxito!",
+	"delete.not_yours":            "Solo quien pidió borrar puede confirmarlo.",
+	"delete_all.none":             "📝 No tienes ningún gasto registrado.",
+	"delete_all.confirm":          "⚠️ <b>¡Atención!</b> Estás a punto de borrar <b>los %d gastos</b> registrados.\n\nPodrás deshacerlo durante %d minutos. ¿Quieres continuar?",
+	"delete_all.confirm_button":   "🗑️ Sí, borrar todos (%d)",
+	"delete_all.failed":           "❌ Ocurrió un error al borrar los gastos. Inténtalo de nuevo más tarde.",
+	"delete_all.done":             "✅ ¡Todos los gastos fueron borrados con éxito!",
+	"delete.trash_note":           "\n🗑️ Los gastos borrados quedan en /lixeira durante %d días.",
+	"delete.partial":              "\n🗑️ Parte de los gastos ya fue a la /lixeira; usa el botón de abajo para deshacer.",
+
+	"draft.register_button": "✅ Registrar",
+	"draft.invalid":         "❌ Propuesta inválida.",
+	"draft.saved":           "✅ Registrado",
+	"draft.not_yours":       "Solo quien envió el comprobante o el audio puede registrar este gasto.",
+
+	"suggest.ok_button": "✅ Está bien",
+	"suggest.not_found": "❌ Gasto #%d no encontrado.",
+	"suggest.failed":    "❌ Error al actualizar el gasto.",
+	"suggest.updated":   "✅ Actualizado",
+	"suggest.not_yours": "Solo quien registró el gasto puede cambiarlo.",
+
+	"chart.usage":       "❌ Uso: /grafico [mes|año]\nEjemplo: /grafico año",
+	"chart.month_title": "%s/%d",
+	"chart.empty":       "📝 Ningún gasto registrado en %s.",
+	"chart.failed":      "❌ No fue posible generar el gráfico. Inténtalo de nuevo más tarde.",
+	"chart.pie_caption": "🥧 Gastos por categoría — %s\n💰 Total: %s\n",
+	"chart.pie_item":    "\n%s %s — %s (%s)",
+	"chart.others":      "otros",
+	"chart.bars_year":   "📊 Total por mes — %s (ene → dic)\n🔝 Mes más alto: %s, %s",
+	"chart.bars_month":  "📊 Total por día — %s (día 1 → %d)\n🔝 Día más alto: %02d, %s",
+
+	"invoice.cards_failed":     "❌ Ocurrió un error al consultar tus tarjetas. Inténtalo de nuevo más tarde.",
+	"invoice.no_cards":         "💳 Ninguna tarjeta de crédito registrada.\nRegistra una con: /metodo <nombre> credito <día de cierre> <día de vencimiento>\nEjemplo: /metodo nubank credito 3 10",
+	"invoice.card_not_found":   "❌ Ninguna tarjeta de crédito llamada %s.\nUsa /metodo para ver tus métodos registrados.",
+	"invoice.usage":            "❌ Uso: /fatura <tarjeta>\nTus tarjetas: %s",
+	"invoice.open_title":       "🧾 Factura abierta",
+	"invoice.reminder_title":   "⏰ Recordatorio: la factura vence en %d días",
+	"invoice.summary":          "%s — %s\n\n💰 Total: %s\n🔒 Cierra el: %s\n📅 Vence el: %s\n🧮 Movimientos: %d",
+	"invoice.item":             "\n• %s | %s | %s",
+	"invoice.item_installment": " (cuota %d/%d)",
+	"invoice.upcoming":         "\n\n📆 Próximas facturas (vencimiento):",
+	"invoice.upcoming_item":    "\n• %s/%d | %s",
+
+	"nfce.invalid_link":     "❌ Este enlace no parece ser de una NFC-e.",
+	"nfce.already_imported": "ℹ️ Esta nota ya fue importada como el gasto #%d.",
+	"nfce.no_total":         "❌ No pude obtener el valor de esta nota en el sitio de la SEFAZ. Inténtalo de nuevo más tarde o regístrala con /gastei.",
+
+	"method.help": `Usa:
+/metodo <nombre> credito <día de cierre> <día de vencimiento>
+/metodo <nombre> debito [cuenta]
+/metodo <nombre> pix [cuenta]
+/metodo <nombre> dinheiro
+/metodo remover <nombre>
+
+Ejemplo: /metodo nubank credito 3 10`,
+	"method.usage":         "❌ Uso incorrecto. %s",
+	"method.not_found":     "❌ Ningún método registrado con el nombre %s.",
+	"method.remove_failed": "❌ Error al eliminar el método. Inténtalo de nuevo.",
+	"method.removed":       "✅ Método %s eliminado.",
+	"method.invalid_days":  "❌ Días inválidos. Usa números entre 1 y 31.\nEjemplo: /metodo nubank credito 3 10",
+	"method.name_too_long": "❌ El nombre del método puede tener hasta %d caracteres.",
+	"method.save_failed":   "❌ Error al guardar el método. Inténtalo de nuevo.",
+	"method.saved":         "✅ ¡Método registrado!\n\n%s\n\n💡 Usa el nombre en /gastei para vincular el gasto. Ejemplo: /gastei 50 mercado %s",
+	"method.query_failed":  "❌ Ocurrió un error al consultar tus métodos. Inténtalo de nuevo más tarde.",
+	"method.none":          "💳 Todavía no registraste ningún método de pago.\n\n%s",
+	"method.list_header":   "💳 Tus métodos de pago (%d):\n\n",
+	"method.list_footer":   "\n💡 Usa /fatura <tarjeta> para ver la factura abierta de una tarjeta de crédito.",
+	"method.credit":        "💳 %s — crédito | cierra el día %d | vence el día %d",
+	"method.debit":         "🏦 %s — débito | cuenta %s",
+	"method.pix":           "⚡ %s — pix | cuenta %s",
+	"method.other":         "💵 %s — %s",
+
+	"rate.help":              "Usa:\n/cotacao <moneda> — cotización en uso\n/cotacao <moneda> <valor> — fija la cotización\n/cotacao remover <moneda> — vuelve a la cotización en caché\n/cotacao base <moneda> — cambia la moneda base\n\nEjemplo: /cotacao USD 5,10",
+	"rate.usage":             "❌ Uso incorrecto. %s",
+	"rate.query_failed":      "❌ Ocurrió un error al consultar tus cotizaciones. Inténtalo de nuevo más tarde.",
+	"rate.header":            "💱 Moneda base: %s\n\n",
+	"rate.none_pinned":       "Ninguna cotización fijada: uso la cotización en caché.\n",
+	"rate.pinned_header":     "📌 Cotizaciones fijadas:\n",
+	"rate.pinned_item":       "• 1 %s = %s %s\n",
+	"rate.unknown_currency":  "❌ Moneda no soportada: %s.\nUsa el código de 3 letras, como USD o EUR.",
+	"rate.is_base":           "❌ %s ya es tu moneda base.",
+	"rate.no_rate":           "❌ No tengo la cotización de %s a %s. Fija una con /cotacao %s <valor>.",
+	"rate.current":           "💱 1 %s = %s %s (%s)",
+	"rate.source_cache":      "cotización en caché",
+	"rate.source_pinned":     "fijada por ti",
+	"rate.invalid_value":     "❌ Cotización inválida. Usa un número mayor que cero.\nEjemplo: /cotacao USD 5,10",
+	"rate.saved":             "✅ Cotización fijada: 1 %s = %s %s.\nLos próximos gastos en esa moneda la usan.",
+	"rate.removed":           "✅ Cotización de %s eliminada. Vuelvo a usar la cotización en caché.",
+	"rate.not_pinned":        "❌ Ninguna cotización fijada para %s.",
+	"rate.base_changed":      "✅ Tu moneda base ahora es %s.\nLas cotizaciones fijadas se borraron.",
+	"rate.base_has_expenses": "❌ Tus gastos están registrados en %s, así que la moneda base no puede cambiar: los valores ya guardados se leerían como %s.\nLa moneda base solo se puede cambiar sin ningún gasto registrado, ni en la papelera (se vacía sola en %d días).",
+	"rate.save_failed":       "❌ Error al guardar la cotización. Inténtalo de nuevo.",
+
+	"receipt.hint":        "🧾 Para guardar un comprobante, envía la foto con el pie de foto /gastei <valor> <categoría> [método] o responde al mensaje de un gasto con la foto.",
+	"receipt.read_failed": "❌ No pude leer el comprobante.\n\n%s",
+	"receipt.no_total":    "🤔 No encontré el valor total en este comprobante.\n\n%s",
+	"receipt.read":        "🧾 Leí el comprobante:\n\n",
+	"receipt.total":       "💰 Total: %s\n",
+	"receipt.merchant":    "🏪 Comercio: %s\n",
+	"receipt.date":        "📅 Fecha: %s\n",
+	"receipt.confirm":     "\n¿Registrar como %s?",
+	"receipt.save_failed": "❌ Error al guardar el comprobante. Inténtalo de nuevo.",
+	"receipt.attached":    "🧾 Comprobante adjuntado al gasto #%d.\nUsa /consulta %d para verlo.",
+	"receipt.not_found":   "❌ Comprobante no encontrado.",
+	"receipt.not_yours":   "Solo quien registró el gasto #%d puede ver o adjuntar el comprobante.",
+	"receipt.caption":     "🧾 Comprobante del gasto #%d — %s | %s",
+
+	"voice.too_long":          "🎙️ Audio demasiado largo. Envía un audio de hasta %d segundos, en portugués, por ejemplo: \"gastei vinte reais de uber no pix\".",
+	"voice.download_failed":   "❌ No pude descargar el audio. Inténtalo de nuevo.",
+	"voice.unavailable":       "🎙️ La transcripción de audio no está disponible en este momento. Usa /gastei <valor> <categoría> [método].",
+	"voice.transcribe_failed": "❌ No pude entender el audio. Inténtalo de nuevo.",
+	"voice.no_amount":         "🎙️ Entendí: \"%s\"\n\n🤔 Pero no encontré el valor. Prueba algo como \"gastei vinte reais de uber no pix\".",
+	"voice.proposal":          "🎙️ Entendí: \"%s\"\n\n💰 Valor: %s\n📝 Descripción: %s\n💳 Método: %s\n\n¿Registrar como %s?",
+	"voice.method_unknown":    "no informado (lo sugeriré según tu historial)",
+
+	"ratelimit.message":  "⏳ ¡Calma! Enviaste muchos comandos en poco tiempo.\nInténtalo de nuevo en %s.",
+	"ratelimit.callback": "⏳ Demasiados clics. Inténtalo de nuevo en %s.",
+
+	"wait.second":  "1 segundo",
+	"wait.seconds": "%d segundos",
+	"wait.minute":  "1 minuto",
+	"wait.minutes": "%d minutos",
+
+	"language.choose":      "🌐 Idioma actual: %s\n\nElige el idioma de los mensajes:",
+	"language.changed":     "✅ ¡Listo! Desde ahora te hablaré en %s.",
+	"language.invalid":     "❌ Idioma no soportado: %s.\nUsa /idioma pt, /idioma en o /idioma es.",
+	"language.save_failed": "❌ Error al guardar el idioma. Inténtalo de nuevo.",
+
+	"undo.button":      "↩️ Deshacer",
+	"undo.nothing":     "🤷 No hay nada que deshacer. Solo se puede deshacer la última acción de los últimos %d minutos.",
+	"undo.expired":     "Esta acción ya no se puede deshacer.",
+	"undo.not_yours":   "Solo quien hizo la acción puede deshacerla.",
+	"undo.failed":      "❌ Error al deshacer. Inténtalo de nuevo.",
+	"undo.saved":       "↩️ Deshecho: se quitó el gasto #%d.",
+	"undo.edited":      "↩️ Deshecho: el gasto #%d volvió a ser como era.",
+	"undo.deleted":     "↩️ Deshecho: se restauró el gasto #%d.",
+	"undo.deleted_all": "↩️ Deshecho: se restauraron %d gastos.",
+	"undo.restored":    "↩️ Deshecho: el gasto #%d volvió a la papelera.",
+
+	"trash.header":         "🗑️ <b>Papelera</b> — gastos borrados en los últimos %d días:\n\n",
+	"trash.item":           "🆔 <b>#%d</b> | 💰 %s | 📝 %s | 🗑️ %s\n",
+	"trash.more":           "\n… y %d más. Restaura algunos para ver los demás.\n",
+	"trash.footer":         "\n💡 Toca un botón para restaurar el gasto.",
+	"trash.empty":          "🗑️ La papelera está vacía. Los gastos borrados quedan aquí durante %d días.",
+	"trash.restore_button": "♻️ Restaurar #%d",
+	"trash.restored":       "♻️ Gasto #%d restaurado.",
+	"trash.not_found":      "Este gasto ya no está en la papelera.",
+	"trash.not_yours":      "Solo el dueño de la papelera puede restaurar estos gastos.",
+	"trash.failed":         "❌ Error al consultar la papelera. Inténtalo de nuevo.",
+	"trash.restore_failed": "❌ Error al restaurar el gasto.",
+
+	"inline.hint":                      "💡 Escribe un gasto (25 café pix) o \"resumen\"",
+	"inline.expense_title":             "💸 Registrar %s",
+	"inline.expense_description":       "%s · %s — el valor no aparece en el chat",
+	"inline.expense_share_title":       "📢 Registrar y mostrar en el chat",
+	"inline.expense_share_description": "Envía los detalles del gasto a la conversación",
+	"inline.expense_pending":           "⏳ Registrando gasto en Money Savior...",
+	"inline.expense_saved":             "✅ Gasto registrado en Money Savior.",
+	"inline.expense_failed":            "❌ No se pudo registrar el gasto. Inténtalo de nuevo o usa /gastei en el chat con el bot.",
+	"inline.open_button":               "💰 Abrir Money Savior",
+	"inline.summary_title":             "📊 Resumen de %s",
+	"inline.summary_description":       "%s en %d gastos",
+	"inline.summary":                   "📊 <b>Resumen de %s</b>\n\n💰 Total: <b>%s</b>\n🧾 Gastos: <b>%d</b>\n",
+	"inline.summary_categories":        "\n🏷️ <b>Categorías principales:</b>\n",
+	"inline.summary_item":              "• %s: <b>%s</b> (%s)\n",
+	"inline.summary_empty":             "📊 Ningún gasto registrado en %s.",
+}
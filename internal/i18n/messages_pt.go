@@ -0,0 +1,262 @@
+package i18n
+
+// portuguese is the reference catalogue: the other languages must have its
+// keys, with the same fmt verbs.
+var portuguese = map[string]string{
+	"error.timeout":        "⌛ A operação demorou demais e foi interrompida. Tente novamente em instantes.\nSe você estava registrando um gasto, confira com /consulta antes de repetir.",
+	"error.timeout_short":  "⌛ Demorou demais. Tente novamente em instantes.",
+	"error.query_expenses": "❌ Ocorreu um erro ao consultar seus gastos. Tente novamente mais tarde.",
+	"error.save_expense":   "❌ Erro ao salvar gasto. Tente novamente.",
+
+	"common.cancel":            "❌ Cancelar",
+	"common.cancelled":         "❌ Operação cancelada.",
+	"common.expense_not_found": "❌ Nenhum gasto encontrado com o ID %d.",
+	"common.unknown_method":    "desconhecido",
+
+	"start.header": "👋 Bem-vindo ao <b>Money Savior</b>!\n\n💰 Seu assistente pessoal para controle de gastos e organização financeira.\n\n📌 <b>Comandos disponíveis:</b>\n\n",
+	"start.footer": "✨ Dica: os IDs são sequenciais (1, 2, 3...), facilitando o gerenciamento!\n\nDigite /help para mais detalhes 🚀",
+
+	"help.header":      "🆘 <b>Ajuda — Comandos Disponíveis</b>\n\n🚀 <b>Comandos principais:</b>\n\n",
+	"help.footer":      "💡 <b>Dica:</b> Os IDs são sequenciais (1, 2, 3...). Use /consulta para ver os IDs antes de deletar.\n\n🔙 Digite <b>/start</b> para voltar ao menu inicial.",
+	"help.start":       "<b>/start</b>  \nInicia o bot e exibe a mensagem de boas-vindas.",
+	"help.gastei":      "<b>/gastei &lt;valor&gt; &lt;categoria&gt; [método] [parcelas]</b>  \nRegistra uma nova despesa.  \nExemplo: /gastei 45.50 supermercado débito  \nParcelado: /gastei 1200 notebook nubank 12x  \nCom comprovante: envie a foto com a legenda /gastei 89,90 farmácia  \nEm outra moeda: /gastei 20 USD jantar nubank",
+	"help.consulta":    "<b>/consulta [ID]</b>  \nExibe todos os seus gastos com IDs em ordem (1, 2, 3...).  \nCom um ID, mostra os detalhes do gasto com navegação ⬅️ ➡️ entre registros.  \nExemplo: /consulta 3",
+	"help.grafico":     "<b>/grafico [mês|ano]</b>  \nEnvia gráficos dos seus gastos: pizza por categoria e barras por dia (mês) ou por mês (ano).  \nExemplo: /grafico ano",
+	"help.deletar":     "<b>/deletar &lt;ID&gt;</b>  \nDeleta um gasto específico pelo ID (com confirmação).  \nExemplo: /deletar 2",
+	"help.deletartudo": "<b>/deletartudo</b>  \nDeleta <b>todos</b> os gastos registrados (com confirmação).",
+	"help.metodo":      "<b>/metodo &lt;nome&gt; &lt;tipo&gt; ...</b>  \nCadastra um método de pagamento (credito, debito, pix ou dinheiro). Sem argumentos, lista os métodos.  \nExemplo: /metodo nubank credito 3 10 (fecha dia 3, vence dia 10)",
+	"help.fatura":      "<b>/fatura &lt;cartão&gt;</b>  \nMostra o total e o vencimento da fatura aberta do cartão.  \nExemplo: /fatura nubank",
+	"help.cotacao":     "<b>/cotacao [moeda] [valor]</b>  \nMostra ou fixa a cotação usada nos gastos em outras moedas. /cotacao base &lt;moeda&gt; muda a moeda base dos totais.  \nExemplo: /cotacao USD 5,10",
+	"help.idioma":      "<b>/idioma [pt|en|es]</b>  \nMuda o idioma das mensagens do bot.",
+	"help.desfazer":    "<b>/desfazer</b>  \nDesfaz a última ação (registro, edição ou exclusão de gastos) se ela for recente. As mensagens de confirmação também trazem o botão ↩️ Desfazer.",
+	"help.lixeira":     "<b>/lixeira</b>  \nLista os gastos deletados recentemente, com um botão para restaurar cada um.",
+	"help.help":        "<b>/help</b>  \nExibe esta mensagem.",
+
+	"commands.start":       "Inicia o bot e mostra as boas-vindas",
+	"commands.gastei":      "Registre um novo gasto",
+	"commands.consulta":    "Veja seus gastos ou um gasto pelo ID",
+	"commands.grafico":     "Veja seus gastos em gráficos",
+	"commands.deletar":     "Delete um gasto pelo ID",
+	"commands.deletartudo": "Delete todos os gastos",
+	"commands.metodo":      "Cadastre seus cartões e contas",
+	"commands.fatura":      "Veja a fatura aberta do cartão",
+	"commands.cotacao":     "Veja ou fixe cotações de outras moedas",
+	"commands.idioma":      "Mude o idioma do bot",
+	"commands.desfazer":    "Desfaça a última ação",
+	"commands.lixeira":     "Restaure gastos deletados",
+	"commands.help":        "Veja todos os comandos e exemplos",
+
+	"invalid.command": `❌ <b>Comando não reconhecido</b>
+
+O comando <b>%s</b> não existe no <b>Money Savior</b> 😕  
+
+📋 Para ver a lista de comandos disponíveis, digite <b>/help</b>.`,
+	"invalid.tip":           "\n\n💡 Dica: confira se o comando foi digitado corretamente.",
+	"invalid.did_you_mean":  "\n\n💡 Você quis dizer %s?",
+	"invalid.or":            " ou ",
+	"invalid.run_button":    "▶️ Executar %s",
+	"invalid.run_expired":   "Não encontrei a mensagem original. Digite o comando de novo.",
+	"invalid.run_not_yours": "Só quem digitou o comando pode usar este botão.",
+
+	"expense.usage":          "⚠️ Formato incorreto — use: /gastei <valor> <categoria> [método] [parcelas] | Exemplo: /gastei 21.90 uber pix ou /gastei 1200 notebook nubank 12x",
+	"expense.invalid_amount": "Valor inválido, exemplo: /gastei 21,74 uber pix",
+	"expense.saving":         "⏳ Registrando seu gasto...",
+	"expense.saved":          "✅ Gasto registrado com sucesso!\n\n🆔 ID: %d\n💰 Valor: %s\n📝 Descrição: %s\n🏷️ Categoria: %s\n💳 Método: %s",
+	"expense.cnpj":           "\n🏪 CNPJ: %s",
+	"expense.installments":   "\n🧾 Parcelado: %dx de %s",
+	"expense.suggested":      "\n\n🤖 Seu histórico sugere as opções abaixo. Toque em uma para aplicar.",
+	"expense.exchange":       "\n💱 Cotação: 1 %s = %s %s",
+	"expense.no_rate":        "❌ Não tenho a cotação de %s para %s. Fixe uma com /cotacao %s <valor> e tente de novo.",
+
+	"query.invalid_id":        "❌ ID inválido. Use um número inteiro maior que zero.\nExemplo: /consulta 3",
+	"query.empty":             "📝 Você ainda não registrou nenhum gasto.",
+	"query.empty_short":       "📝 Nenhum gasto registrado.",
+	"query.list_header":       "📋 <b>Seus gastos (%d registros):</b>\n\n",
+	"query.list_item":         "🆔 <b>#%d</b> | 💰 %s | 📝 %s | 💳 %s%s\n",
+	"query.list_footer":       "\n💡 Use /consulta &lt;ID&gt; para ver detalhes de um gasto específico.\nExemplo: /consulta 2",
+	"query.installment_note":  " | 🧾 parcela %d/%d",
+	"query.not_found":         "❌ Nenhum gasto encontrado com o ID <b>%d</b>.\nUse /consulta para ver a lista completa.",
+	"query.card":              "📄 <b>Gasto %d de %d</b>\n\n🆔 ID: <b>%d</b>\n💰 Valor: <b>%s</b>\n📝 Categoria: <b>%s</b>\n💳 Método: <b>%s</b>\n🕐 Data: <b>%s</b>",
+	"query.card_cnpj":         "\n🏪 CNPJ: <b>%s</b>",
+	"query.card_exchange":     "\n💱 Cotação: <b>1 %s = %s %s</b>",
+	"query.card_installments": "\n🧾 Parcelas: <b>%dx de %s</b> (parcela %d/%d)",
+	"query.previous_button":   "⬅️ Anterior",
+	"query.next_button":       "Próximo ➡️",
+	"query.delete_button":     "🗑️ Deletar #%d",
+	"query.all_button":        "📋 Ver todos",
+	"query.receipt_button":    "🧾 Ver comprovante",
+
+	"delete.usage":                "❌ Uso incorreto. Use: /deletar <ID do gasto>\nExemplo: /deletar 3\n\nUse /consulta para ver os IDs dos seus gastos.",
+	"delete.invalid_id":           "❌ ID inválido. Use um número inteiro maior que zero.\nExemplo: /deletar 3",
+	"delete.not_found":            "❌ Nenhum gasto encontrado com o ID %d.\nUse /consulta para ver os IDs disponíveis.",
+	"delete.confirm":              "⚠️ Tem certeza que deseja deletar este gasto?\n\n🆔 ID: %d\n💰 Valor: %s\n📝 Categoria: %s\n💳 Método: %s",
+	"delete.confirm_installments": "\n\n🧾 As %d parcelas desta compra também serão deletadas.",
+	"delete.confirm_receipt":      "\n\n📎 O comprovante anexado fica guardado com o gasto e volta se você restaurá-lo.",
+	"delete.confirm_button":       "✅ Sim, deletar",
+	"delete.done":                 "✅ Gasto #%d deletado com sucesso!",
+	"delete.not_yours":            "Só quem pediu para deletar pode confirmar.",
+	"delete_all.none":             "📝 Você não possui nenhum gasto registrado.",
+	"delete_all.confirm":          "⚠️ <b>Atenção!</b> Você está prestes a deletar <b>todos os %d gastos</b> registrados.\n\nVocê poderá desfazer por %d minutos. Deseja continuar?",
+	"delete_all.confirm_button":   "🗑️ Sim, deletar todos (%d)",
+	"delete_all.failed":           "❌ Ocorreu um erro ao deletar os gastos. Tente novamente mais tarde.",
+	"delete_all.done":             "✅ Todos os gastos foram deletados com sucesso!",
+	"delete.trash_note":           "\n🗑️ Gastos deletados ficam na /lixeira por %d dias.",
+	"delete.partial":              "\n🗑️ Parte dos gastos já foi para a /lixeira; use o botão abaixo para desfazer.",
+
+	"draft.register_button": "✅ Registrar",
+	"draft.invalid":         "❌ Proposta inválida.",
+	"draft.saved":           "✅ Registrado",
+	"draft.not_yours":       "Só quem enviou o comprovante ou o áudio pode registrar este gasto.",
+
+	"suggest.ok_button": "✅ Está certo",
+	"suggest.not_found": "❌ Gasto #%d não encontrado.",
+	"suggest.failed":    "❌ Erro ao atualizar o gasto.",
+	"suggest.updated":   "✅ Atualizado",
+	"suggest.not_yours": "Só quem registrou o gasto pode alterá-lo.",
+
+	"chart.usage":       "❌ Uso: /grafico [mês|ano]\nExemplo: /grafico ano",
+	"chart.month_title": "%s/%d",
+	"chart.empty":       "📝 Nenhum gasto registrado em %s.",
+	"chart.failed":      "❌ Não foi possível gerar o gráfico. Tente novamente mais tarde.",
+	"chart.pie_caption": "🥧 Gastos por categoria — %s\n💰 Total: %s\n",
+	"chart.pie_item":    "\n%s %s — %s (%s)",
+	"chart.others":      "outros",
+	"chart.bars_year":   "📊 Total por mês — %s (jan → dez)\n🔝 Maior mês: %s, %s",
+	"chart.bars_month":  "📊 Total por dia — %s (dia 1 → %d)\n🔝 Maior dia: %02d, %s",
+
+	"invoice.cards_failed":     "❌ Ocorreu um erro ao consultar seus cartões. Tente novamente mais tarde.",
+	"invoice.no_cards":         "💳 Nenhum cartão de crédito cadastrado.\nCadastre um com: /metodo <nome> credito <dia fechamento> <dia vencimento>\nExemplo: /metodo nubank credito 3 10",
+	"invoice.card_not_found":   "❌ Nenhum cartão de crédito chamado %s.\nUse /metodo para ver seus métodos cadastrados.",
+	"invoice.usage":            "❌ Uso: /fatura <cartão>\nSeus cartões: %s",
+	"invoice.open_title":       "🧾 Fatura aberta",
+	"invoice.reminder_title":   "⏰ Lembrete: fatura vence em %d dias",
+	"invoice.summary":          "%s — %s\n\n💰 Total: %s\n🔒 Fecha em: %s\n📅 Vence em: %s\n🧮 Lançamentos: %d",
+	"invoice.item":             "\n• %s | %s | %s",
+	"invoice.item_installment": " (parcela %d/%d)",
+	"invoice.upcoming":         "\n\n📆 Próximas faturas (vencimento):",
+	"invoice.upcoming_item":    "\n• %s/%d | %s",
+
+	"nfce.invalid_link":     "❌ Este link não parece ser de uma NFC-e.",
+	"nfce.already_imported": "ℹ️ Esta nota já foi importada como o gasto #%d.",
+	"nfce.no_total":         "❌ Não consegui obter o valor desta nota no site da SEFAZ. Tente novamente mais tarde ou registre com /gastei.",
+
+	"method.help": `Use:
+/metodo <nome> credito <dia fechamento> <dia vencimento>
+/metodo <nome> debito [conta]
+/metodo <nome> pix [conta]
+/metodo <nome> dinheiro
+/metodo remover <nome>
+
+Exemplo: /metodo nubank credito 3 10`,
+	"method.usage":         "❌ Uso incorreto. %s",
+	"method.not_found":     "❌ Nenhum método cadastrado com o nome %s.",
+	"method.remove_failed": "❌ Erro ao remover o método. Tente novamente.",
+	"method.removed":       "✅ Método %s removido.",
+	"method.invalid_days":  "❌ Dias inválidos. Use números entre 1 e 31.\nExemplo: /metodo nubank credito 3 10",
+	"method.name_too_long": "❌ O nome do método pode ter até %d caracteres.",
+	"method.save_failed":   "❌ Erro ao salvar o método. Tente novamente.",
+	"method.saved":         "✅ Método cadastrado!\n\n%s\n\n💡 Use o nome no /gastei para vincular o gasto. Exemplo: /gastei 50 mercado %s",
+	"method.query_failed":  "❌ Ocorreu um erro ao consultar seus métodos. Tente novamente mais tarde.",
+	"method.none":          "💳 Você ainda não cadastrou nenhum método de pagamento.\n\n%s",
+	"method.list_header":   "💳 Seus métodos de pagamento (%d):\n\n",
+	"method.list_footer":   "\n💡 Use /fatura <cartão> para ver a fatura aberta de um cartão de crédito.",
+	"method.credit":        "💳 %s — crédito | fecha dia %d | vence dia %d",
+	"method.debit":         "🏦 %s — débito | conta %s",
+	"method.pix":           "⚡ %s — pix | conta %s",
+	"method.other":         "💵 %s — %s",
+
+	"rate.help":              "Use:\n/cotacao <moeda> — cotação em uso\n/cotacao <moeda> <valor> — fixa a cotação\n/cotacao remover <moeda> — volta à cotação em cache\n/cotacao base <moeda> — muda a moeda base\n\nExemplo: /cotacao USD 5,10",
+	"rate.usage":             "❌ Uso incorreto. %s",
+	"rate.query_failed":      "❌ Ocorreu um erro ao consultar suas cotações. Tente novamente mais tarde.",
+	"rate.header":            "💱 Moeda base: %s\n\n",
+	"rate.none_pinned":       "Nenhuma cotação fixada: uso a cotação em cache.\n",
+	"rate.pinned_header":     "📌 Cotações fixadas:\n",
+	"rate.pinned_item":       "• 1 %s = %s %s\n",
+	"rate.unknown_currency":  "❌ Moeda não suportada: %s.\nUse o código de 3 letras, como USD ou EUR.",
+	"rate.is_base":           "❌ %s já é a sua moeda base.",
+	"rate.no_rate":           "❌ Não tenho a cotação de %s para %s. Fixe uma com /cotacao %s <valor>.",
+	"rate.current":           "💱 1 %s = %s %s (%s)",
+	"rate.source_cache":      "cotação em cache",
+	"rate.source_pinned":     "fixada por você",
+	"rate.invalid_value":     "❌ Cotação inválida. Use um número maior que zero.\nExemplo: /cotacao USD 5,10",
+	"rate.saved":             "✅ Cotação fixada: 1 %s = %s %s.\nOs próximos gastos nessa moeda usam esse valor.",
+	"rate.removed":           "✅ Cotação de %s removida. Volto a usar a cotação em cache.",
+	"rate.not_pinned":        "❌ Nenhuma cotação fixada para %s.",
+	"rate.base_changed":      "✅ Sua moeda base agora é %s.\nAs cotações fixadas foram apagadas.",
+	"rate.base_has_expenses": "❌ Seus gastos estão registrados em %s, então a moeda base não pode mudar: os valores já salvos seriam lidos como %s.\nA moeda base só pode ser trocada sem nenhum gasto registrado, nem na lixeira (ela se esvazia sozinha em %d dias).",
+	"rate.save_failed":       "❌ Erro ao salvar a cotação. Tente novamente.",
+
+	"receipt.hint":        "🧾 Para guardar um comprovante, envie a foto com a legenda /gastei <valor> <categoria> [método] ou responda à mensagem de um gasto com a foto.",
+	"receipt.read_failed": "❌ Não consegui ler o comprovante.\n\n%s",
+	"receipt.no_total":    "🤔 Não encontrei o valor total neste comprovante.\n\n%s",
+	"receipt.read":        "🧾 Li o comprovante:\n\n",
+	"receipt.total":       "💰 Total: %s\n",
+	"receipt.merchant":    "🏪 Estabelecimento: %s\n",
+	"receipt.date":        "📅 Data: %s\n",
+	"receipt.confirm":     "\nRegistrar como %s?",
+	"receipt.save_failed": "❌ Erro ao salvar o comprovante. Tente novamente.",
+	"receipt.attached":    "🧾 Comprovante anexado ao gasto #%d.\nUse /consulta %d para vê-lo.",
+	"receipt.not_found":   "❌ Comprovante não encontrado.",
+	"receipt.not_yours":   "Só quem registrou o gasto #%d pode ver ou anexar o comprovante.",
+	"receipt.caption":     "🧾 Comprovante do gasto #%d — %s | %s",
+
+	"voice.too_long":          "🎙️ Áudio muito longo. Envie um áudio de até %d segundos, por exemplo: \"gastei vinte reais de uber no pix\".",
+	"voice.download_failed":   "❌ Não consegui baixar o áudio. Tente novamente.",
+	"voice.unavailable":       "🎙️ A transcrição de áudio não está disponível no momento. Use /gastei <valor> <categoria> [método].",
+	"voice.transcribe_failed": "❌ Não consegui entender o áudio. Tente novamente.",
+	"voice.no_amount":         "🎙️ Entendi: \"%s\"\n\n🤔 Mas não encontrei o valor. Tente algo como \"gastei vinte reais de uber no pix\".",
+	"voice.proposal":          "🎙️ Entendi: \"%s\"\n\n💰 Valor: %s\n📝 Descrição: %s\n💳 Método: %s\n\nRegistrar como %s?",
+	"voice.method_unknown":    "não informado (vou sugerir pelo seu histórico)",
+
+	"ratelimit.message":  "⏳ Calma! Você enviou muitos comandos em pouco tempo.\nTente novamente em %s.",
+	"ratelimit.callback": "⏳ Muitos cliques. Tente novamente em %s.",
+
+	"wait.second":  "1 segundo",
+	"wait.seconds": "%d segundos",
+	"wait.minute":  "1 minuto",
+	"wait.minutes": "%d minutos",
+
+	"language.choose":      "🌐 Idioma atual: %s\n\nEscolha o idioma das mensagens:",
+	"language.changed":     "✅ Pronto! Agora vou falar com você em %s.",
+	"language.invalid":     "❌ Idioma não suportado: %s.\nUse /idioma pt, /idioma en ou /idioma es.",
+	"language.save_failed": "❌ Erro ao salvar o idioma. Tente novamente.",
+
+	"undo.button":      "↩️ Desfazer",
+	"undo.nothing":     "🤷 Não há nada para desfazer. Só a última ação dos últimos %d minutos pode ser desfeita.",
+	"undo.expired":     "Esta ação não pode mais ser desfeita.",
+	"undo.not_yours":   "Só quem fez a ação pode desfazê-la.",
+	"undo.failed":      "❌ Erro ao desfazer. Tente novamente.",
+	"undo.saved":       "↩️ Desfeito: o gasto #%d foi removido.",
+	"undo.edited":      "↩️ Desfeito: o gasto #%d voltou a ser como era.",
+	"undo.deleted":     "↩️ Desfeito: o gasto #%d foi restaurado.",
+	"undo.deleted_all": "↩️ Desfeito: %d gastos foram restaurados.",
+	"undo.restored":    "↩️ Desfeito: o gasto #%d voltou para a lixeira.",
+
+	"trash.header":         "🗑️ <b>Lixeira</b> — gastos deletados nos últimos %d dias:\n\n",
+	"trash.item":           "🆔 <b>#%d</b> | 💰 %s | 📝 %s | 🗑️ %s\n",
+	"trash.more":           "\n… e mais %d. Restaure alguns para ver os outros.\n",
+	"trash.footer":         "\n💡 Toque em um botão para restaurar o gasto.",
+	"trash.empty":          "🗑️ A lixeira está vazia. Gastos deletados ficam aqui por %d dias.",
+	"trash.restore_button": "♻️ Restaurar #%d",
+	"trash.restored":       "♻️ Gasto #%d restaurado.",
+	"trash.not_found":      "Este gasto não está mais na lixeira.",
+	"trash.not_yours":      "Só o dono da lixeira pode restaurar estes gastos.",
+	"trash.failed":         "❌ Erro ao consultar a lixeira. Tente novamente.",
+	"trash.restore_failed": "❌ Erro ao restaurar o gasto.",
+
+	"inline.hint":                      "💡 Digite um gasto (25 café pix) ou \"resumo\"",
+	"inline.expense_title":             "💸 Registrar %s",
+	"inline.expense_description":       "%s · %s — o valor não aparece no chat",
+	"inline.expense_share_title":       "📢 Registrar e mostrar no chat",
+	"inline.expense_share_description": "Envia os detalhes do gasto para a conversa",
+	"inline.expense_pending":           "⏳ Registrando gasto no Money Savior...",
+	"inline.expense_saved":             "✅ Gasto registrado no Money Savior.",
+	"inline.expense_failed":            "❌ Não foi possível registrar o gasto. Tente de novo ou use /gastei no chat com o bot.",
+	"inline.open_button":               "💰 Abrir o Money Savior",
+	"inline.summary_title":             "📊 Resumo de %s",
+	"inline.summary_description":       "%s em %d gastos",
+	"inline.summary":                   "📊 <b>Resumo de %s</b>\n\n💰 Total: <b>%s</b>\n🧾 Gastos: <b>%d</b>\n",
+	"inline.summary_categories":        "\n🏷️ <b>Maiores categorias:</b>\n",
+	"inline.summary_item":              "• %s: <b>%s</b> (%s)\n",
+	"inline.summary_empty":             "📊 Nenhum gasto registrado em %s.",
+}
@@ -0,0 +1,150 @@
+// Package logging configures the JSON slog logger of the bot. Attributes
+// stored in a context with With (update_id, user_id, chat_id, command) are
+// added to every line logged with that context, and attributes that carry
+// users' financial data are redacted unless LOG_REDACT=false. Credentials are
+// always redacted, including bot tokens inside error messages and URLs.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the value of sensitive attributes.
+const Redacted = "[redacted]"
+
+// sensitiveKeys are the attributes holding amounts, descriptions or raw user
+// text. Keep keys of new log lines in this list when they carry such data.
+var sensitiveKeys = map[string]bool{
+	"amount":      true,
+	"total":       true,
+	"description": true,
+	"category":    true,
+	"text":        true,
+	"transcript":  true,
+	"data":        true,
+}
+
+// secretKeys are the attributes holding credentials. Keys ending in _token or
+// _secret are treated the same way.
+var secretKeys = map[string]bool{
+	"token":         true,
+	"secret":        true,
+	"password":      true,
+	"authorization": true,
+	"api_key":       true,
+}
+
+// botTokenPattern matches a Telegram bot token in the path of a Bot API URL,
+// which the HTTP client repeats in its errors.
+var botTokenPattern = regexp.MustCompile(`bot\d+:[A-Za-z0-9_-]+`)
+
+// Setup installs the default logger from LOG_LEVEL (debug, info, warn or
+// error; info by default) and LOG_REDACT.
+func Setup() {
+	slog.SetDefault(New(os.Stdout, levelFromEnv(), os.Getenv("LOG_REDACT") != "false"))
+}
+
+// New returns a JSON logger writing to w.
+func New(w io.Writer, level slog.Level, redact bool) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr(redact)}
+	return slog.New(contextHandler{slog.NewJSONHandler(w, options)})
+}
+
+func levelFromEnv() slog.Level {
+	switch strings.ToLower(os.Getenv("LOG_LEVEL")) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// redactAttr masks credentials, and financial data too when redact is set.
+// The JSON handler calls it for the attributes inside groups as well.
+func redactAttr(redact bool) func(groups []string, attr slog.Attr) slog.Attr {
+	return func(groups []string, attr slog.Attr) slog.Attr {
+		key := strings.ToLower(attr.Key)
+		if secretKeys[key] || strings.HasSuffix(key, "_token") || strings.HasSuffix(key, "_secret") ||
+			(redact && sensitiveKeys[key]) {
+			return slog.String(attr.Key, Redacted)
+		}
+
+		var text string
+		switch value := attr.Value.Resolve(); {
+		case value.Kind() == slog.KindString:
+			text = value.String()
+		case value.Kind() == slog.KindAny:
+			err, ok := value.Any().(error)
+			if !ok {
+				return attr
+			}
+			text = err.Error()
+		default:
+			return attr
+		}
+		if botTokenPattern.MatchString(text) {
+			return slog.String(attr.Key, botTokenPattern.ReplaceAllString(text, "bot"+Redacted))
+		}
+		return attr
+	}
+}
+
+type contextKey struct{}
+
+// With returns a copy of ctx whose log lines also carry attrs.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	previous, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	merged := make([]slog.Attr, 0, len(previous)+len(attrs))
+	merged = append(merged, previous...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+// contextHandler adds the attributes stored by With to each record.
+type contextHandler struct {
+	slog.Handler
+}
+
+// Attributes the line already has take precedence over the context ones, so
+// keys are never repeated in the JSON object.
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrs, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	if len(attrs) == 0 {
+		return h.Handler.Handle(ctx, record)
+	}
+
+	present := make(map[string]bool, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		present[attr.Key] = true
+		return true
+	})
+	for _, attr := range attrs {
+		if !present[attr.Key] {
+			record.AddAttrs(attr)
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Fatal logs msg at error level and exits, like log.Fatal.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+const botToken = "123456789:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw"
+
+// logLine logs with a logger writing to a buffer and decodes the JSON line.
+func logLine(t *testing.T, redact bool, log func(*slog.Logger)) (map[string]any, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	log(New(&buf, slog.LevelDebug, redact))
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", buf.String(), err)
+	}
+	return line, buf.String()
+}
+
+func TestRedaction(t *testing.T) {
+	apiErr := errors.New(`Post "https://api.telegram.org/bot` + botToken + `/sendMessage": dial tcp: i/o timeout`)
+	tests := []struct {
+		name   string
+		redact bool
+		log    func(*slog.Logger)
+		path   []string // where the value is in the JSON line
+		want   any
+	}{
+		{"amount", true, func(l *slog.Logger) { l.Info("m", "amount", 45.5) }, []string{"amount"}, Redacted},
+		{"amount when disabled", false, func(l *slog.Logger) { l.Info("m", "amount", 45.5) }, []string{"amount"}, 45.5},
+		{"token", true, func(l *slog.Logger) { l.Info("m", "token", botToken) }, []string{"token"}, Redacted},
+		{"token when disabled", false, func(l *slog.Logger) { l.Info("m", "token", botToken) }, []string{"token"}, Redacted},
+		{"secret suffix", false, func(l *slog.Logger) { l.Info("m", "webhook_secret", "s3cr3t") }, []string{"webhook_secret"}, Redacted},
+		{"token suffix", false, func(l *slog.Logger) { l.Info("m", "Secret_Token", "s3cr3t") }, []string{"Secret_Token"}, Redacted},
+		{"nested group", false, func(l *slog.Logger) {
+			l.Info("m", slog.Group("request", slog.Group("headers", "authorization", "Bearer x")))
+		}, []string{"request", "headers", "authorization"}, Redacted},
+		{"logger group", true, func(l *slog.Logger) { l.WithGroup("expense").Info("m", "description", "farmácia") },
+			[]string{"expense", "description"}, Redacted},
+		{"logger attrs", false, func(l *slog.Logger) { l.With("password", "hunter2").Info("m") }, []string{"password"}, Redacted},
+		{"token in error", false, func(l *slog.Logger) { l.Error("m", "error", apiErr) },
+			[]string{"error"}, `Post "https://api.telegram.org/bot[redacted]/sendMessage": dial tcp: i/o timeout`},
+		{"token in string", false, func(l *slog.Logger) { l.Info("m", "url", "https://api.telegram.org/file/bot"+botToken+"/voice.ogg") },
+			[]string{"url"}, "https://api.telegram.org/file/bot[redacted]/voice.ogg"},
+		{"context attrs", true, func(l *slog.Logger) {
+			l.InfoContext(With(context.Background(), slog.String("text", "/gastei 10 mercado")), "m")
+		}, []string{"text"}, Redacted},
+		{"other attrs kept", true, func(l *slog.Logger) { l.Info("m", "user_id", 42, "command", "gastei") }, []string{"command"}, "gastei"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, raw := logLine(t, tt.redact, tt.log)
+			var value any = line
+			for _, key := range tt.path {
+				group, ok := value.(map[string]any)
+				if !ok {
+					t.Fatalf("no %v in %s", tt.path, raw)
+				}
+				value = group[key]
+			}
+			if value != tt.want {
+				t.Errorf("%v = %v, want %v", tt.path, value, tt.want)
+			}
+			if tt.want == Redacted && strings.Contains(raw, botToken) {
+				t.Errorf("line leaks the token: %s", raw)
+			}
+		})
+	}
+}
+
+func TestContextAttrs(t *testing.T) {
+	ctx := With(context.Background(), slog.Int64("update_id", 7), slog.Int64("user_id", 42))
+	ctx = With(ctx, slog.String("command", "gastei"))
+
+	line, raw := logLine(t, true, func(l *slog.Logger) {
+		l.InfoContext(ctx, "m", "user_id", 99)
+	})
+	if line["update_id"] != 7.0 || line["command"] != "gastei" {
+		t.Errorf("context attributes missing: %s", raw)
+	}
+	// The line's own attribute wins, without repeating the key.
+	if line["user_id"] != 99.0 || strings.Count(raw, `"user_id"`) != 1 {
+		t.Errorf("user_id repeated or overridden: %s", raw)
+	}
+}
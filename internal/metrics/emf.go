@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Namespace is the CloudWatch namespace of the EMF metrics.
+const Namespace = "MoneySavior"
+
+type emfKind int
+
+const (
+	emfUpdate emfKind = iota
+	emfStorage
+	emfSend
+)
+
+type emfEvent struct {
+	kind   emfKind
+	labels []string
+	value  time.Duration
+	failed bool
+	at     time.Time
+}
+
+// EnableEMF makes the default registry keep each observation until the next
+// FlushEMF, for processes (Lambda) that are not scraped by Prometheus.
+func EnableEMF() {
+	Default.mu.Lock()
+	defer Default.mu.Unlock()
+	Default.emf = true
+}
+
+// record queues an event for FlushEMF. The caller holds r.mu.
+func (r *Registry) record(event emfEvent) {
+	if r.emf {
+		event.at = time.Now()
+		r.pending = append(r.pending, event)
+	}
+}
+
+// FlushEMF writes the observations since the last flush to w, one CloudWatch
+// Embedded Metric Format document per line. Lambda sends stdout to CloudWatch
+// Logs, which extracts the metrics from these lines.
+func FlushEMF(w io.Writer) error {
+	Default.mu.Lock()
+	pending := Default.pending
+	Default.pending = nil
+	Default.mu.Unlock()
+
+	encoder := json.NewEncoder(w)
+	for _, event := range pending {
+		if err := encoder.Encode(event.document()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+func (e emfEvent) document() map[string]any {
+	doc := map[string]any{}
+	var dimensions []string
+	var metrics []emfMetric
+
+	switch e.kind {
+	case emfUpdate:
+		dimensions = []string{"Command", "Outcome"}
+		doc["Command"], doc["Outcome"] = e.labels[0], e.labels[1]
+		doc["Updates"] = 1
+		doc["UpdateLatency"] = float64(e.value) / float64(time.Millisecond)
+		metrics = []emfMetric{{"Updates", "Count"}, {"UpdateLatency", "Milliseconds"}}
+	case emfStorage:
+		dimensions = []string{"Operation"}
+		doc["Operation"] = e.labels[0]
+		doc["StorageLatency"] = float64(e.value) / float64(time.Millisecond)
+		failures := 0
+		if e.failed {
+			failures = 1
+		}
+		doc["StorageErrors"] = failures
+		metrics = []emfMetric{{"StorageLatency", "Milliseconds"}, {"StorageErrors", "Count"}}
+	case emfSend:
+		dimensions = []string{"Method"}
+		doc["Method"] = e.labels[0]
+		doc["SendFailures"] = 1
+		metrics = []emfMetric{{"SendFailures", "Count"}}
+	}
+
+	doc["_aws"] = map[string]any{
+		"Timestamp": e.at.UnixMilli(),
+		"CloudWatchMetrics": []map[string]any{{
+			"Namespace":  Namespace,
+			"Dimensions": [][]string{dimensions},
+			"Metrics":    metrics,
+		}},
+	}
+	return doc
+}
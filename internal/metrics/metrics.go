@@ -0,0 +1,127 @@
+// Package metrics counts what the bot does and exposes it in the Prometheus
+// text format (polling and webhook modes) or as CloudWatch Embedded Metric
+// Format lines (Lambda). It has no dependencies: the few metric types the bot
+// needs are implemented here.
+package metrics
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outcomes of an update.
+const (
+	OutcomeOK          = "ok"
+	OutcomeError       = "error"
+	OutcomeTimeout     = "timeout"
+	OutcomePanic       = "panic"
+	OutcomeDuplicate   = "duplicate"
+	OutcomeRateLimited = "rate_limited"
+	OutcomeIgnored     = "ignored"
+)
+
+// buckets are the upper bounds, in seconds, of the latency histograms. They
+// go up to the one minute a voice transcription may take.
+var buckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative; the last one is +Inf
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(seconds float64) {
+	i := sort.SearchFloat64s(buckets, seconds)
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+}
+
+// series identifies a metric with its label values, in the order the metric
+// declares its labels.
+type series struct {
+	name   string
+	labels string // label values joined by labelSeparator
+}
+
+const labelSeparator = "\x00"
+
+func newSeries(name string, values ...string) series {
+	return series{name: name, labels: strings.Join(values, labelSeparator)}
+}
+
+func (s series) values() []string {
+	return strings.Split(s.labels, labelSeparator)
+}
+
+// Registry holds the metrics of the process.
+type Registry struct {
+	mu         sync.Mutex
+	counters   map[series]float64
+	histograms map[series]*histogram
+	emf        bool
+	pending    []emfEvent
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		counters:   make(map[series]float64),
+		histograms: make(map[series]*histogram),
+	}
+}
+
+// Default is the registry the bot records into.
+var Default = NewRegistry()
+
+func (r *Registry) add(s series, value float64) {
+	r.counters[s] += value
+}
+
+func (r *Registry) observe(s series, d time.Duration) {
+	h, ok := r.histograms[s]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(buckets)+1)}
+		r.histograms[s] = h
+	}
+	h.observe(d.Seconds())
+}
+
+// ObserveUpdate records a handled update: its command (or kind of update),
+// outcome and how long it took.
+func ObserveUpdate(command, outcome string, d time.Duration) {
+	Default.mu.Lock()
+	defer Default.mu.Unlock()
+	Default.add(newSeries(updatesTotal, command, outcome), 1)
+	Default.observe(newSeries(updateDuration, command), d)
+	Default.record(emfEvent{kind: emfUpdate, labels: []string{command, outcome}, value: d})
+}
+
+// ObserveStorage records a DynamoDB call. A failed call also marks the
+// update tracked in ctx (see Track) as failed.
+func ObserveStorage(ctx context.Context, operation string, d time.Duration, err error) {
+	Default.mu.Lock()
+	Default.observe(newSeries(storageDuration, operation), d)
+	if err != nil {
+		Default.add(newSeries(storageErrors, operation), 1)
+	}
+	Default.record(emfEvent{kind: emfStorage, labels: []string{operation}, value: d, failed: err != nil})
+	Default.mu.Unlock()
+
+	if err != nil {
+		if t, ok := ctx.Value(trackerKey{}).(*tracker); ok {
+			t.failed.Store(true)
+		}
+	}
+}
+
+// SendFailed records a Telegram API call (sendMessage, editMessageText...)
+// that failed.
+func SendFailed(method string) {
+	Default.mu.Lock()
+	defer Default.mu.Unlock()
+	Default.add(newSeries(sendFailures, method), 1)
+	Default.record(emfEvent{kind: emfSend, labels: []string{method}})
+}
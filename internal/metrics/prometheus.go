@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	updatesTotal    = "moneybot_updates_total"
+	updateDuration  = "moneybot_update_duration_seconds"
+	storageDuration = "moneybot_dynamodb_duration_seconds"
+	storageErrors   = "moneybot_dynamodb_errors_total"
+	sendFailures    = "moneybot_telegram_send_failures_total"
+)
+
+type definition struct {
+	name   string
+	kind   string // "counter" or "histogram"
+	help   string
+	labels []string
+}
+
+var definitions = []definition{
+	{updatesTotal, "counter", "Updates handled, by command (or kind of update) and outcome.", []string{"command", "outcome"}},
+	{updateDuration, "histogram", "Time spent handling an update, by command.", []string{"command"}},
+	{storageDuration, "histogram", "Latency of DynamoDB calls, by operation.", []string{"operation"}},
+	{storageErrors, "counter", "DynamoDB calls that failed, by operation.", []string{"operation"}},
+	{sendFailures, "counter", "Telegram API calls that failed, by method.", []string{"method"}},
+}
+
+// Handler serves the registry in the Prometheus text exposition format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// WriteText writes every metric in the Prometheus text exposition format.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := bufio.NewWriter(w)
+	for _, def := range definitions {
+		fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", def.name, def.help, def.name, def.kind)
+		switch def.kind {
+		case "counter":
+			for _, s := range sortedSeries(r.counters, def.name) {
+				fmt.Fprintf(out, "%s%s %s\n", def.name, formatLabels(def.labels, s.values()), formatFloat(r.counters[s]))
+			}
+		case "histogram":
+			for _, s := range sortedSeries(r.histograms, def.name) {
+				writeHistogram(out, def, s.values(), r.histograms[s])
+			}
+		}
+	}
+	return out.Flush()
+}
+
+func writeHistogram(out io.Writer, def definition, values []string, h *histogram) {
+	names := append(def.labels[:len(def.labels):len(def.labels)], "le")
+	var cumulative uint64
+	for i, bound := range buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(out, "%s_bucket%s %d\n", def.name, formatLabels(names, append(values, formatFloat(bound))), cumulative)
+	}
+	fmt.Fprintf(out, "%s_bucket%s %d\n", def.name, formatLabels(names, append(values, "+Inf")), h.count)
+	fmt.Fprintf(out, "%s_sum%s %s\n", def.name, formatLabels(def.labels, values), formatFloat(h.sum))
+	fmt.Fprintf(out, "%s_count%s %d\n", def.name, formatLabels(def.labels, values), h.count)
+}
+
+func sortedSeries[V any](m map[series]V, name string) []series {
+	var list []series
+	for s := range m {
+		if s.name == name {
+			list = append(list, s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].labels < list[j].labels })
+	return list
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(values[i]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
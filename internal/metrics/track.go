@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type trackerKey struct{}
+
+type tracker struct {
+	failed atomic.Bool
+}
+
+// Track returns a context that notices storage failures of the update it is
+// handling, and a function reporting whether any happened. Handlers answer
+// the user instead of returning errors, so this is how an update that
+// failed halfway gets the "error" outcome.
+func Track(ctx context.Context) (context.Context, func() bool) {
+	t := &tracker{}
+	return context.WithValue(ctx, trackerKey{}, t), t.failed.Load
+}
@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Transport wraps next to count failed Telegram Bot API calls. Every bot.Send
+// and bot.Request goes through the bot's HTTP client, so this catches the
+// failures call sites ignore.
+func Transport(next http.RoundTripper) http.RoundTripper {
+	return roundTripper(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		// Only API calls ("/bot<token>/<method>"), not file downloads.
+		if strings.HasPrefix(req.URL.Path, "/bot") && (err != nil || resp.StatusCode >= 400) {
+			SendFailed(path.Base(req.URL.Path))
+		}
+		return resp, err
+	})
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
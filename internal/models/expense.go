@@ -3,13 +3,79 @@ package models
 import "time"
 
 type Expense struct {
-	UserID    int64     `dynamodbav:"user_id"`
-	ChatID    int64     `dynamodbav:"chat_id"`
-	Username  string    `dynamodbav:"username"`
-	Amount    float64   `dynamodbav:"amount"`
-	Category  string    `dynamodbav:"category"`
-	Method    string    `dynamodbav:"method"`
-	CreatedAt time.Time `dynamodbav:"created_at"`
-	ExpenseID string    `dynamodbav:"expense_id"` // sort key: user_id#timestamp
-	SeqID     int       `dynamodbav:"seq_id"`      // sequential ID: 1, 2, 3...
-}
\ No newline at end of file
+	UserID      int64     `dynamodbav:"user_id"`
+	ChatID      int64     `dynamodbav:"chat_id"`
+	Username    string    `dynamodbav:"username"`
+	Amount      float64   `dynamodbav:"amount"`
+	Category    string    `dynamodbav:"category"`
+	Method      string    `dynamodbav:"method"`
+	Description string    `dynamodbav:"description,omitempty"` // free text typed by the user in /gastei
+	CreatedAt   time.Time `dynamodbav:"created_at"`
+	ExpenseID   string    `dynamodbav:"expense_id"` // sort key: user_id#timestamp
+	SeqID       int       `dynamodbav:"seq_id"`     // sequential ID: 1, 2, 3...
+
+	ReceiptFileID string `dynamodbav:"receipt_file_id,omitempty"` // Telegram file_id of the receipt photo
+	StoreCNPJ     string `dynamodbav:"store_cnpj,omitempty"`      // issuer CNPJ, for expenses imported from an NFC-e
+	NFCeKey       string `dynamodbav:"nfce_key,omitempty"`        // NFC-e access key, to avoid importing a receipt twice
+
+	// Expenses paid in another currency keep what was paid; Amount holds it
+	// converted to the base currency of the user at the rate of the day.
+	Currency       string  `dynamodbav:"currency,omitempty"`        // ISO 4217 code, only when not the base currency
+	OriginalAmount float64 `dynamodbav:"original_amount,omitempty"` // amount in Currency
+	ExchangeRate   float64 `dynamodbav:"exchange_rate,omitempty"`   // base currency per unit of Currency
+
+	// Installment purchases ("12x") are stored as a parent expense holding the
+	// full amount plus one child per installment, dated in the month it is billed.
+	// Children have no SeqID and use the sort key <parent expense_id>#<NN>.
+	ParentID         string `dynamodbav:"parent_id,omitempty"`         // children: expense_id of the purchase
+	Installment      int    `dynamodbav:"installment,omitempty"`       // children: 1..InstallmentCount
+	InstallmentCount int    `dynamodbav:"installment_count,omitempty"` // parent and children: number of installments
+
+	// Deleted expenses stay in the table, hidden from every query, until the
+	// table's TTL on expires_at removes them. /lixeira restores them.
+	DeletedAt *time.Time `dynamodbav:"deleted_at,omitempty"`
+	ExpiresAt int64      `dynamodbav:"expires_at,omitempty"` // unix seconds; only set while deleted
+}
+
+// Label returns the text the user typed for this expense, falling back to the category.
+func (e *Expense) Label() string {
+	if e.Description != "" {
+		return e.Description
+	}
+	return e.Category
+}
+
+// IsForeign reports whether the expense was paid in a currency other than the base one.
+func (e *Expense) IsForeign() bool {
+	return e.Currency != ""
+}
+
+// IsInstallmentPurchase reports whether the expense is the parent of an installment purchase.
+func (e *Expense) IsInstallmentPurchase() bool {
+	return e.ParentID == "" && e.InstallmentCount > 1
+}
+
+// IsInstallment reports whether the expense is a single installment of a purchase.
+func (e *Expense) IsInstallment() bool {
+	return e.ParentID != ""
+}
+
+// IsDeleted reports whether the expense is in the trash.
+func (e *Expense) IsDeleted() bool {
+	return e.DeletedAt != nil
+}
+
+// CurrentInstallment returns which installment of the purchase is billed in the
+// month of now, on the calendar of now's location.
+func (e *Expense) CurrentInstallment(now time.Time) int {
+	created := e.CreatedAt.In(now.Location())
+	months := (now.Year()-created.Year())*12 + int(now.Month()) - int(created.Month())
+	switch {
+	case months < 0:
+		return 1
+	case months >= e.InstallmentCount:
+		return e.InstallmentCount
+	default:
+		return months + 1
+	}
+}
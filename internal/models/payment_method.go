@@ -0,0 +1,37 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Payment method types accepted by /metodo.
+const (
+	MethodCredit = "credito"
+	MethodDebit  = "debito"
+	MethodPix    = "pix"
+	MethodCash   = "dinheiro"
+)
+
+// PaymentMethod is a card or account registered by the user. It lives in the
+// same table as the expenses, under the sort key "method#<name>".
+type PaymentMethod struct {
+	UserID      int64     `dynamodbav:"user_id"`
+	ItemID      string    `dynamodbav:"expense_id"` // sort key: method#<name>
+	Name        string    `dynamodbav:"name"`
+	Type        string    `dynamodbav:"type"`                   // credito, debito, pix or dinheiro
+	ClosingDay  int       `dynamodbav:"closing_day,omitempty"`  // credit cards only
+	DueDay      int       `dynamodbav:"due_day,omitempty"`      // credit cards only
+	AccountType string    `dynamodbav:"account_type,omitempty"` // debit and pix: corrente, poupanca...
+	CreatedAt   time.Time `dynamodbav:"created_at"`
+}
+
+// IsCredit reports whether the method is a credit card with a billing cycle.
+func (m *PaymentMethod) IsCredit() bool {
+	return m.Type == MethodCredit
+}
+
+// Matches reports whether an expense method refers to this payment method.
+func (m *PaymentMethod) Matches(method string) bool {
+	return strings.EqualFold(strings.TrimSpace(method), m.Name)
+}
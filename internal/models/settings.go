@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// SettingsItemID is the sort key of the settings item of a user.
+const SettingsItemID = "settings"
+
+// UserSettings holds the preferences of a user. It lives in the same table as
+// the expenses, under the sort key "settings".
+type UserSettings struct {
+	UserID    int64     `dynamodbav:"user_id"`
+	ItemID    string    `dynamodbav:"expense_id"`         // sort key: settings
+	Language  string    `dynamodbav:"language,omitempty"` // pt-BR, en or es; empty follows Telegram
+	UpdatedAt time.Time `dynamodbav:"updated_at"`
+
+	BaseCurrency string             `dynamodbav:"base_currency,omitempty"` // ISO 4217 code; empty is BRL
+	Rates        map[string]float64 `dynamodbav:"rates,omitempty"`         // rates pinned with /cotacao, in base currency per unit
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// UndoItemID is the sort key of the undo item of a user. Each action replaces
+// it, so only the last action can be undone.
+const UndoItemID = "undo"
+
+// Actions that can be undone.
+const (
+	UndoSave      = "save"
+	UndoEdit      = "edit"
+	UndoDelete    = "delete"
+	UndoDeleteAll = "delete_all"
+	UndoRestore   = "restore"
+)
+
+// UndoEntry records how to revert the last action that changed the expenses of
+// a user: the expenses it created are deleted, the ones it changed are put back
+// as they were, and the ones it moved in or out of the trash are moved back.
+// Deleted expenses stay in the table, so only their keys are kept: a copy of
+// every expense would not fit in an item.
+type UndoEntry struct {
+	UserID    int64     `dynamodbav:"user_id"`
+	ItemID    string    `dynamodbav:"expense_id"` // sort key: undo
+	ActionID  string    `dynamodbav:"action_id"`  // names the action in its undo button
+	Action    string    `dynamodbav:"action"`     // UndoSave, UndoEdit, UndoDelete, UndoDeleteAll or UndoRestore
+	SeqID     int       `dynamodbav:"seq_id,omitempty"`
+	Created   []string  `dynamodbav:"created,omitempty"`  // expense_ids of the expenses the action created
+	Trashed   []string  `dynamodbav:"trashed,omitempty"`  // expense_ids the action put in the trash, installments left out
+	Restored  []string  `dynamodbav:"restored,omitempty"` // expense_ids the action took out of the trash, installments left out
+	Previous  []Expense `dynamodbav:"previous,omitempty"` // expenses as they were before an edit
+	CreatedAt time.Time `dynamodbav:"created_at"`
+	ExpiresAt int64     `dynamodbav:"expires_at"` // unix seconds, the table's TTL attribute
+}
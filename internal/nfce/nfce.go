@@ -0,0 +1,162 @@
+// Package nfce reads Brazilian NFC-e (nota fiscal de consumidor eletrônica)
+// QR codes: it decodes the QR from a receipt photo, parses the access key and
+// amount from its URL and, when the URL does not carry the amount, reads it
+// from the SEFAZ consultation page.
+package nfce
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NFCeModel is the document model code of an NFC-e inside the access key.
+const NFCeModel = "65"
+
+// ErrNotNFCe is returned when a text or image holds no NFC-e QR code URL.
+var ErrNotNFCe = errors.New("nfce: not an NFC-e QR code")
+
+// Note is what the QR code URL tells about a receipt.
+type Note struct {
+	URL       string
+	AccessKey string  // chave de acesso, 44 digits
+	CNPJ      string  // issuer CNPJ, 14 digits
+	Total     float64 // zero when the URL does not carry it (online QR codes)
+}
+
+var urlPattern = regexp.MustCompile(`https?://\S+`)
+
+// FindURL returns the first NFC-e consultation URL in a text, if any.
+func FindURL(text string) (string, bool) {
+	for _, candidate := range urlPattern.FindAllString(text, -1) {
+		if _, err := ParseURL(candidate); err == nil {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// sefazHost matches the consultation portals of the state treasuries, such as
+// www.nfce.fazenda.sp.gov.br or www.sefaz.rs.gov.br. A few states use other
+// department names, listed in otherSefazDomains.
+var sefazHost = regexp.MustCompile(`^([a-z0-9-]+\.)*(fazenda|sefaz)\.(ac|al|am|ap|ba|ce|df|es|go|ma|mg|ms|mt|pa|pb|pe|pi|pr|rj|rn|ro|rr|rs|sc|se|sp|to)\.gov\.br$`)
+
+var otherSefazDomains = []string{
+	"svrs.rs.gov.br",     // SEFAZ Virtual, serving several states
+	"sefa.pa.gov.br",     // Pará
+	"set.rn.gov.br",      // Rio Grande do Norte
+	"sefin.ro.gov.br",    // Rondônia
+	"nfce.se.gov.br",     // Sergipe
+	"sefa.pr.gov.br",     // Paraná, older QR codes
+	"economia.go.gov.br", // Goiás
+}
+
+// AllowedURL reports whether a URL points to a SEFAZ portal over https on
+// the default port. Only such URLs are ever fetched: the link comes from the
+// user and must not make the bot reach arbitrary or internal hosts.
+func AllowedURL(u *url.URL) bool {
+	if u.Scheme != "https" || u.User != nil || (u.Port() != "" && u.Port() != "443") {
+		return false
+	}
+	return allowedHost(u.Hostname())
+}
+
+func allowedHost(host string) bool {
+	host = strings.ToLower(host)
+	if sefazHost.MatchString(host) {
+		return true
+	}
+	for _, domain := range otherSefazDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseURL parses an NFC-e QR code URL. It understands version 2 of the QR
+// code ("?p=<key>|2|..."), both online and offline (contingency), and the
+// legacy version 1 ("?chNFe=<key>&...&vNF=<total>"). Only SEFAZ hosts are
+// accepted; the many QR codes printed with http are upgraded to https.
+func ParseURL(raw string) (*Note, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return nil, ErrNotNFCe
+	}
+	if u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+	if !AllowedURL(u) {
+		return nil, ErrNotNFCe
+	}
+	query := u.Query()
+	note := &Note{URL: u.String()}
+
+	if p := query.Get("p"); p != "" {
+		fields := strings.Split(p, "|")
+		note.AccessKey = fields[0]
+		// Offline QR codes: key|version|env|day|total|digest|token id|hash
+		if len(fields) >= 8 {
+			note.Total = parseDecimal(fields[4])
+		}
+	} else if key := query.Get("chNFe"); key != "" {
+		note.AccessKey = key
+		note.Total = parseDecimal(query.Get("vNF"))
+	} else {
+		return nil, ErrNotNFCe
+	}
+
+	if !ValidAccessKey(note.AccessKey) || note.AccessKey[20:22] != NFCeModel {
+		return nil, ErrNotNFCe
+	}
+	note.CNPJ = note.AccessKey[6:20]
+	return note, nil
+}
+
+// ValidAccessKey checks the length and the mod-11 check digit of an access key.
+func ValidAccessKey(key string) bool {
+	if len(key) != 44 {
+		return false
+	}
+	sum, weight := 0, 2
+	for i := 42; i >= 0; i-- {
+		digit := key[i]
+		if digit < '0' || digit > '9' {
+			return false
+		}
+		sum += int(digit-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	check := 11 - sum%11
+	if check >= 10 {
+		check = 0
+	}
+	return int(key[43]-'0') == check
+}
+
+// FormatCNPJ renders 14 digits as 12.345.678/0001-90.
+func FormatCNPJ(cnpj string) string {
+	if len(cnpj) != 14 {
+		return cnpj
+	}
+	return cnpj[0:2] + "." + cnpj[2:5] + "." + cnpj[5:8] + "/" + cnpj[8:12] + "-" + cnpj[12:14]
+}
+
+// parseDecimal reads "12.34" (QR code) or "1.234,56" (SEFAZ pages).
+func parseDecimal(value string) float64 {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, ",") {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || amount < 0 {
+		return 0
+	}
+	return amount
+}
@@ -0,0 +1,141 @@
+package nfce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Fetcher downloads the SEFAZ consultation page of a note.
+type Fetcher interface {
+	Fetch(ctx context.Context, note *Note) ([]byte, error)
+}
+
+// maxPageBytes caps the size of a SEFAZ page.
+const maxPageBytes = 2 << 20
+
+// HTTPFetcher fetches the page from the URL in the QR code.
+type HTTPFetcher struct {
+	Client *http.Client
+}
+
+// maxRedirects caps the redirects between SEFAZ pages.
+const maxRedirects = 5
+
+// errOffPortal is returned for a page outside the SEFAZ portals.
+var errOffPortal = errors.New("nfce: url is not a SEFAZ portal")
+
+// NewHTTPFetcher returns a fetcher with a short timeout, since SEFAZ portals
+// are often slow and the user is waiting for a reply. Redirects are only
+// followed while they stay on SEFAZ portals over https.
+func NewHTTPFetcher() *HTTPFetcher {
+	return &HTTPFetcher{Client: &http.Client{
+		Timeout:       15 * time.Second,
+		CheckRedirect: checkRedirect,
+	}}
+}
+
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("nfce: stopped after %d redirects", maxRedirects)
+	}
+	if !AllowedURL(req.URL) {
+		return fmt.Errorf("%w: redirected to %s", errOffPortal, req.URL.Host)
+	}
+	return nil
+}
+
+// Fetch downloads the page.
+func (f *HTTPFetcher) Fetch(ctx context.Context, note *Note) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, note.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !AllowedURL(req.URL) {
+		return nil, errOffPortal
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; MoneySaviorBot)")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sefaz page returned %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+}
+
+// FileFetcher reads pages from local HTML fixtures named <access key>.html.
+type FileFetcher struct {
+	Dir string
+}
+
+// Fetch reads the fixture of the note.
+func (f FileFetcher) Fetch(_ context.Context, note *Note) ([]byte, error) {
+	return os.ReadFile(filepath.Join(f.Dir, note.AccessKey+".html"))
+}
+
+// Page is what could be read from the SEFAZ consultation page. Zero fields were not found.
+type Page struct {
+	Merchant string
+	Total    float64
+	IssuedAt time.Time
+}
+
+var (
+	tagPattern      = regexp.MustCompile(`(?s)<[^>]*>`)
+	spacePattern    = regexp.MustCompile(`\s+`)
+	merchantPattern = regexp.MustCompile(`(?is)<div[^>]*(?:id="u20"|class="txtTopo")[^>]*>(.*?)</div>`)
+	totalPatterns   = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)valor\s+a\s+pagar\s*r\$:?\s*([\d.]+,\d{2})`),
+		regexp.MustCompile(`(?i)valor\s+total\s*r\$:?\s*([\d.]+,\d{2})`),
+	}
+	issuedPattern = regexp.MustCompile(`(?i)emiss[ãa]o:?\s*(\d{2}/\d{2}/\d{4})\s+(\d{2}:\d{2}(?::\d{2})?)`)
+)
+
+// ParsePage extracts the store name, total and issue date from the page HTML
+// used by the state portals (the layout is shared by most SEFAZ).
+func ParsePage(page []byte, location *time.Location) Page {
+	raw := string(page)
+	var result Page
+
+	if match := merchantPattern.FindStringSubmatch(raw); match != nil {
+		result.Merchant = cleanText(match[1])
+	}
+
+	text := cleanText(raw)
+	for _, pattern := range totalPatterns {
+		if match := pattern.FindStringSubmatch(text); match != nil {
+			result.Total = parseDecimal(match[1])
+			break
+		}
+	}
+
+	if match := issuedPattern.FindStringSubmatch(text); match != nil {
+		layout := "02/01/2006 15:04"
+		if len(match[2]) == 8 {
+			layout = "02/01/2006 15:04:05"
+		}
+		if issued, err := time.ParseInLocation(layout, match[1]+" "+match[2], location); err == nil {
+			result.IssuedAt = issued
+		}
+	}
+
+	return result
+}
+
+func cleanText(fragment string) string {
+	text := html.UnescapeString(tagPattern.ReplaceAllString(fragment, " "))
+	return strings.TrimSpace(spacePattern.ReplaceAllString(text, " "))
+}
@@ -0,0 +1,105 @@
+package nfce
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+var brt = time.FixedZone("BRT", -3*60*60)
+
+func TestParsePageFixtures(t *testing.T) {
+	tests := []struct {
+		key  string
+		want Page
+	}{
+		{
+			key: spKey,
+			want: Page{
+				Merchant: "SUPERMERCADO BOM PREÇO LTDA",
+				Total:    1040,
+				IssuedAt: time.Date(2025, time.March, 14, 18, 22, 10, 0, brt),
+			},
+		},
+		{
+			key: rsKey,
+			want: Page{
+				Merchant: "Padaria Pão Quente",
+				Total:    18.50,
+				IssuedAt: time.Date(2025, time.February, 2, 7, 5, 0, 0, brt),
+			},
+		},
+	}
+
+	fetcher := FileFetcher{Dir: "testdata"}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			page, err := fetcher.Fetch(context.Background(), &Note{AccessKey: tt.key})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ParsePage(page, brt)
+			if got.Merchant != tt.want.Merchant || got.Total != tt.want.Total || !got.IssuedAt.Equal(tt.want.IssuedAt) {
+				t.Errorf("ParsePage = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePageWithoutData(t *testing.T) {
+	got := ParsePage([]byte("<html><body>Serviço indisponível</body></html>"), brt)
+	if got != (Page{}) {
+		t.Errorf("ParsePage = %+v, want zero", got)
+	}
+}
+
+func TestHTTPFetcherStaysOnPortals(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPFetcher().Fetch(context.Background(), &Note{URL: server.URL + "/?p=" + spKey})
+	if !errors.Is(err, errOffPortal) {
+		t.Errorf("err = %v, want errOffPortal", err)
+	}
+	if requested {
+		t.Error("fetched a page outside the SEFAZ portals")
+	}
+}
+
+func TestCheckRedirect(t *testing.T) {
+	from, _ := http.NewRequest(http.MethodGet, "https://www.sefaz.rs.gov.br/NFCE/NFCE-COM.aspx", nil)
+	tests := []struct {
+		to   string
+		via  int
+		want bool
+	}{
+		{"https://dfe-portal.svrs.rs.gov.br/NFCE", 1, true},
+		{"https://nfce.example.com/NFCE", 1, false},
+		{"https://www.sefaz.rs.gov.br/NFCE/Consulta.aspx", 1, true},
+		{"http://www.sefaz.rs.gov.br/NFCE/Consulta.aspx", 1, false},
+		{"https://169.254.169.254/latest/meta-data", 1, false},
+		{"https://www.sefaz.rs.gov.br/NFCE/Consulta.aspx", maxRedirects, false},
+	}
+
+	for _, tt := range tests {
+		to, err := url.Parse(tt.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		via := make([]*http.Request, tt.via)
+		for i := range via {
+			via[i] = from
+		}
+		err = checkRedirect(&http.Request{URL: to}, via)
+		if (err == nil) != tt.want {
+			t.Errorf("redirect to %s after %d: err = %v, allowed want %v", tt.to, tt.via, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package nfce
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg" // receipt photos arrive as JPEG
+	_ "image/png"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// DecodeQR finds a QR code in a photo and returns its text.
+func DecodeQR(photo []byte) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(photo))
+	if err != nil {
+		return "", err
+	}
+
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", err
+	}
+
+	hints := map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_TRY_HARDER: true,
+	}
+	result, err := qrcode.NewQRCodeReader().Decode(bitmap, hints)
+	if err != nil {
+		return "", err
+	}
+	return result.GetText(), nil
+}
+
+// DecodePhoto decodes the QR code of a receipt photo and parses its NFC-e URL.
+func DecodePhoto(photo []byte) (*Note, error) {
+	text, err := DecodeQR(photo)
+	if err != nil {
+		return nil, ErrNotNFCe
+	}
+	return ParseURL(text)
+}
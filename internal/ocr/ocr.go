@@ -0,0 +1,89 @@
+// Package ocr extracts text from receipt photos. Engines are pluggable: the
+// bot uses the tesseract CLI when it is installed, and tests can use Fake.
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrUnavailable is returned when no OCR engine is installed or configured.
+var ErrUnavailable = errors.New("ocr: no engine available")
+
+// Engine turns an image (JPEG or PNG bytes) into plain text.
+type Engine interface {
+	Recognize(ctx context.Context, image []byte) (string, error)
+}
+
+// Tesseract runs the tesseract command line tool.
+type Tesseract struct {
+	Binary   string // path or name of the executable
+	Language string // tesseract language pack, "por" for Portuguese receipts
+}
+
+// NewTesseract returns an engine configured from TESSERACT_PATH and
+// TESSERACT_LANG, defaulting to "tesseract" in PATH and Portuguese.
+func NewTesseract() *Tesseract {
+	t := &Tesseract{Binary: "tesseract", Language: "por"}
+	if path := os.Getenv("TESSERACT_PATH"); path != "" {
+		t.Binary = path
+	}
+	if lang := os.Getenv("TESSERACT_LANG"); lang != "" {
+		t.Language = lang
+	}
+	return t
+}
+
+// Recognize pipes the image through "tesseract stdin stdout". It returns
+// ErrUnavailable when the binary cannot be found, so callers can fall back.
+func (t *Tesseract) Recognize(ctx context.Context, image []byte) (string, error) {
+	binary, err := exec.LookPath(t.Binary)
+	if err != nil {
+		return "", ErrUnavailable
+	}
+
+	cmd := exec.CommandContext(ctx, binary, "stdin", "stdout", "-l", t.Language, "--psm", "4")
+	cmd.Stdin = bytes.NewReader(image)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("tesseract failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// Disabled is an engine that is never available, for deployments without OCR.
+type Disabled struct{}
+
+// Recognize always returns ErrUnavailable.
+func (Disabled) Recognize(context.Context, []byte) (string, error) {
+	return "", ErrUnavailable
+}
+
+// Fake returns fixed text, for tests.
+type Fake struct {
+	Text string
+	Err  error
+}
+
+// Recognize returns the configured text and error.
+func (f Fake) Recognize(context.Context, []byte) (string, error) {
+	return f.Text, f.Err
+}
+
+// FromEnv picks the engine named by OCR_ENGINE: "tesseract" (default) or "off".
+func FromEnv() Engine {
+	switch strings.ToLower(os.Getenv("OCR_ENGINE")) {
+	case "off", "none", "disabled":
+		return Disabled{}
+	default:
+		return NewTesseract()
+	}
+}
@@ -0,0 +1,124 @@
+package ocr
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Receipt holds what could be read from a cupom fiscal. Zero fields were not found.
+type Receipt struct {
+	Total    float64
+	Merchant string
+	Date     time.Time
+}
+
+var (
+	// Brazilian money values: 1.234,56 or 12,34 (OCR often turns "," into ".").
+	moneyPattern = regexp.MustCompile(`(\d{1,3}(?:\.\d{3})*|\d+)[,.](\d{2})\b`)
+	datePattern  = regexp.MustCompile(`\b(\d{2})/(\d{2})/(\d{2,4})\b`)
+
+	// Total labels, most reliable first.
+	totalLabels = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)valor\s+a\s+pagar`),
+		regexp.MustCompile(`(?i)valor\s+total`),
+		regexp.MustCompile(`(?i)total\s+r\$`),
+		regexp.MustCompile(`(?i)\btotal\b`),
+	}
+
+	// Lines that are never the merchant name.
+	merchantSkip = regexp.MustCompile(`(?i)cnpj|cpf|\bie\b|inscri|cupom|nfc-?e|documento|auxiliar|extrato|danfe|^\s*(rua|av\.?|avenida|rod\.?)\b`)
+)
+
+// ParseReceipt extracts the total, merchant and date from OCR text. The
+// second result is false when no total could be found.
+func ParseReceipt(text string) (Receipt, bool) {
+	lines := strings.Split(text, "\n")
+
+	var receipt Receipt
+	receipt.Total = findTotal(lines)
+	receipt.Merchant = findMerchant(lines)
+	receipt.Date = findDate(text)
+
+	return receipt, receipt.Total > 0
+}
+
+func findTotal(lines []string) float64 {
+	for _, label := range totalLabels {
+		for i, line := range lines {
+			loc := label.FindStringIndex(line)
+			if loc == nil {
+				continue
+			}
+			// The value is usually on the same line, after the label,
+			// but some printers put it on the next one.
+			if value, ok := lastMoney(line[loc[1]:]); ok {
+				return value
+			}
+			if i+1 < len(lines) {
+				if value, ok := lastMoney(lines[i+1]); ok {
+					return value
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func lastMoney(text string) (float64, bool) {
+	matches := moneyPattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	match := matches[len(matches)-1]
+	whole := strings.ReplaceAll(match[1], ".", "")
+	value, err := strconv.ParseFloat(whole+"."+match[2], 64)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+	return value, true
+}
+
+// findMerchant returns the first line near the top that looks like a name.
+func findMerchant(lines []string) string {
+	for i, line := range lines {
+		if i >= 8 {
+			break
+		}
+		line = strings.TrimSpace(line)
+		if merchantSkip.MatchString(line) {
+			continue
+		}
+		letters := 0
+		for _, r := range line {
+			if unicode.IsLetter(r) {
+				letters++
+			}
+		}
+		if letters >= 4 && letters*2 >= len([]rune(line)) {
+			return strings.Join(strings.Fields(line), " ")
+		}
+	}
+	return ""
+}
+
+func findDate(text string) time.Time {
+	for _, match := range datePattern.FindAllStringSubmatch(text, -1) {
+		day, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		year, _ := strconv.Atoi(match[3])
+		if year < 100 {
+			year += 2000
+		}
+		if day < 1 || day > 31 || month < 1 || month > 12 || year < 2000 {
+			continue
+		}
+		date := time.Date(year, time.Month(month), day, 12, 0, 0, 0, time.UTC)
+		if date.Day() == day {
+			return date
+		}
+	}
+	return time.Time{}
+}
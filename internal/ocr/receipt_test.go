@@ -0,0 +1,90 @@
+package ocr
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseReceipt(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		total    float64
+		merchant string
+		date     string // 2006-01-02, "" when not found
+	}{
+		{
+			name:     "valor a pagar wins over subtotal",
+			text:     "PADARIA PAO QUENTE\nCNPJ 11.222.333/0001-44\nSUBTOTAL 30,00\nDESCONTO 5,00\nVALOR A PAGAR R$ 25,00\n01/02/2025",
+			total:    25,
+			merchant: "PADARIA PAO QUENTE",
+			date:     "2025-02-01",
+		},
+		{
+			name:     "total on the next line",
+			text:     "FARMACIA SAUDE\nTOTAL R$\n1.234,56\n",
+			total:    1234.56,
+			merchant: "FARMACIA SAUDE",
+		},
+		{
+			name:     "OCR reads the comma as a dot",
+			text:     "Restaurante Sabor Caseiro\nTOTAL 89.90\n05/06/24",
+			total:    89.90,
+			merchant: "Restaurante Sabor Caseiro",
+			date:     "2024-06-05",
+		},
+		{
+			name:     "address and invalid dates skipped",
+			text:     "RUA AUGUSTA 100\nCNPJ 1\nLANCHONETE DA ESQUINA\nVALOR TOTAL 10,00\n31/02/2025 15/03/2025",
+			total:    10,
+			merchant: "LANCHONETE DA ESQUINA",
+			date:     "2025-03-15",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipt, ok := ParseReceipt(tt.text)
+			if !ok {
+				t.Fatal("no total found")
+			}
+			if receipt.Total != tt.total {
+				t.Errorf("total = %v, want %v", receipt.Total, tt.total)
+			}
+			if receipt.Merchant != tt.merchant {
+				t.Errorf("merchant = %q, want %q", receipt.Merchant, tt.merchant)
+			}
+			date := ""
+			if !receipt.Date.IsZero() {
+				date = receipt.Date.Format("2006-01-02")
+			}
+			if date != tt.date {
+				t.Errorf("date = %q, want %q", date, tt.date)
+			}
+		})
+	}
+}
+
+func TestParseReceiptWithoutTotal(t *testing.T) {
+	if _, ok := ParseReceipt("MERCADINHO\nOBRIGADO, VOLTE SEMPRE\n"); ok {
+		t.Error("found a total in a receipt without one")
+	}
+}
+
+func TestEngines(t *testing.T) {
+	var engine Engine = Fake{Text: "TOTAL 12,00"}
+	text, err := engine.Recognize(context.Background(), nil)
+	if err != nil || text != "TOTAL 12,00" {
+		t.Errorf("Fake.Recognize = %q, %v", text, err)
+	}
+
+	if _, err := (Disabled{}).Recognize(context.Background(), nil); err != ErrUnavailable {
+		t.Errorf("Disabled.Recognize error = %v, want ErrUnavailable", err)
+	}
+
+	missing := &Tesseract{Binary: "tesseract-not-installed-" + time.Now().Format("150405"), Language: "por"}
+	if _, err := missing.Recognize(context.Background(), nil); err != ErrUnavailable {
+		t.Errorf("Tesseract without binary error = %v, want ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,227 @@
+// Package parser understands expenses written (or spoken) in plain
+// Portuguese, such as "gastei vinte reais de uber no pix".
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Expense is what could be understood from a sentence. Method is empty when
+// the sentence does not mention one.
+type Expense struct {
+	Amount      float64
+	Description string
+	Method      string
+}
+
+// knownMethods are payment methods recognised even when the user has not registered them.
+var knownMethods = map[string]string{
+	"pix":      "pix",
+	"dinheiro": "dinheiro",
+	"debito":   "debito",
+	"credito":  "credito",
+	"cartao":   "cartao",
+	"boleto":   "boleto",
+	"vr":       "vr",
+	"va":       "va",
+}
+
+var numberWords = map[string]int{
+	"zero": 0, "um": 1, "uma": 1, "dois": 2, "duas": 2, "tres": 3, "quatro": 4,
+	"cinco": 5, "seis": 6, "sete": 7, "oito": 8, "nove": 9, "dez": 10,
+	"onze": 11, "doze": 12, "treze": 13, "catorze": 14, "quatorze": 14,
+	"quinze": 15, "dezesseis": 16, "dezessete": 17, "dezoito": 18, "dezenove": 19,
+	"vinte": 20, "trinta": 30, "quarenta": 40, "cinquenta": 50, "sessenta": 60,
+	"setenta": 70, "oitenta": 80, "noventa": 90,
+	"cem": 100, "cento": 100, "duzentos": 200, "duzentas": 200, "trezentos": 300,
+	"trezentas": 300, "quatrocentos": 400, "quatrocentas": 400, "quinhentos": 500,
+	"quinhentas": 500, "seiscentos": 600, "seiscentas": 600, "setecentos": 700,
+	"setecentas": 700, "oitocentos": 800, "oitocentas": 800, "novecentos": 900,
+	"novecentas": 900,
+}
+
+// stopWords are dropped when looking for the description.
+var stopWords = map[string]bool{
+	"gastei": true, "paguei": true, "comprei": true, "gasto": true, "foi": true,
+	"foram": true, "de": true, "do": true, "da": true, "dos": true, "das": true,
+	"com": true, "no": true, "na": true, "nos": true, "nas": true, "em": true,
+	"e": true, "o": true, "a": true, "os": true, "as": true, "um": true, "uma": true,
+	"reais": true, "real": true, "r": true, "conto": true, "contos": true, "pila": true,
+	"centavos": true, "centavo": true, "pelo": true, "pela": true, "hoje": true,
+	"eu": true, "pra": true, "para": true, "por": true, "mil": true,
+}
+
+var (
+	numericAmount = regexp.MustCompile(`^(?:r\$)?(\d+(?:[.,]\d{1,2})?)$`)
+	accents       = strings.NewReplacer(
+		"á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i",
+		"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ç", "c",
+	)
+)
+
+// Parse reads an expense from free text. userMethods are the names of the
+// user's registered payment methods, matched in addition to the common ones.
+// The second result is false when no amount was found.
+func Parse(text string, userMethods []string) (Expense, bool) {
+	tokens := tokenize(text)
+	methods := make(map[string]string, len(knownMethods)+len(userMethods))
+	for word, method := range knownMethods {
+		methods[word] = method
+	}
+	for _, name := range userMethods {
+		methods[normalize(name)] = name
+	}
+
+	var result Expense
+	used := make([]bool, len(tokens))
+
+	result.Amount = findAmount(tokens, used)
+	if result.Amount <= 0 {
+		return Expense{}, false
+	}
+
+	for i, token := range tokens {
+		if used[i] {
+			continue
+		}
+		if method, ok := methods[token]; ok && result.Method == "" {
+			result.Method = method
+			used[i] = true
+		}
+	}
+
+	for i, token := range tokens {
+		if used[i] || stopWords[token] {
+			continue
+		}
+		if _, isNumber := numberWords[token]; isNumber {
+			continue
+		}
+		result.Description = token
+		break
+	}
+	if result.Description == "" {
+		result.Description = "outros"
+	}
+
+	return result, true
+}
+
+// findAmount returns the first amount in the tokens, written with digits
+// ("25", "25,90", "r$25") or words ("vinte e cinco reais e noventa centavos"),
+// and marks the tokens it used.
+func findAmount(tokens []string, used []bool) float64 {
+	for i, token := range tokens {
+		if match := numericAmount.FindStringSubmatch(token); match != nil {
+			value, err := strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64)
+			if err == nil {
+				used[i] = true
+				return value
+			}
+		}
+	}
+
+	for i := range tokens {
+		reais, next := readNumber(tokens, i)
+		if next == i {
+			continue
+		}
+		for j := i; j < next; j++ {
+			used[j] = true
+		}
+
+		// Optional "reais e <n> centavos".
+		if next < len(tokens) && isCurrencyWord(tokens[next]) {
+			used[next] = true
+			next++
+		}
+		if next+1 < len(tokens) && tokens[next] == "e" {
+			cents, end := readNumber(tokens, next+1)
+			if end > next+1 && end < len(tokens) && strings.HasPrefix(tokens[end], "centavo") && cents < 100 {
+				for j := next; j <= end; j++ {
+					used[j] = true
+				}
+				return float64(reais) + float64(cents)/100
+			}
+		}
+		return float64(reais)
+	}
+	return 0
+}
+
+// readNumber reads a number written in words starting at tokens[start], such
+// as "mil e duzentos e cinquenta". It returns the value and the index after it;
+// the index equals start when there is no number there.
+func readNumber(tokens []string, start int) (int, int) {
+	total, current := 0, 0
+	end := start
+	for i := start; i < len(tokens); i++ {
+		token := tokens[i]
+		if token == "mil" {
+			if current == 0 {
+				current = 1
+			}
+			total += current * 1000
+			current = 0
+			end = i + 1
+			continue
+		}
+		if value, ok := numberWords[token]; ok {
+			// "um"/"uma" alone is an article, not a number ("um café").
+			if (token == "um" || token == "uma") && i == start && !nextIsNumberOrCurrency(tokens, i) {
+				break
+			}
+			current += value
+			end = i + 1
+			continue
+		}
+		if token == "e" && i > start && i+1 < len(tokens) {
+			if _, ok := numberWords[tokens[i+1]]; ok {
+				continue
+			}
+		}
+		break
+	}
+	if end == start {
+		return 0, start
+	}
+	return total + current, end
+}
+
+func nextIsNumberOrCurrency(tokens []string, i int) bool {
+	if i+1 >= len(tokens) {
+		return false
+	}
+	next := tokens[i+1]
+	_, isNumber := numberWords[next]
+	return isNumber || next == "mil" || isCurrencyWord(next)
+}
+
+func isCurrencyWord(token string) bool {
+	switch token {
+	case "real", "reais", "conto", "contos", "pila":
+		return true
+	}
+	return false
+}
+
+func tokenize(text string) []string {
+	fields := strings.Fields(normalize(text))
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.Trim(field, ".!?;:\"'()")
+		// "r$ 25" is spoken/written apart; keep the number only.
+		field = strings.TrimSuffix(field, ",")
+		if field == "" || field == "r$" {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+func normalize(text string) string {
+	return accents.Replace(strings.ToLower(text))
+}
@@ -0,0 +1,169 @@
+// Package ratelimit throttles how often each user can run each command, with
+// a token bucket per user, chat and command.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultName is the Limits entry used for names without a limit of their own.
+const DefaultName = "default"
+
+// Limit allows Burst calls at once, refilled evenly over Per: "10/1m" is up
+// to 10 calls in a row, then one every 6 seconds.
+type Limit struct {
+	Burst int
+	Per   time.Duration
+}
+
+func (l Limit) rate() float64 {
+	return float64(l.Burst) / l.Per.Seconds()
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Burst, l.Per)
+}
+
+// Limits maps a command (without the slash) or update kind to its limit.
+type Limits map[string]Limit
+
+// DefaultLimits are used when RATE_LIMITS is not set. Commands that read the
+// whole expense history or call external tools get the tighter limits.
+var DefaultLimits = Limits{
+	DefaultName:   {Burst: 30, Per: time.Minute},
+	"gastei":      {Burst: 10, Per: time.Minute},
+	"consulta":    {Burst: 10, Per: time.Minute},
+	"fatura":      {Burst: 5, Per: time.Minute},
+	"grafico":     {Burst: 5, Per: time.Minute},
+	"deletartudo": {Burst: 3, Per: time.Minute},
+	"inline":      {Burst: 60, Per: time.Minute},
+	"photo":       {Burst: 5, Per: time.Minute},
+	"voice":       {Burst: 5, Per: time.Minute},
+}
+
+// For returns the limit of name, or the default one.
+func (l Limits) For(name string) Limit {
+	if limit, ok := l[name]; ok {
+		return limit
+	}
+	return l[DefaultName]
+}
+
+// ParseLimits reads a list such as "default=30/1m,gastei=10/1m,photo=5/30s".
+// Entries override DefaultLimits; the others are kept.
+func ParseLimits(spec string) (Limits, error) {
+	limits := make(Limits, len(DefaultLimits))
+	for name, limit := range DefaultLimits {
+		limits[name] = limit
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit %q: expected name=burst/period", entry)
+		}
+		burstText, periodText, ok := strings.Cut(value, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit %q: expected name=burst/period", entry)
+		}
+		burst, err := strconv.Atoi(strings.TrimSpace(burstText))
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("invalid rate limit %q: burst must be a positive integer", entry)
+		}
+		period, err := time.ParseDuration(strings.TrimSpace(periodText))
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("invalid rate limit %q: period must be a duration such as 1m", entry)
+		}
+		limits[strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "/")] = Limit{Burst: burst, Per: period}
+	}
+	return limits, nil
+}
+
+// LimitsFromEnv reads RATE_LIMITS with ParseLimits.
+func LimitsFromEnv() (Limits, error) {
+	return ParseLimits(os.Getenv("RATE_LIMITS"))
+}
+
+// Key identifies a bucket: one per user, chat and command.
+type Key struct {
+	UserID int64
+	ChatID int64
+	Name   string
+}
+
+// Bucket is the state of a token bucket. Notified records that the user was
+// already told to slow down, so spamming does not get a reply per message.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+	Notified  bool
+}
+
+// Result is the outcome of a call to Limiter.Allow.
+type Result struct {
+	Allowed bool
+	// RetryAfter is how long until the next call would be allowed.
+	RetryAfter time.Duration
+	// Notify is true on the first refusal since the last allowed call.
+	Notify bool
+}
+
+// Take spends a token from the bucket, refilled up to now. A zero bucket is full.
+func Take(bucket Bucket, limit Limit, now time.Time) (Bucket, Result) {
+	capacity := float64(limit.Burst)
+	tokens := capacity
+	if !bucket.UpdatedAt.IsZero() {
+		elapsed := now.Sub(bucket.UpdatedAt).Seconds()
+		tokens = math.Min(capacity, bucket.Tokens+math.Max(0, elapsed)*limit.rate())
+	}
+
+	if tokens >= 1 {
+		return Bucket{Tokens: tokens - 1, UpdatedAt: now}, Result{Allowed: true}
+	}
+
+	wait := time.Duration((1 - tokens) / limit.rate() * float64(time.Second))
+	return Bucket{Tokens: tokens, UpdatedAt: now, Notified: true},
+		Result{RetryAfter: wait, Notify: !bucket.Notified}
+}
+
+// Store keeps the buckets. Take must apply the package Take function to the
+// stored bucket and save the result atomically.
+type Store interface {
+	Take(ctx context.Context, key Key, limit Limit, now time.Time) (Result, error)
+}
+
+// Limiter applies Limits to the calls of each user.
+type Limiter struct {
+	limits Limits
+	store  Store
+	now    func() time.Time // the clock; tests replace it
+}
+
+func New(limits Limits, store Store) *Limiter {
+	return &Limiter{limits: limits, store: store, now: time.Now}
+}
+
+// Allow spends a token of key.Name's bucket. When the store fails the call is
+// allowed: the limiter protects the bot, it must not take it down.
+func (l *Limiter) Allow(ctx context.Context, key Key) Result {
+	result, err := l.store.Take(ctx, key, l.limits.For(key.Name), l.now())
+	if err != nil {
+		slog.WarnContext(ctx, "Rate limiter unavailable", "user_id", key.UserID, "name", key.Name, "error", err)
+		return Result{Allowed: true}
+	}
+	if !result.Allowed {
+		slog.WarnContext(ctx, "Rate limited", "user_id", key.UserID, "chat_id", key.ChatID, "name", key.Name, "retry_after", result.RetryAfter.Round(time.Second))
+	}
+	return result
+}
@@ -0,0 +1,218 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clock is a fake clock the tests move by hand.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+func newClock() *clock                   { return &clock{t: time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)} }
+
+func TestTake(t *testing.T) {
+	limit := Limit{Burst: 3, Per: 30 * time.Second} // one token every 10s
+
+	type step struct {
+		after      time.Duration
+		allowed    bool
+		retryAfter time.Duration
+		notify     bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"burst then refused", []step{
+			{0, true, 0, false},
+			{0, true, 0, false},
+			{0, true, 0, false},
+			{0, false, 10 * time.Second, true},
+			{4 * time.Second, false, 6 * time.Second, false},
+		}},
+		{"refill one token", []step{
+			{0, true, 0, false}, {0, true, 0, false}, {0, true, 0, false},
+			{10 * time.Second, true, 0, false},
+			{0, false, 10 * time.Second, true},
+		}},
+		{"refill caps at burst", []step{
+			{0, true, 0, false},
+			{time.Hour, true, 0, false}, {0, true, 0, false}, {0, true, 0, false},
+			{0, false, 10 * time.Second, true},
+		}},
+		{"notify again after an allowed call", []step{
+			{0, true, 0, false}, {0, true, 0, false}, {0, true, 0, false},
+			{0, false, 10 * time.Second, true},
+			{10 * time.Second, true, 0, false},
+			{0, false, 10 * time.Second, true},
+		}},
+		{"clock going back", []step{
+			{0, true, 0, false}, {0, true, 0, false}, {0, true, 0, false},
+			{-time.Minute, false, 10 * time.Second, true},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClock()
+			var bucket Bucket
+			for i, s := range tt.steps {
+				c.advance(s.after)
+				var result Result
+				bucket, result = Take(bucket, limit, c.now())
+				// Refills are computed in float seconds.
+				retryAfter := result.RetryAfter.Round(time.Millisecond)
+				if result.Allowed != s.allowed || retryAfter != s.retryAfter || result.Notify != s.notify {
+					t.Fatalf("step %d: got %+v, want allowed=%v retry=%v notify=%v", i, result, s.allowed, s.retryAfter, s.notify)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		spec string
+		want map[string]Limit
+	}{
+		{"", map[string]Limit{DefaultName: DefaultLimits[DefaultName], "gastei": DefaultLimits["gastei"]}},
+		{"gastei=2/10s", map[string]Limit{"gastei": {Burst: 2, Per: 10 * time.Second}, "fatura": DefaultLimits["fatura"]}},
+		{" /Consulta = 4/1h , default=100/1m ,", map[string]Limit{"consulta": {Burst: 4, Per: time.Hour}, DefaultName: {Burst: 100, Per: time.Minute}}},
+		{"novo=1/500ms", map[string]Limit{"novo": {Burst: 1, Per: 500 * time.Millisecond}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			limits, err := ParseLimits(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.want {
+				if got := limits[name]; got != want {
+					t.Errorf("%s = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLimitsErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"gastei", "expected name=burst/period"},
+		{"gastei=10", "expected name=burst/period"},
+		{"gastei=dez/1m", "burst must be a positive integer"},
+		{"gastei=0/1m", "burst must be a positive integer"},
+		{"gastei=-1/1m", "burst must be a positive integer"},
+		{"gastei=10/minuto", "period must be a duration"},
+		{"gastei=10/0s", "period must be a duration"},
+		{"default=30/1m,gastei=10", "expected name=burst/period"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := ParseLimits(tt.spec)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseLimits(%q) = %v, want an error about %q", tt.spec, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitsFor(t *testing.T) {
+	limits := Limits{DefaultName: {Burst: 1, Per: time.Second}, "gastei": {Burst: 2, Per: time.Second}}
+	if got := limits.For("gastei"); got.Burst != 2 {
+		t.Errorf("For(gastei) = %v", got)
+	}
+	if got := limits.For("outro"); got.Burst != 1 {
+		t.Errorf("For(outro) = %v, want the default", got)
+	}
+}
+
+func TestMemoryStoreSeparatesKeys(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Burst: 1, Per: time.Minute}
+	now := newClock().now()
+
+	keys := []Key{
+		{UserID: 1, ChatID: 1, Name: "gastei"},
+		{UserID: 1, ChatID: 2, Name: "gastei"},
+		{UserID: 1, ChatID: 1, Name: "consulta"},
+		{UserID: 2, ChatID: 1, Name: "gastei"},
+	}
+	for _, key := range keys {
+		if result, _ := store.Take(context.Background(), key, limit, now); !result.Allowed {
+			t.Errorf("first call of %+v refused", key)
+		}
+	}
+	if result, _ := store.Take(context.Background(), keys[0], limit, now); result.Allowed {
+		t.Error("second call of the same key allowed")
+	}
+}
+
+func TestMemoryStoreDropsFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Burst: 1, Per: time.Minute}
+	c := newClock()
+
+	for i := 0; i < maxMemoryBuckets; i++ {
+		store.Take(context.Background(), Key{UserID: int64(i)}, limit, c.now())
+	}
+	if len(store.buckets) != maxMemoryBuckets {
+		t.Fatalf("holds %d buckets, want %d", len(store.buckets), maxMemoryBuckets)
+	}
+
+	// Still refilling: nothing is dropped.
+	c.advance(30 * time.Second)
+	store.Take(context.Background(), Key{UserID: -1}, limit, c.now())
+	if len(store.buckets) != maxMemoryBuckets+1 {
+		t.Fatalf("dropped buckets still refilling: holds %d", len(store.buckets))
+	}
+
+	// Full again: the old buckets go, the new ones stay.
+	c.advance(31 * time.Second)
+	store.Take(context.Background(), Key{UserID: -2}, limit, c.now())
+	if len(store.buckets) != 2 {
+		t.Errorf("holds %d buckets, want the 2 still refilling", len(store.buckets))
+	}
+	if result, _ := store.Take(context.Background(), Key{UserID: 0}, limit, c.now()); !result.Allowed {
+		t.Error("a dropped bucket did not come back full")
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, Key, Limit, time.Time) (Result, error) {
+	return Result{}, errors.New("dynamodb unavailable")
+}
+
+func TestLimiterAllow(t *testing.T) {
+	c := newClock()
+	limiter := New(Limits{DefaultName: {Burst: 1, Per: time.Minute}}, NewMemoryStore())
+	limiter.now = c.now
+	key := Key{UserID: 1, ChatID: 1, Name: "gastei"}
+
+	if !limiter.Allow(context.Background(), key).Allowed {
+		t.Fatal("first call refused")
+	}
+	if result := limiter.Allow(context.Background(), key); result.Allowed || result.RetryAfter != time.Minute {
+		t.Fatalf("second call = %+v, want refused for a minute", result)
+	}
+	c.advance(time.Minute)
+	if !limiter.Allow(context.Background(), key).Allowed {
+		t.Error("call refused after the bucket refilled")
+	}
+
+	// A failing store lets calls through.
+	open := New(DefaultLimits, failingStore{})
+	if !open.Allow(context.Background(), key).Allowed {
+		t.Error("call refused while the store fails")
+	}
+}
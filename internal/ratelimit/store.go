@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"money-telegram-bot/internal/database"
+)
+
+// maxMemoryBuckets is how many buckets MemoryStore holds before it drops the
+// ones that are full again (and so equal to a missing bucket).
+const maxMemoryBuckets = 10000
+
+type memoryEntry struct {
+	bucket Bucket
+	full   time.Time
+}
+
+// MemoryStore keeps the buckets in memory, for polling mode.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[Key]memoryEntry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[Key]memoryEntry)}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key Key, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket, result := Take(s.buckets[key].bucket, limit, now)
+	s.buckets[key] = memoryEntry{bucket: bucket, full: now.Add(limit.Per)}
+
+	if len(s.buckets) > maxMemoryBuckets {
+		for k, entry := range s.buckets {
+			if now.After(entry.full) {
+				delete(s.buckets, k)
+			}
+		}
+	}
+	return result, nil
+}
+
+// dynamoAttempts is how many times DynamoStore retries when another request
+// changed the bucket between the read and the write.
+const dynamoAttempts = 3
+
+// DynamoStore keeps the buckets in DynamoDB, shared by every Lambda instance.
+type DynamoStore struct{}
+
+func (DynamoStore) Take(ctx context.Context, key Key, limit Limit, now time.Time) (Result, error) {
+	name := fmt.Sprintf("%d#%s", key.ChatID, key.Name)
+	for attempt := 0; attempt < dynamoAttempts; attempt++ {
+		stored, err := database.GetRateBucket(ctx, key.UserID, name)
+		if err != nil {
+			return Result{}, err
+		}
+
+		var bucket Bucket
+		var previous int64
+		if stored != nil {
+			previous = stored.UpdatedAt
+			bucket = Bucket{Tokens: stored.Tokens, UpdatedAt: time.UnixMilli(stored.UpdatedAt), Notified: stored.Notified}
+		}
+
+		bucket, result := Take(bucket, limit, now)
+		err = database.SaveRateBucket(ctx, &database.RateBucket{
+			UserID:    key.UserID,
+			Tokens:    bucket.Tokens,
+			UpdatedAt: bucket.UpdatedAt.UnixMilli(),
+			Notified:  bucket.Notified,
+			ExpiresAt: now.Add(limit.Per).Add(time.Hour).Unix(),
+		}, name, previous)
+		if errors.Is(err, database.ErrRateBucketChanged) {
+			continue
+		}
+		if err != nil {
+			return Result{}, err
+		}
+		return result, nil
+	}
+	return Result{}, database.ErrRateBucketChanged
+}
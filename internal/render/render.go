@@ -0,0 +1,45 @@
+// Package render builds the formatted messages the bot sends. Messages use
+// Telegram's HTML parse mode, where only <, > and & need escaping, so any
+// category, method or command typed by a user can be shown safely.
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ParseMode is the parse mode of every formatted message.
+const ParseMode = tgbotapi.ModeHTML
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Escape makes s safe to place in an HTML message, as text or inside a tag.
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// Bold returns s escaped and in bold.
+func Bold(s string) string {
+	return "<b>" + Escape(s) + "</b>"
+}
+
+// Sprintf formats like fmt.Sprintf, escaping every string, error and
+// fmt.Stringer argument. The format itself is trusted HTML.
+func Sprintf(format string, args ...any) string {
+	escaped := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			escaped[i] = Escape(v)
+		case error:
+			escaped[i] = Escape(v.Error())
+		case fmt.Stringer:
+			escaped[i] = Escape(v.String())
+		default:
+			escaped[i] = arg
+		}
+	}
+	return fmt.Sprintf(format, escaped...)
+}
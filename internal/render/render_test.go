@@ -0,0 +1,47 @@
+package render
+
+import (
+	"errors"
+	"testing"
+)
+
+type label string
+
+func (l label) String() string { return string(l) }
+
+func TestSprintf(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		args   []any
+		want   string
+	}{
+		{"plain text", "<b>%s</b>", []any{"uber"}, "<b>uber</b>"},
+		{"tags in a category", "<b>%s</b>", []any{"<i>bar</i>"}, "<b>&lt;i&gt;bar&lt;/i&gt;</b>"},
+		{"ampersand", "%s", []any{"C&A"}, "C&amp;A"},
+		{"already an entity", "%s", []any{"&lt;"}, "&amp;lt;"},
+		{"unclosed tag", "Categoria: %s", []any{"<b"}, "Categoria: &lt;b"},
+		{"markdown is left alone", "%s", []any{"*_pix_* `[x](y)`"}, "*_pix_* `[x](y)`"},
+		{"quotes and accents", "%s", []any{`"pão" d'água 🍞`}, `"pão" d'água 🍞`},
+		{"command typed by the user", "Use <code>%s</code>", []any{"/gastei 10 <script>"}, "Use <code>/gastei 10 &lt;script&gt;</code>"},
+		{"error", "Erro: %v", []any{errors.New("a < b & c")}, "Erro: a &lt; b &amp; c"},
+		{"stringer", "%s", []any{label("R&D")}, "R&amp;D"},
+		{"numbers untouched", "%d itens, R$ %.2f", []any{3, 10.5}, "3 itens, R$ 10.50"},
+		{"quoted verb", "%q", []any{"<x>"}, `"&lt;x&gt;"`},
+		{"format is trusted", "<b>%s</b> & <i>%s</i>", []any{"a", "b"}, "<b>a</b> & <i>b</i>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sprintf(tt.format, tt.args...); got != tt.want {
+				t.Errorf("Sprintf(%q, %v) = %q, want %q", tt.format, tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBold(t *testing.T) {
+	if got, want := Bold("Tom & Jerry <3"), "<b>Tom &amp; Jerry &lt;3</b>"; got != want {
+		t.Errorf("Bold = %q, want %q", got, want)
+	}
+}
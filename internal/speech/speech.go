@@ -0,0 +1,118 @@
+// Package speech transcribes voice messages. Engines are pluggable: the bot
+// uses a local whisper.cpp CLI when it is installed, and tests can use Fake.
+package speech
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnavailable is returned when no speech-to-text engine is installed or configured.
+var ErrUnavailable = errors.New("speech: no engine available")
+
+// SpeechToText turns recorded audio into text.
+type SpeechToText interface {
+	Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error)
+}
+
+// WhisperCLI runs whisper.cpp locally. Telegram voice notes are OGG/Opus, so
+// the audio is first converted to 16 kHz mono WAV with ffmpeg.
+type WhisperCLI struct {
+	Binary   string // whisper.cpp executable ("whisper-cli", formerly "main")
+	Model    string // path to a ggml model, e.g. ggml-base.bin
+	FFmpeg   string // ffmpeg executable
+	Language string // spoken language, "pt" by default
+}
+
+// NewWhisperCLI returns an engine configured from WHISPER_PATH, WHISPER_MODEL,
+// WHISPER_LANG and FFMPEG_PATH.
+func NewWhisperCLI() *WhisperCLI {
+	w := &WhisperCLI{
+		Binary:   "whisper-cli",
+		Model:    os.Getenv("WHISPER_MODEL"),
+		FFmpeg:   "ffmpeg",
+		Language: "pt",
+	}
+	if path := os.Getenv("WHISPER_PATH"); path != "" {
+		w.Binary = path
+	}
+	if path := os.Getenv("FFMPEG_PATH"); path != "" {
+		w.FFmpeg = path
+	}
+	if lang := os.Getenv("WHISPER_LANG"); lang != "" {
+		w.Language = lang
+	}
+	return w
+}
+
+// Transcribe converts and transcribes the audio. It returns ErrUnavailable
+// when whisper, its model or ffmpeg are missing, so callers can fall back.
+func (w *WhisperCLI) Transcribe(ctx context.Context, audio []byte, _ string) (string, error) {
+	whisper, errWhisper := exec.LookPath(w.Binary)
+	ffmpeg, errFFmpeg := exec.LookPath(w.FFmpeg)
+	if errWhisper != nil || errFFmpeg != nil || w.Model == "" {
+		return "", ErrUnavailable
+	}
+	if _, err := os.Stat(w.Model); err != nil {
+		return "", ErrUnavailable
+	}
+
+	dir, err := os.MkdirTemp("", "voice-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	wav := filepath.Join(dir, "voice.wav")
+
+	convert := exec.CommandContext(ctx, ffmpeg, "-hide_banner", "-loglevel", "error",
+		"-i", "pipe:0", "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", wav)
+	convert.Stdin = bytes.NewReader(audio)
+	if output, err := convert.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	transcribe := exec.CommandContext(ctx, whisper, "-m", w.Model, "-l", w.Language, "-nt", "-np", "-f", wav)
+	var stdout, stderr bytes.Buffer
+	transcribe.Stdout = &stdout
+	transcribe.Stderr = &stderr
+	if err := transcribe.Run(); err != nil {
+		return "", fmt.Errorf("whisper failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.Join(strings.Fields(stdout.String()), " "), nil
+}
+
+// Disabled is an engine that is never available, for deployments without transcription.
+type Disabled struct{}
+
+// Transcribe always returns ErrUnavailable.
+func (Disabled) Transcribe(context.Context, []byte, string) (string, error) {
+	return "", ErrUnavailable
+}
+
+// Fake returns a fixed transcript, for tests.
+type Fake struct {
+	Text string
+	Err  error
+}
+
+// Transcribe returns the configured transcript and error.
+func (f Fake) Transcribe(context.Context, []byte, string) (string, error) {
+	return f.Text, f.Err
+}
+
+// FromEnv picks the engine named by STT_ENGINE: "whisper" (default) or "off".
+func FromEnv() SpeechToText {
+	switch strings.ToLower(os.Getenv("STT_ENGINE")) {
+	case "off", "none", "disabled":
+		return Disabled{}
+	default:
+		return NewWhisperCLI()
+	}
+}